@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vaynedu/hollow/internal/idl"
+)
+
+// generateClient 生成类型化的 HTTP 客户端，每次重新生成
+func (g *ProtoGenerator) generateClient(service *idl.Service) error {
+	methods := make([]clientMethod, len(service.Methods))
+	for i, m := range service.Methods {
+		methods[i] = clientMethod{Method: m}
+	}
+	data := map[string]interface{}{
+		"Package":         "client",
+		"ModuleName":      g.ModuleName,
+		"FrameworkImport": g.FrameworkImport,
+		"ServiceName":     service.Name,
+		"Comment":         service.Comment,
+		"Methods":         methods,
+	}
+
+	src, err := renderGoFile(clientTemplate, data)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(filepath.Join(g.OutputDir, "..", "client", strings.ToLower(service.Name)+"_client.go"), src)
+}
+
+// clientMethod rpc 方法在客户端中的签名
+type clientMethod struct {
+	idl.Method
+}
+
+// Doc 方法的注释，proto 中没有注释时使用方法名
+func (m clientMethod) Doc() string {
+	if m.Comment == "" {
+		return m.Name + " " + m.Name
+	}
+	return m.Name + " " + strings.ReplaceAll(m.Comment, "\n", "\n// ")
+}
+
+// Rule 主绑定对应的 hclient.Rule 字面量，客户端只使用主绑定，不使用 additional_bindings
+func (m clientMethod) Rule() string {
+	rule := m.HTTPRules[0]
+	fields := []string{"Method: " + strconv.Quote(rule.Method), "Path: " + strconv.Quote(rule.Path)}
+	if rule.Body != "" {
+		fields = append(fields, "Body: "+strconv.Quote(rule.Body))
+	}
+	if rule.ResponseBody != "" {
+		fields = append(fields, "ResponseBody: "+strconv.Quote(rule.ResponseBody))
+	}
+	return "hclient.Rule{" + strings.Join(fields, ", ") + "}"
+}
+
+// Route 方法注释中说明的 HTTP 方法和路径
+func (m clientMethod) Route() string {
+	rule := m.HTTPRules[0]
+	route := rule.Method + " " + rule.Path
+	switch {
+	case m.ClientStreaming:
+		return route + "，通过 WebSocket 传输"
+	case m.ServerStreaming:
+		return route + "，通过 SSE 传输"
+	case rule.Method == "GET" || rule.Method == "HEAD" || rule.Method == "OPTIONS" || rule.Method == "PUT" || rule.Method == "DELETE":
+		return route + "，失败时自动重试"
+	default:
+		return route
+	}
+}
+
+// Client 模板
+var clientTemplate = `// Code generated by hollow-cli proto. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+
+	"{{.FrameworkImport}}/pkg/hclient"
+	"{{.ModuleName}}/proto"
+)
+
+// {{.ServiceName}}Client {{if .Comment}}{{comment .Comment}}{{else}}{{.ServiceName}} 服务的 HTTP 客户端{{end}}
+//
+// 每个 rpc 对应一个方法，按 google.api.http 注解发送请求，服务端返回的错误码还原为 hecode 错误
+type {{.ServiceName}}Client struct {
+	cc *hclient.Client
+}
+
+// New{{.ServiceName}}Client 创建客户端，cc 由 hclient.New 创建，可以在多个服务的客户端之间共享
+func New{{.ServiceName}}Client(cc *hclient.Client) *{{.ServiceName}}Client {
+	return &{{.ServiceName}}Client{cc: cc}
+}
+{{range .Methods}}
+// {{.Doc}}
+//
+// {{.Route}}
+{{- if .ClientStreaming}}
+func (c *{{$.ServiceName}}Client) {{.Name}}(ctx context.Context, opts ...hclient.CallOption) (*hclient.BidiStream[*proto.{{.RequestType}}, *proto.{{.ResponseType}}], error) {
+	return hclient.Dial[*proto.{{.RequestType}}, *proto.{{.ResponseType}}](ctx, c.cc, {{.Rule}}, opts...)
+}
+{{else if .ServerStreaming}}
+func (c *{{$.ServiceName}}Client) {{.Name}}(ctx context.Context, req *proto.{{.RequestType}}, opts ...hclient.CallOption) (*hclient.ServerStream[*proto.{{.ResponseType}}], error) {
+	return hclient.Stream[*proto.{{.ResponseType}}](ctx, c.cc, {{.Rule}}, req, opts...)
+}
+{{else}}
+func (c *{{$.ServiceName}}Client) {{.Name}}(ctx context.Context, req *proto.{{.RequestType}}, opts ...hclient.CallOption) (*proto.{{.ResponseType}}, error) {
+	resp := new(proto.{{.ResponseType}})
+	if err := c.cc.Invoke(ctx, {{.Rule}}, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+{{end}}
+{{- end}}
+`
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateProtoClient(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/library\n")
+	protoPath := filepath.Join(dir, "proto", "library.proto")
+	writeFile(t, protoPath, libraryProto+`
+// 书籍推送
+service Feed {
+  // 订阅书籍
+  // 新书上架时推送
+  rpc Watch (GetBookRequest) returns (stream Book) {
+    option (google.api.http) = { get: "/v1/feed/{name}" };
+  }
+  rpc Import (stream Book) returns (ListBooksResponse);
+}
+`)
+
+	// 默认不生成客户端
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard}))
+	assert.NoFileExists(t, filepath.Join(dir, "client", "library_client.go"))
+
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Client: true, Out: io.Discard}))
+	client := readFile(t, filepath.Join(dir, "client", "library_client.go"))
+	assert.Contains(t, client, "// Code generated by hollow-cli proto. DO NOT EDIT.")
+	assert.Contains(t, client, `"github.com/vaynedu/hollow/pkg/hclient"`)
+	assert.Contains(t, client, `"example.com/library/proto"`)
+	assert.Contains(t, client, "func NewLibraryClient(cc *hclient.Client) *LibraryClient {")
+	// 只使用主绑定，幂等方法在注释中说明自动重试
+	assert.Contains(t, client, "// GET /v1/{name=shelves/*/books/*}，失败时自动重试\n")
+	assert.Contains(t, client, "func (c *LibraryClient) GetBook(ctx context.Context, req *proto.GetBookRequest, opts ...hclient.CallOption) (*proto.Book, error) {")
+	assert.Contains(t, client, `c.cc.Invoke(ctx, hclient.Rule{Method: "GET", Path: "/v1/{name=shelves/*/books/*}"}, req, resp, opts...)`)
+	assert.Contains(t, client, `hclient.Rule{Method: "PATCH", Path: "/v1/books/{name=**}", Body: "*"}`)
+	assert.Contains(t, client, `hclient.Rule{Method: "GET", Path: "/v1/books", ResponseBody: "books"}`)
+	assert.NotContains(t, client, "additional")
+
+	feed := readFile(t, filepath.Join(dir, "client", "feed_client.go"))
+	assert.Contains(t, feed, "// FeedClient 书籍推送\n")
+	assert.Contains(t, feed, "// Watch 订阅书籍\n// 新书上架时推送\n//\n// GET /v1/feed/{name}，通过 SSE 传输\n")
+	assert.Contains(t, feed, "func (c *FeedClient) Watch(ctx context.Context, req *proto.GetBookRequest, opts ...hclient.CallOption) (*hclient.ServerStream[*proto.Book], error) {")
+	assert.Contains(t, feed, `return hclient.Stream[*proto.Book](ctx, c.cc, hclient.Rule{Method: "GET", Path: "/v1/feed/{name}"}, req, opts...)`)
+	assert.Contains(t, feed, "func (c *FeedClient) Import(ctx context.Context, opts ...hclient.CallOption) (*hclient.BidiStream[*proto.Book, *proto.ListBooksResponse], error) {")
+	assert.Contains(t, feed, `return hclient.Dial[*proto.Book, *proto.ListBooksResponse](ctx, c.cc, hclient.Rule{Method: "GET", Path: "/import"}, opts...)`)
+}
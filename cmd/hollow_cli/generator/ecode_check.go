@@ -0,0 +1,487 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vaynedu/hollow/pkg/hecode"
+)
+
+// hecodeImportPath hecode 包的导入路径
+const hecodeImportPath = "github.com/vaynedu/hollow/pkg/hecode"
+
+// builtinNamespaces hecode 内置命名空间变量名到命名空间的映射
+var builtinNamespaces = map[string]*hecode.Namespace{
+	"NamespaceSystem": hecode.NamespaceSystem,
+	"NamespaceParam":  hecode.NamespaceParam,
+	"NamespaceBiz":    hecode.NamespaceBiz,
+	"NamespaceData":   hecode.NamespaceData,
+	"NamespaceDB":     hecode.NamespaceDB,
+	"NamespaceRedis":  hecode.NamespaceRedis,
+}
+
+// EcodeUsage 源码中一处创建错误码的调用
+type EcodeUsage struct {
+	Code      int
+	Message   string
+	Namespace string // 通过 Namespace.New 创建时的命名空间名称
+	Pos       token.Position
+}
+
+// EcodeIssue 检查发现的问题
+type EcodeIssue struct {
+	Pos     token.Position
+	Message string
+}
+
+// String 返回 "文件:行号: 问题" 格式的字符串
+func (i EcodeIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Pos, i.Message)
+}
+
+// EcodeReport 错误码检查结果
+type EcodeReport struct {
+	Usages     []EcodeUsage
+	Namespaces []*hecode.Namespace // 源码中注册的命名空间
+	Issues     []EcodeIssue
+}
+
+// HasIssues 是否发现问题
+func (r *EcodeReport) HasIssues() bool {
+	return len(r.Issues) > 0
+}
+
+// Print 输出检查结果
+func (r *EcodeReport) Print(w io.Writer) {
+	for _, issue := range r.Issues {
+		fmt.Fprintln(w, issue)
+	}
+	fmt.Fprintf(w, "checked %d error codes in %d namespaces, %d issues found\n",
+		len(r.Usages), len(r.Namespaces)+len(builtinNamespaces), len(r.Issues))
+}
+
+// CheckEcode 扫描目录下的 Go 源码，检查 hecode 错误码是否重复、是否超出命名空间号段，
+// 直接使用 hecode.New 创建的错误码也必须落在某个注册的命名空间内；
+// 测试文件、vendor、testdata 以及隐藏目录不参与检查
+func CheckEcode(dir string) (*EcodeReport, error) {
+	files, err := parseGoFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &ecodeChecker{
+		report:     &EcodeReport{},
+		namespaces: make(map[string]*hecode.Namespace),
+		packages:   make(map[string]bool),
+	}
+	// 第一遍收集常量和命名空间，第二遍收集错误码
+	for _, f := range files {
+		c.packages[f.pkg] = true
+		c.collectConsts(f)
+	}
+	for _, f := range files {
+		c.collectNamespaces(f)
+	}
+	for _, f := range files {
+		c.collectUsages(f)
+	}
+	c.validate()
+	return c.report, nil
+}
+
+// parsedFile 解析后的源文件
+type parsedFile struct {
+	fset    *token.FileSet
+	file    *ast.File
+	pkg     string            // 所在包的导入路径，找不到 go.mod 时为所在目录，用于区分不同包的同名标识符
+	alias   string            // hecode 包在文件中的引用名，为空表示文件没有引用 hecode
+	local   bool              // 文件是否就是 hecode 包本身
+	imports map[string]string // 其他包在文件中的引用名 -> 导入路径
+}
+
+// parseGoFiles 解析目录下的所有源文件，没有引用 hecode 的文件也可能通过其他包的命名空间变量创建错误码
+func parseGoFiles(dir string) ([]*parsedFile, error) {
+	fset := token.NewFileSet()
+	modules := make(map[string]string)
+	var files []*parsedFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return fmt.Errorf("解析 %s 失败: %w", path, err)
+		}
+		pf := &parsedFile{fset: fset, file: file, pkg: packagePath(filepath.Dir(path), modules), imports: make(map[string]string)}
+		for _, imp := range file.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			name := importName(imp)
+			if p == hecodeImportPath {
+				pf.alias = name
+				continue
+			}
+			if name != "_" && name != "." {
+				pf.imports[name] = p
+			}
+		}
+		pf.local = file.Name.Name == "hecode" && pf.alias == ""
+		files = append(files, pf)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("目录 %s 不存在", dir)
+	}
+	return files, err
+}
+
+// packagePath 根据所在模块的 go.mod 计算目录对应的导入路径，找不到 go.mod 时返回目录本身，
+// modules 缓存目录对应的模块根目录和模块路径
+func packagePath(dir string, modules map[string]string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		module, ok := modules[d]
+		if !ok {
+			module = modulePath(filepath.Join(d, "go.mod"))
+			modules[d] = module
+		}
+		if module != "" {
+			rel, err := filepath.Rel(d, dir)
+			if err != nil {
+				return dir
+			}
+			if rel == "." {
+				return module
+			}
+			return module + "/" + filepath.ToSlash(rel)
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// modulePath 读取 go.mod 中的模块路径，文件不存在时返回空字符串
+func modulePath(gomod string) string {
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), `"`)
+		}
+	}
+	return ""
+}
+
+type ecodeChecker struct {
+	report     *EcodeReport
+	consts     map[string]ast.Expr          // 包导入路径.常量名 -> 常量表达式
+	namespaces map[string]*hecode.Namespace // 包导入路径.变量名 -> 源码中注册的命名空间
+	packages   map[string]bool              // 检查的所有包
+}
+
+// collectConsts 收集包级整数常量，用于解析 hecode.New(CodeXxx, ...) 形式的调用
+func (c *ecodeChecker) collectConsts(f *parsedFile) {
+	if c.consts == nil {
+		c.consts = make(map[string]ast.Expr)
+	}
+	for _, decl := range f.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if i < len(vs.Values) {
+					c.consts[f.pkg+"."+name.Name] = vs.Values[i]
+				}
+			}
+		}
+	}
+}
+
+// collectNamespaces 收集 RegisterNamespace/MustRegisterNamespace 注册的命名空间
+func (c *ecodeChecker) collectNamespaces(f *parsedFile) {
+	// hecode 包本身注册的是内置命名空间，已经在 builtinNamespaces 中
+	if f.local {
+		return
+	}
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		var names []*ast.Ident
+		var values []ast.Expr
+		switch s := n.(type) {
+		case *ast.ValueSpec:
+			names, values = s.Names, s.Values
+		case *ast.AssignStmt:
+			for _, lhs := range s.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					names = append(names, ident)
+				}
+			}
+			values = s.Rhs
+		default:
+			return true
+		}
+
+		for i, value := range values {
+			call, ok := value.(*ast.CallExpr)
+			if !ok || i >= len(names) {
+				continue
+			}
+			fn := c.hecodeFunc(f, call.Fun)
+			if (fn != "RegisterNamespace" && fn != "MustRegisterNamespace") || len(call.Args) != 3 {
+				continue
+			}
+			pos := f.fset.Position(call.Pos())
+			name, ok := stringLit(call.Args[0])
+			min, okMin := c.eval(f.pkg, call.Args[1])
+			max, okMax := c.eval(f.pkg, call.Args[2])
+			if !ok || !okMin || !okMax {
+				c.issue(pos, "无法解析命名空间的名称或号段，请使用字面量")
+				continue
+			}
+
+			ns := &hecode.Namespace{Name: name, Min: min, Max: max}
+			if min < 1000 || min > max {
+				c.issue(pos, fmt.Sprintf("命名空间 %s 的号段无效，错误码不能小于 1000", ns))
+			}
+			for _, other := range c.allNamespaces() {
+				if other.Name == name {
+					c.issue(pos, fmt.Sprintf("命名空间 %q 重复注册", name))
+				} else if min <= other.Max && other.Min <= max {
+					c.issue(pos, fmt.Sprintf("命名空间 %s 与 %s 的号段重叠", ns, other))
+				}
+			}
+			c.namespaces[f.pkg+"."+names[i].Name] = ns
+			c.report.Namespaces = append(c.report.Namespaces, ns)
+		}
+		return true
+	})
+}
+
+// collectUsages 收集 New/Newf/NewErrorWithCause 以及 Namespace.New/Newf 调用中的错误码
+func (c *ecodeChecker) collectUsages(f *parsedFile) {
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) < 2 {
+			return true
+		}
+
+		var ns *hecode.Namespace
+		switch fn := c.hecodeFunc(f, call.Fun); fn {
+		case "New", "Newf", "NewErrorWithCause":
+		default:
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "New" && sel.Sel.Name != "Newf") {
+				return true
+			}
+			var unresolved string
+			if ns, unresolved = c.namespaceOf(f, sel.X); ns == nil {
+				// 其他包中的变量，包不在检查的目录中时无法确认是不是命名空间，参数像错误码时报告
+				if _, isMsg := stringLit(call.Args[1]); unresolved != "" && isMsg {
+					if _, isCode := c.eval(f.pkg, call.Args[0]); isCode {
+						c.issue(f.fset.Position(call.Pos()), fmt.Sprintf("无法解析 %s 的命名空间，所在的包 %s 不在检查的目录中", types.ExprString(sel.X), unresolved))
+					}
+				}
+				return true
+			}
+		}
+
+		pos := f.fset.Position(call.Pos())
+		code, ok := c.eval(f.pkg, call.Args[0])
+		if !ok {
+			c.issue(pos, "无法解析错误码，请使用整数字面量或常量")
+			return true
+		}
+		msg, _ := stringLit(call.Args[1])
+		usage := EcodeUsage{Code: code, Message: msg, Pos: pos}
+		if ns != nil {
+			usage.Namespace = ns.Name
+		}
+		c.report.Usages = append(c.report.Usages, usage)
+
+		switch {
+		case code < 1000:
+			c.issue(pos, fmt.Sprintf("错误码 %d 小于 1000", code))
+		case ns != nil && !ns.Contains(code):
+			c.issue(pos, fmt.Sprintf("错误码 %d 超出命名空间 %s 的号段", code, ns))
+		case ns == nil && f.local:
+			if builtinNamespace(code) == nil {
+				c.issue(pos, fmt.Sprintf("错误码 %d 不在任何内置命名空间的号段中", code))
+			}
+		case ns == nil:
+			// 业务代码直接使用 hecode.New 时，错误码不能占用框架内置命名空间的号段，并且要落在源码中注册的某个命名空间内
+			if builtin := builtinNamespace(code); builtin != nil {
+				c.issue(pos, fmt.Sprintf("错误码 %d 占用了内置命名空间 %s 的号段", code, builtin))
+			} else if namespaceContaining(c.report.Namespaces, code) == nil {
+				c.issue(pos, fmt.Sprintf("错误码 %d 不在任何已注册命名空间的号段中", code))
+			}
+		}
+		return true
+	})
+}
+
+// validate 检查错误码重复
+func (c *ecodeChecker) validate() {
+	byCode := make(map[int][]EcodeUsage)
+	for _, u := range c.report.Usages {
+		byCode[u.Code] = append(byCode[u.Code], u)
+	}
+	for code, usages := range byCode {
+		if len(usages) < 2 {
+			continue
+		}
+		for _, u := range usages[1:] {
+			c.issue(u.Pos, fmt.Sprintf("错误码 %d 重复，首次定义于 %s", code, usages[0].Pos))
+		}
+	}
+
+	sort.SliceStable(c.report.Issues, func(i, j int) bool {
+		a, b := c.report.Issues[i].Pos, c.report.Issues[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Line < b.Line
+	})
+}
+
+func (c *ecodeChecker) issue(pos token.Position, msg string) {
+	c.report.Issues = append(c.report.Issues, EcodeIssue{Pos: pos, Message: msg})
+}
+
+// builtinNamespace 返回号段包含 code 的内置命名空间，没有时返回 nil
+func builtinNamespace(code int) *hecode.Namespace {
+	for _, ns := range builtinNamespaces {
+		if ns.Contains(code) {
+			return ns
+		}
+	}
+	return nil
+}
+
+// namespaceContaining 返回号段包含 code 的命名空间，没有时返回 nil
+func namespaceContaining(namespaces []*hecode.Namespace, code int) *hecode.Namespace {
+	for _, ns := range namespaces {
+		if ns.Contains(code) {
+			return ns
+		}
+	}
+	return nil
+}
+
+func (c *ecodeChecker) allNamespaces() []*hecode.Namespace {
+	result := hecode.Namespaces()
+	return append(result, c.report.Namespaces...)
+}
+
+// hecodeFunc 返回调用的 hecode 包级函数名，不是 hecode 函数时返回空字符串
+func (c *ecodeChecker) hecodeFunc(f *parsedFile, fun ast.Expr) string {
+	switch fn := fun.(type) {
+	case *ast.SelectorExpr:
+		if pkg, ok := fn.X.(*ast.Ident); ok && f.alias != "" && pkg.Name == f.alias {
+			return fn.Sel.Name
+		}
+	case *ast.Ident:
+		if f.local {
+			return fn.Name
+		}
+	}
+	return ""
+}
+
+// namespaceOf 解析 xxx.New 中的 xxx 对应的命名空间，xxx 是其他包的变量并且该包不在检查的目录中时，
+// 返回 nil 和该包的导入路径
+func (c *ecodeChecker) namespaceOf(f *parsedFile, x ast.Expr) (*hecode.Namespace, string) {
+	switch v := x.(type) {
+	case *ast.Ident:
+		if f.local {
+			if ns, ok := builtinNamespaces[v.Name]; ok {
+				return ns, ""
+			}
+		}
+		return c.namespaces[f.pkg+"."+v.Name], ""
+	case *ast.SelectorExpr:
+		pkg, ok := v.X.(*ast.Ident)
+		if !ok {
+			return nil, ""
+		}
+		if f.alias != "" && pkg.Name == f.alias {
+			return builtinNamespaces[v.Sel.Name], ""
+		}
+		path, ok := f.imports[pkg.Name]
+		if !ok {
+			return nil, ""
+		}
+		if !c.packages[path] {
+			return nil, path
+		}
+		return c.namespaces[path+"."+v.Sel.Name], ""
+	}
+	return nil, ""
+}
+
+// eval 计算整数常量表达式，支持字面量、同包常量以及加减乘运算
+func (c *ecodeChecker) eval(pkg string, expr ast.Expr) (int, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.INT {
+			return 0, false
+		}
+		v, err := strconv.ParseInt(e.Value, 0, 64)
+		return int(v), err == nil
+	case *ast.Ident:
+		value, ok := c.consts[pkg+"."+e.Name]
+		if !ok {
+			return 0, false
+		}
+		return c.eval(pkg, value)
+	case *ast.ParenExpr:
+		return c.eval(pkg, e.X)
+	case *ast.BinaryExpr:
+		x, okX := c.eval(pkg, e.X)
+		y, okY := c.eval(pkg, e.Y)
+		if !okX || !okY {
+			return 0, false
+		}
+		switch e.Op {
+		case token.ADD:
+			return x + y, true
+		case token.SUB:
+			return x - y, true
+		case token.MUL:
+			return x * y, true
+		}
+	}
+	return 0, false
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
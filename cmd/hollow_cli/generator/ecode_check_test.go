@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestCheckEcode(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "order", "errors.go"), `package order
+
+import ec "github.com/vaynedu/hollow/pkg/hecode"
+
+const codeBase = 20000
+
+var ns = ec.MustRegisterNamespace("order", codeBase, codeBase+999)
+
+var (
+	ErrNotFound = ns.New(codeBase+1, "order not found")
+	ErrClosed   = ns.New(20001, "order closed")
+	ErrRange    = ns.New(21000, "out of range")
+	ErrReserved = ec.New(1150, "reserved")
+	ErrSmall    = ec.Newf(999, "small %d", 1)
+	ErrStray    = ec.New(1700, "stray")
+	ErrUnscoped = ec.Newf(30000, "unscoped %d", 1)
+	ErrScoped   = ec.New(20998, "scoped")
+)
+`)
+	writeFile(t, filepath.Join(dir, "user", "errors.go"), `package user
+
+import "github.com/vaynedu/hollow/pkg/hecode"
+
+var ns = hecode.MustRegisterNamespace("user", 20500, 21499)
+
+var ErrBiz = hecode.NamespaceBiz.New(1299, "biz")
+`)
+	// 测试文件不参与检查
+	writeFile(t, filepath.Join(dir, "user", "errors_test.go"), `package user
+
+import "github.com/vaynedu/hollow/pkg/hecode"
+
+var errTest = hecode.New(1299, "test")
+`)
+
+	report, err := CheckEcode(dir)
+	require.NoError(t, err)
+	assert.Len(t, report.Usages, 9)
+	assert.Len(t, report.Namespaces, 2)
+
+	var messages []string
+	for _, issue := range report.Issues {
+		messages = append(messages, issue.Message)
+	}
+	all := strings.Join(messages, "\n")
+	assert.Contains(t, all, "错误码 20001 重复")
+	assert.Contains(t, all, "错误码 21000 超出命名空间 order[20000-20999] 的号段")
+	assert.Contains(t, all, "错误码 1150 占用了内置命名空间 param[1100-1199] 的号段")
+	assert.Contains(t, all, "错误码 999 小于 1000")
+	assert.Contains(t, all, "命名空间 user[20500-21499] 与 order[20000-20999] 的号段重叠")
+	// 直接使用 hecode.New 的错误码也要落在某个命名空间的号段内
+	assert.Contains(t, all, "错误码 1700 不在任何已注册命名空间的号段中")
+	assert.Contains(t, all, "错误码 30000 不在任何已注册命名空间的号段中")
+	assert.NotContains(t, all, "20998")
+	assert.Len(t, report.Issues, 7)
+	assert.True(t, report.HasIssues())
+}
+
+func TestCheckEcodeCrossPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/shop\n")
+	writeFile(t, filepath.Join(dir, "ecode", "ecode.go"), `package ecode
+
+import "github.com/vaynedu/hollow/pkg/hecode"
+
+const CodeBase = 40000
+
+var Namespace = hecode.MustRegisterNamespace("shop", 40000, 40999)
+`)
+	// 使用其他包的命名空间变量创建错误码，文件本身没有引用 hecode
+	writeFile(t, filepath.Join(dir, "order", "errors.go"), `package order
+
+import (
+	shop "example.com/shop/ecode"
+	"example.com/vendor/payment"
+)
+
+var (
+	ErrNotFound = shop.Namespace.New(40001, "order not found")
+	ErrRange    = shop.Namespace.Newf(41000, "out of range %d", 1)
+	ErrDup      = shop.Namespace.New(40001, "duplicate")
+	ErrPayment  = payment.Namespace.New(50001, "payment failed")
+	ErrOther    = payment.Client.New(http, "not an error code")
+)
+`)
+
+	report, err := CheckEcode(dir)
+	require.NoError(t, err)
+	require.Len(t, report.Usages, 3)
+	assert.Equal(t, "shop", report.Usages[0].Namespace)
+
+	var messages []string
+	for _, issue := range report.Issues {
+		messages = append(messages, issue.Message)
+	}
+	assert.ElementsMatch(t, []string{
+		"错误码 41000 超出命名空间 shop[40000-40999] 的号段",
+		"错误码 40001 重复，首次定义于 " + report.Usages[0].Pos.String(),
+		"无法解析 payment.Namespace 的命名空间，所在的包 example.com/vendor/payment 不在检查的目录中",
+	}, messages)
+}
+
+func TestCheckEcodeFramework(t *testing.T) {
+	// 框架自身的错误码没有问题
+	report, err := CheckEcode(filepath.Join("..", "..", "..", "pkg", "hecode"))
+	require.NoError(t, err)
+	assert.Empty(t, report.Issues)
+	assert.NotEmpty(t, report.Usages)
+}
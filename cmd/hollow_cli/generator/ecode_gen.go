@@ -0,0 +1,679 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/emicklei/proto"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/grpc/codes"
+	"gopkg.in/yaml.v3"
+)
+
+// EcodeDefinition 错误码定义，可以从 YAML 或 proto 枚举加载
+type EcodeDefinition struct {
+	Package   string             `yaml:"package"`   // 生成的 Go 包名
+	Namespace *EcodeNamespaceDef `yaml:"namespace"` // 必填，错误码注册在该命名空间中
+	Errors    []*EcodeDef        `yaml:"errors"`
+
+	source string // 定义文件路径
+}
+
+// EcodeNamespaceDef 命名空间定义
+type EcodeNamespaceDef struct {
+	Name string `yaml:"name"`
+	Min  int    `yaml:"min"`
+	Max  int    `yaml:"max"`
+}
+
+// EcodeDef 单个错误码定义
+type EcodeDef struct {
+	Name        string            `yaml:"name"` // 驼峰格式，生成 ErrXxx、CodeXxx 和 IsXxx
+	Code        int               `yaml:"code"`
+	Message     string            `yaml:"message"`
+	HTTPStatus  int               `yaml:"http_status"`
+	GRPCCode    string            `yaml:"grpc_code"` // 例如 NotFound 或 NOT_FOUND
+	Retryable   bool              `yaml:"retryable"`
+	Severity    string            `yaml:"severity"`
+	Messages    map[string]string `yaml:"messages"` // 多语言消息，key 为语言标签，例如 zh-CN
+	Description string            `yaml:"description"`
+
+	meta hecode.Meta // 按 hecode 默认规则补全后的元信息
+}
+
+// EcodeGenOptions 错误码生成选项
+type EcodeGenOptions struct {
+	OutputDir string // 输出目录，为空时与定义文件同目录
+	Package   string // 覆盖定义中的包名
+	Go        bool   // 生成 Go 错误变量和 Is 函数
+	Markdown  bool   // 生成 Markdown 文档
+	TS        bool   // 生成 TypeScript 错误码目录
+	JSON      bool   // 生成 JSON 错误码目录
+}
+
+// GenerateEcode 根据错误码定义文件生成 Go 代码、文档和客户端错误码目录
+func GenerateEcode(defPath string, opts EcodeGenOptions) error {
+	def, err := LoadEcodeDefinition(defPath)
+	if err != nil {
+		return err
+	}
+	if opts.Package != "" {
+		def.Package = opts.Package
+	}
+	if err := def.Validate(); err != nil {
+		return err
+	}
+
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Dir(defPath)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	outputs := []struct {
+		enabled bool
+		file    string
+		render  func() ([]byte, error)
+	}{
+		{opts.Go, "ecode_gen.go", def.RenderGo},
+		{opts.Markdown, "ecode.md", def.RenderMarkdown},
+		{opts.TS, "ecode.ts", def.RenderTS},
+		{opts.JSON, "ecode.json", def.RenderJSON},
+	}
+	for _, out := range outputs {
+		if !out.enabled {
+			continue
+		}
+		content, err := out.render()
+		if err != nil {
+			return fmt.Errorf("生成 %s 失败: %w", out.file, err)
+		}
+		path := filepath.Join(outputDir, out.file)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", path, err)
+		}
+		fmt.Printf("生成文件: %s\n", path)
+	}
+	return nil
+}
+
+// LoadEcodeDefinition 加载错误码定义，支持 .yaml/.yml 和 .proto 文件
+func LoadEcodeDefinition(path string) (*EcodeDefinition, error) {
+	var def *EcodeDefinition
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		def, err = loadEcodeYAML(path)
+	case ".proto":
+		def, err = loadEcodeProto(path)
+	default:
+		return nil, fmt.Errorf("不支持的错误码定义文件类型: %s", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	def.source = filepath.Base(path)
+	return def, nil
+}
+
+func loadEcodeYAML(path string) (*EcodeDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取错误码定义失败: %w", err)
+	}
+	var def EcodeDefinition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("解析错误码定义失败: %w", err)
+	}
+	return &def, nil
+}
+
+// loadEcodeProto 从 proto 文件的枚举加载错误码定义，值为 0 的枚举项会被忽略
+// 枚举项的选项 message、http_status、grpc_code、retryable、severity 对应错误码的属性（忽略选项的包前缀），
+// 注释中的 "@lang zh-CN 消息" 设置多语言消息，其余注释作为说明；
+// 枚举的注释中可以使用 "@namespace 名称 最小值 最大值" 声明命名空间
+func loadEcodeProto(path string) (*EcodeDefinition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取错误码定义失败: %w", err)
+	}
+	defer f.Close()
+
+	parsed, err := proto.NewParser(f).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("解析 proto 文件失败: %w", err)
+	}
+
+	def := &EcodeDefinition{}
+	var enumErr error
+	proto.Walk(parsed,
+		proto.WithPackage(func(p *proto.Package) {
+			if def.Package == "" {
+				def.Package = p.Name[strings.LastIndex(p.Name, ".")+1:]
+			}
+		}),
+		proto.WithOption(func(o *proto.Option) {
+			if o.Name == "go_package" {
+				goPkg := o.Constant.Source
+				if i := strings.LastIndex(goPkg, ";"); i >= 0 {
+					def.Package = goPkg[i+1:]
+				} else {
+					def.Package = filepath.Base(goPkg)
+				}
+			}
+		}),
+		proto.WithEnum(func(e *proto.Enum) {
+			if enumErr == nil {
+				enumErr = def.addProtoEnum(e)
+			}
+		}),
+	)
+	if enumErr != nil {
+		return nil, enumErr
+	}
+	def.Package = strings.ReplaceAll(def.Package, "-", "_")
+	return def, nil
+}
+
+func (d *EcodeDefinition) addProtoEnum(e *proto.Enum) error {
+	_, annotations := splitAnnotations(e.Comment)
+	for _, a := range annotations {
+		if a[0] != "namespace" {
+			continue
+		}
+		if len(a) != 4 {
+			return fmt.Errorf("枚举 %s 的 @namespace 格式应为: @namespace 名称 最小值 最大值", e.Name)
+		}
+		min, errMin := strconv.Atoi(a[2])
+		max, errMax := strconv.Atoi(a[3])
+		if errMin != nil || errMax != nil {
+			return fmt.Errorf("枚举 %s 的 @namespace 号段无效", e.Name)
+		}
+		d.Namespace = &EcodeNamespaceDef{Name: a[1], Min: min, Max: max}
+	}
+
+	prefix := strings.ToUpper(toSnake(e.Name)) + "_"
+	for _, elem := range e.Elements {
+		field, ok := elem.(*proto.EnumField)
+		if !ok || field.Integer == 0 {
+			continue
+		}
+
+		ed := &EcodeDef{Name: toCamel(strings.TrimPrefix(field.Name, prefix)), Code: field.Integer}
+		lines, annotations := splitAnnotations(field.Comment)
+		ed.Description = strings.Join(lines, " ")
+		for _, a := range annotations {
+			if a[0] == "lang" && len(a) >= 3 {
+				if ed.Messages == nil {
+					ed.Messages = make(map[string]string)
+				}
+				ed.Messages[a[1]] = strings.Join(a[2:], " ")
+			}
+		}
+
+		for _, fe := range field.Elements {
+			opt, ok := fe.(*proto.Option)
+			if !ok {
+				continue
+			}
+			name := strings.Trim(opt.Name, "()")
+			name = name[strings.LastIndex(name, ".")+1:]
+			value := opt.Constant.Source
+			var err error
+			switch name {
+			case "message":
+				ed.Message = value
+			case "http_status":
+				ed.HTTPStatus, err = strconv.Atoi(value)
+			case "grpc_code":
+				ed.GRPCCode = value
+			case "retryable":
+				ed.Retryable, err = strconv.ParseBool(value)
+			case "severity":
+				ed.Severity = value
+			case "description":
+				ed.Description = value
+			}
+			if err != nil {
+				return fmt.Errorf("枚举项 %s 的选项 %s 无效: %w", field.Name, opt.Name, err)
+			}
+		}
+		if ed.Message == "" && field.InlineComment != nil {
+			ed.Message = strings.TrimSpace(strings.Join(field.InlineComment.Lines, " "))
+		}
+		d.Errors = append(d.Errors, ed)
+	}
+	return nil
+}
+
+// splitAnnotations 将注释拆分为普通文本行和 @ 开头的注解，注解按空白分割
+func splitAnnotations(c *proto.Comment) ([]string, [][]string) {
+	if c == nil {
+		return nil, nil
+	}
+	var lines []string
+	var annotations [][]string
+	for _, line := range c.Lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "@"):
+			if fields := strings.Fields(line[1:]); len(fields) > 0 {
+				annotations = append(annotations, fields)
+			}
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return lines, annotations
+}
+
+var goIdentRegexp = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// Validate 校验错误码定义，并按 hecode 的默认规则补全 HTTP 和 gRPC 状态码
+func (d *EcodeDefinition) Validate() error {
+	if !token.IsIdentifier(d.Package) {
+		return fmt.Errorf("包名 %q 无效", d.Package)
+	}
+	if len(d.Errors) == 0 {
+		return fmt.Errorf("没有定义错误码")
+	}
+	ns := d.Namespace
+	if ns == nil {
+		return fmt.Errorf("缺少命名空间，请在 YAML 中配置 namespace 或在 proto 枚举注释中添加 @namespace")
+	}
+	if ns.Name == "" || ns.Min < 1000 || ns.Min > ns.Max {
+		return fmt.Errorf("命名空间 %s[%d-%d] 无效", ns.Name, ns.Min, ns.Max)
+	}
+	// 生成的包在导入时注册命名空间，与已注册（包括内置）的命名空间冲突时会 panic
+	for _, other := range hecode.Namespaces() {
+		if other.Name == ns.Name {
+			return fmt.Errorf("命名空间 %s 与已注册的命名空间 %s 重名", ns.Name, other)
+		}
+		if ns.Min <= other.Max && other.Min <= ns.Max {
+			return fmt.Errorf("命名空间 %s[%d-%d] 与已注册的命名空间 %s 号段重叠", ns.Name, ns.Min, ns.Max, other)
+		}
+	}
+
+	names := make(map[string]bool)
+	registry := hecode.NewRegistry()
+	for _, e := range d.Errors {
+		if !goIdentRegexp.MatchString(e.Name) {
+			return fmt.Errorf("错误码 %d 的名称 %q 无效，应为大写开头的驼峰格式", e.Code, e.Name)
+		}
+		if names[e.Name] {
+			return fmt.Errorf("错误名称 %s 重复", e.Name)
+		}
+		names[e.Name] = true
+
+		if e.Code < 1000 {
+			return fmt.Errorf("错误码 %s=%d 小于 1000", e.Name, e.Code)
+		}
+		if e.Code < ns.Min || e.Code > ns.Max {
+			return fmt.Errorf("错误码 %s=%d 超出命名空间 %s[%d-%d] 的号段", e.Name, e.Code, ns.Name, ns.Min, ns.Max)
+		}
+		if other, ok := hecode.NamespaceOf(e.Code); ok {
+			return fmt.Errorf("错误码 %s=%d 位于已注册的命名空间 %s 中", e.Name, e.Code, other)
+		}
+		if meta, ok := hecode.DefaultRegistry().Lookup(e.Code); ok {
+			return fmt.Errorf("错误码 %s=%d 与已注册的错误码 %q 重复", e.Name, e.Code, meta.Message)
+		}
+		if e.Message == "" {
+			return fmt.Errorf("错误码 %s 缺少 message", e.Name)
+		}
+		if e.HTTPStatus != 0 && http.StatusText(e.HTTPStatus) == "" {
+			return fmt.Errorf("错误码 %s 的 http_status %d 无效", e.Name, e.HTTPStatus)
+		}
+		if e.GRPCCode != "" {
+			if _, ok := parseGRPCCode(e.GRPCCode); !ok {
+				return fmt.Errorf("错误码 %s 的 grpc_code %q 无效", e.Name, e.GRPCCode)
+			}
+		}
+		switch hecode.Severity(e.Severity) {
+		case "", hecode.SeverityInfo, hecode.SeverityWarning, hecode.SeverityError, hecode.SeverityCritical:
+		default:
+			return fmt.Errorf("错误码 %s 的 severity %q 无效", e.Name, e.Severity)
+		}
+
+		meta, err := registry.Register(e.Code, e.Message, e.options()...)
+		if err != nil {
+			return fmt.Errorf("错误码 %s: %w", e.Name, err)
+		}
+		e.meta = meta
+	}
+	return nil
+}
+
+// Source 返回定义文件名
+func (d *EcodeDefinition) Source() string {
+	return d.source
+}
+
+// Meta 返回按 hecode 默认规则补全后的元信息，Validate 之后可用
+func (e *EcodeDef) Meta() hecode.Meta {
+	return e.meta
+}
+
+// options 转换为 hecode 的错误码选项
+func (e *EcodeDef) options() []hecode.Option {
+	var opts []hecode.Option
+	if e.HTTPStatus != 0 {
+		opts = append(opts, hecode.WithHTTPStatus(e.HTTPStatus))
+	}
+	if c, ok := parseGRPCCode(e.GRPCCode); ok {
+		opts = append(opts, hecode.WithGRPCCode(c))
+	}
+	if e.Retryable {
+		opts = append(opts, hecode.WithRetryable())
+	}
+	if e.Severity != "" {
+		opts = append(opts, hecode.WithSeverity(hecode.Severity(e.Severity)))
+	}
+	for _, lang := range sortedKeys(e.Messages) {
+		opts = append(opts, hecode.WithLang(lang, e.Messages[lang]))
+	}
+	return opts
+}
+
+// parseGRPCCode 解析 gRPC 状态码名称，支持 NotFound 和 NOT_FOUND 两种写法
+func parseGRPCCode(name string) (codes.Code, bool) {
+	if name == "" {
+		return 0, false
+	}
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(toSnake(name))))); err != nil {
+		return 0, false
+	}
+	return c, true
+}
+
+// Langs 返回定义中出现的所有语言，按字母排序
+func (d *EcodeDefinition) Langs() []string {
+	set := make(map[string]string)
+	for _, e := range d.Errors {
+		for lang := range e.Messages {
+			set[strings.ToLower(lang)] = lang
+		}
+	}
+	var langs []string
+	for _, lang := range sortedKeys(set) {
+		langs = append(langs, set[lang])
+	}
+	return langs
+}
+
+// RenderGo 生成错误码常量、错误变量和 Is 函数
+func (d *EcodeDefinition) RenderGo() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ecodeGoTemplate.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// RenderMarkdown 生成错误码文档
+func (d *EcodeDefinition) RenderMarkdown() ([]byte, error) {
+	var buf bytes.Buffer
+	err := ecodeMarkdownTemplate.Execute(&buf, d)
+	return buf.Bytes(), err
+}
+
+// RenderTS 生成 TypeScript 错误码枚举和错误码目录
+func (d *EcodeDefinition) RenderTS() ([]byte, error) {
+	var buf bytes.Buffer
+	err := ecodeTSTemplate.Execute(&buf, d)
+	return buf.Bytes(), err
+}
+
+// ecodeCatalogEntry 客户端错误码目录中的一项
+type ecodeCatalogEntry struct {
+	Code        int               `json:"code"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Message     string            `json:"message"`
+	HTTPStatus  int               `json:"http_status"`
+	GRPCCode    string            `json:"grpc_code"`
+	Retryable   bool              `json:"retryable"`
+	Severity    string            `json:"severity"`
+	Messages    map[string]string `json:"messages,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// RenderJSON 生成 JSON 错误码目录
+func (d *EcodeDefinition) RenderJSON() ([]byte, error) {
+	entries := make([]ecodeCatalogEntry, 0, len(d.Errors))
+	for _, e := range d.SortedErrors() {
+		entry := ecodeCatalogEntry{
+			Code:        e.Code,
+			Name:        e.Name,
+			Namespace:   d.Namespace.Name,
+			Message:     e.Message,
+			HTTPStatus:  e.meta.HTTPStatus,
+			GRPCCode:    e.meta.GRPCCode.String(),
+			Retryable:   e.meta.Retryable,
+			Severity:    string(e.meta.Severity),
+			Messages:    e.Messages,
+			Description: e.Description,
+		}
+		entries = append(entries, entry)
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// SortedErrors 返回按错误码升序排列的错误码定义
+func (d *EcodeDefinition) SortedErrors() []*EcodeDef {
+	errs := make([]*EcodeDef, len(d.Errors))
+	copy(errs, d.Errors)
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Code < errs[j].Code
+	})
+	return errs
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toSnake 驼峰转下划线，例如 NotFound -> not_found，已经是下划线格式的保持不变
+func toSnake(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && runes[i-1] != '_' &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// toCamel 下划线转驼峰，例如 ORDER_NOT_FOUND -> OrderNotFound
+func toCamel(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(strings.ToLower(s), "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+var ecodeFuncs = template.FuncMap{
+	"quote": strconv.Quote,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"goOptions": func(e *EcodeDef) string {
+		var opts []string
+		if e.HTTPStatus != 0 {
+			opts = append(opts, fmt.Sprintf("hecode.WithHTTPStatus(%d)", e.HTTPStatus))
+		}
+		if c, ok := parseGRPCCode(e.GRPCCode); ok {
+			opts = append(opts, "hecode.WithGRPCCode(codes."+c.String()+")")
+		}
+		if e.Retryable {
+			opts = append(opts, "hecode.WithRetryable()")
+		}
+		if e.Severity != "" {
+			opts = append(opts, fmt.Sprintf("hecode.WithSeverity(%q)", e.Severity))
+		}
+		for _, lang := range sortedKeys(e.Messages) {
+			opts = append(opts, fmt.Sprintf("hecode.WithLang(%q, %q)", lang, e.Messages[lang]))
+		}
+		if len(opts) == 0 {
+			return ""
+		}
+		return ", " + strings.Join(opts, ", ")
+	},
+	"usesGRPC": func(d *EcodeDefinition) bool {
+		for _, e := range d.Errors {
+			if e.GRPCCode != "" {
+				return true
+			}
+		}
+		return false
+	},
+	"comment": func(s string) string {
+		return strings.ReplaceAll(s, "\n", " ")
+	},
+	"md": func(s string) string {
+		return strings.ReplaceAll(s, "|", "\\|")
+	},
+	"localize": func(e *EcodeDef, lang string) string {
+		return e.meta.Localize(lang)
+	},
+}
+
+var ecodeGoTemplate = template.Must(template.New("ecode_go").Funcs(ecodeFuncs).Parse(`// Code generated by hollow-cli ecode gen. DO NOT EDIT.
+// source: {{.Source}}
+
+package {{.Package}}
+
+import (
+	"github.com/vaynedu/hollow/pkg/hecode"
+{{- if usesGRPC .}}
+	"google.golang.org/grpc/codes"
+{{- end}}
+)
+
+// Namespace {{.Namespace.Name}} 模块的错误码命名空间
+var Namespace = hecode.MustRegisterNamespace({{quote .Namespace.Name}}, {{.Namespace.Min}}, {{.Namespace.Max}})
+
+// 错误码
+const (
+{{- range .Errors}}
+	// Code{{.Name}} {{comment .Message}}
+	Code{{.Name}} = {{.Code}}
+{{- end}}
+)
+
+// 错误实例
+var (
+{{- range .Errors}}
+	// Err{{.Name}} {{comment .Message}}{{if .Description}}
+	// {{comment .Description}}{{end}}
+	Err{{.Name}} = Namespace.New(Code{{.Name}}, {{quote .Message}}{{goOptions .}})
+{{- end}}
+)
+{{range .Errors}}
+// Is{{.Name}} 判断错误是否为 Err{{.Name}}
+func Is{{.Name}}(err error) bool {
+	return hecode.IsErrorCode(err, Code{{.Name}})
+}
+{{end}}`))
+
+var ecodeMarkdownTemplate = template.Must(template.New("ecode_md").Funcs(ecodeFuncs).Parse(`# {{.Package}} 错误码
+
+> 由 hollow-cli ecode gen 根据 {{.Source}} 生成，请勿手动修改
+
+命名空间：{{.Namespace.Name}}，号段 {{.Namespace.Min}} - {{.Namespace.Max}}
+
+| 名称 | 错误码 | 消息 | HTTP 状态码 | gRPC 状态码 | 可重试 | 严重程度 |{{range $.Langs}} {{.}} |{{end}} 说明 |
+|------|--------|------|-------------|-------------|--------|----------|{{range $.Langs}}------|{{end}}------|
+{{- range .SortedErrors}}
+{{- $e := .}}
+| {{.Name}} | {{.Code}} | {{md .Message}} | {{.Meta.HTTPStatus}} | {{.Meta.GRPCCode}} | {{.Meta.Retryable}} | {{.Meta.Severity}} |{{range $.Langs}} {{md (localize $e .)}} |{{end}} {{md .Description}} |
+{{- end}}
+`))
+
+var ecodeTSTemplate = template.Must(template.New("ecode_ts").Funcs(ecodeFuncs).Parse(`// Code generated by hollow-cli ecode gen. DO NOT EDIT.
+// source: {{.Source}}
+
+export enum ErrorCode {
+{{- range .SortedErrors}}
+  {{.Name}} = {{.Code}},
+{{- end}}
+}
+
+export interface ErrorInfo {
+  code: number;
+  name: string;
+  message: string;
+  httpStatus: number;
+  grpcCode: string;
+  retryable: boolean;
+  messages: Record<string, string>;
+}
+
+export const ErrorCatalog: Record<number, ErrorInfo> = {
+{{- range .SortedErrors}}
+  [ErrorCode.{{.Name}}]: {
+    code: {{.Code}},
+    name: {{json .Name}},
+    message: {{json .Message}},
+    httpStatus: {{.Meta.HTTPStatus}},
+    grpcCode: {{json .Meta.GRPCCode.String}},
+    retryable: {{.Meta.Retryable}},
+    messages: {{if .Messages}}{{json .Messages}}{{else}}{}{{end}},
+  },
+{{- end}}
+};
+
+// errorMessage 返回错误码对应语言的消息，没有对应语言时返回默认消息
+export function errorMessage(code: number, lang?: string): string | undefined {
+  const info = ErrorCatalog[code];
+  if (!info) {
+    return undefined;
+  }
+  if (lang) {
+    const normalized = lang.toLowerCase();
+    for (const [key, msg] of Object.entries(info.messages)) {
+      if (key.toLowerCase() === normalized || key.toLowerCase().split("-")[0] === normalized.split("-")[0]) {
+        return msg;
+      }
+    }
+  }
+  return info.message;
+}
+
+// isRetryable 判断错误码是否可以重试
+export function isRetryable(code: number): boolean {
+  return ErrorCatalog[code]?.retryable ?? false;
+}
+`))
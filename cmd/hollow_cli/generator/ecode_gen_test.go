@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderEcodeYAML = `package: ordererr
+namespace:
+  name: order
+  min: 30000
+  max: 30999
+errors:
+  - name: OrderNotFound
+    code: 30001
+    message: order not found
+    http_status: 404
+    messages:
+      zh-CN: 订单不存在
+    description: 订单不存在或已删除
+  - name: OrderLocked
+    code: 30002
+    message: order is locked
+    http_status: 409
+    grpc_code: ABORTED
+    retryable: true
+`
+
+func TestGenerateEcodeYAML(t *testing.T) {
+	dir := t.TempDir()
+	defPath := filepath.Join(dir, "errors.yaml")
+	writeFile(t, defPath, orderEcodeYAML)
+
+	out := filepath.Join(dir, "ordererr")
+	require.NoError(t, GenerateEcode(defPath, EcodeGenOptions{OutputDir: out, Go: true, Markdown: true, TS: true, JSON: true}))
+
+	goSrc, err := os.ReadFile(filepath.Join(out, "ecode_gen.go"))
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "ecode_gen.go", goSrc, 0)
+	require.NoError(t, err)
+	assert.Contains(t, string(goSrc), `var Namespace = hecode.MustRegisterNamespace("order", 30000, 30999)`)
+	assert.Contains(t, string(goSrc), `ErrOrderNotFound = Namespace.New(CodeOrderNotFound, "order not found", hecode.WithHTTPStatus(404), hecode.WithLang("zh-CN", "订单不存在"))`)
+	assert.Contains(t, string(goSrc), `hecode.WithGRPCCode(codes.Aborted), hecode.WithRetryable()`)
+	assert.Contains(t, string(goSrc), "func IsOrderLocked(err error) bool {")
+
+	// 生成的代码可以通过错误码检查
+	report, err := CheckEcode(out)
+	require.NoError(t, err)
+	assert.Empty(t, report.Issues)
+	assert.Len(t, report.Usages, 2)
+
+	md, err := os.ReadFile(filepath.Join(out, "ecode.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(md), "| OrderNotFound | 30001 | order not found | 404 | NotFound | false | error | 订单不存在 | 订单不存在或已删除 |")
+
+	ts, err := os.ReadFile(filepath.Join(out, "ecode.ts"))
+	require.NoError(t, err)
+	assert.Contains(t, string(ts), "OrderLocked = 30002,")
+	assert.Contains(t, string(ts), `messages: {"zh-CN":"订单不存在"},`)
+
+	var catalog []ecodeCatalogEntry
+	data, err := os.ReadFile(filepath.Join(out, "ecode.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &catalog))
+	require.Len(t, catalog, 2)
+	assert.Equal(t, "Aborted", catalog[1].GRPCCode)
+	assert.Equal(t, "order", catalog[1].Namespace)
+	assert.True(t, catalog[1].Retryable)
+}
+
+func TestLoadEcodeProto(t *testing.T) {
+	dir := t.TempDir()
+	defPath := filepath.Join(dir, "errors.proto")
+	writeFile(t, defPath, `syntax = "proto3";
+package shop.v1;
+option go_package = "example.com/shop/api/v1;shoperr";
+
+// 商品错误码
+// @namespace goods 31000 31999
+enum GoodsError {
+  GOODS_ERROR_UNSPECIFIED = 0;
+  // 商品已下架
+  // @lang zh-CN 商品已下架
+  GOODS_ERROR_OFF_SHELF = 31001 [(hollow.message) = "goods is off shelf", (hollow.http_status) = 410];
+  GOODS_ERROR_OUT_OF_STOCK = 31002 [(hollow.retryable) = true]; // out of stock
+}
+`)
+
+	def, err := LoadEcodeDefinition(defPath)
+	require.NoError(t, err)
+	require.NoError(t, def.Validate())
+	assert.Equal(t, "shoperr", def.Package)
+	assert.Equal(t, &EcodeNamespaceDef{Name: "goods", Min: 31000, Max: 31999}, def.Namespace)
+	require.Len(t, def.Errors, 2)
+
+	offShelf := def.Errors[0]
+	assert.Equal(t, "OffShelf", offShelf.Name)
+	assert.Equal(t, "goods is off shelf", offShelf.Message)
+	assert.Equal(t, 410, offShelf.HTTPStatus)
+	assert.Equal(t, "商品已下架", offShelf.Messages["zh-CN"])
+	assert.Equal(t, "商品已下架", offShelf.Description)
+
+	outOfStock := def.Errors[1]
+	assert.Equal(t, "OutOfStock", outOfStock.Name)
+	assert.Equal(t, "out of stock", outOfStock.Message)
+	assert.True(t, outOfStock.Retryable)
+	assert.Equal(t, 500, outOfStock.Meta().HTTPStatus)
+}
+
+func TestEcodeDefinitionValidate(t *testing.T) {
+	ns := &EcodeNamespaceDef{Name: "errs", Min: 2000, Max: 2099}
+	tests := []struct {
+		name string
+		def  EcodeDefinition
+		want string
+	}{
+		{"empty", EcodeDefinition{Package: "errs", Namespace: ns}, "没有定义错误码"},
+		{"invalid package", EcodeDefinition{Package: "1errs", Namespace: ns, Errors: []*EcodeDef{{Name: "A", Code: 2000, Message: "a"}}}, "包名"},
+		{"missing namespace", EcodeDefinition{Package: "errs", Errors: []*EcodeDef{{Name: "A", Code: 2000, Message: "a"}}}, "缺少命名空间"},
+		{"builtin namespace name", EcodeDefinition{Package: "errs", Namespace: &EcodeNamespaceDef{Name: "biz", Min: 2000, Max: 2099}, Errors: []*EcodeDef{{Name: "A", Code: 2000, Message: "a"}}}, "重名"},
+		{"builtin namespace range", EcodeDefinition{Package: "errs", Namespace: &EcodeNamespaceDef{Name: "errs", Min: 1000, Max: 2099}, Errors: []*EcodeDef{{Name: "A", Code: 1200, Message: "a"}}}, "号段重叠"},
+		{"invalid name", EcodeDefinition{Package: "errs", Namespace: ns, Errors: []*EcodeDef{{Name: "a", Code: 2000, Message: "a"}}}, "名称"},
+		{"duplicate code", EcodeDefinition{Package: "errs", Namespace: ns, Errors: []*EcodeDef{{Name: "A", Code: 2000, Message: "a"}, {Name: "B", Code: 2000, Message: "b"}}}, "already in use"},
+		{"out of range", EcodeDefinition{Package: "errs", Namespace: ns, Errors: []*EcodeDef{{Name: "A", Code: 2100, Message: "a"}}}, "超出命名空间"},
+		{"missing message", EcodeDefinition{Package: "errs", Namespace: ns, Errors: []*EcodeDef{{Name: "A", Code: 2000}}}, "缺少 message"},
+		{"invalid grpc code", EcodeDefinition{Package: "errs", Namespace: ns, Errors: []*EcodeDef{{Name: "A", Code: 2000, Message: "a", GRPCCode: "Bad"}}}, "grpc_code"},
+		{"invalid http status", EcodeDefinition{Package: "errs", Namespace: ns, Errors: []*EcodeDef{{Name: "A", Code: 2000, Message: "a", HTTPStatus: 999}}}, "http_status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.def.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mergeResult 把新生成的代码合并到用户已修改的文件中的结果
+type mergeResult struct {
+	Src     []byte         // 合并后的代码
+	Added   []string       // 追加的方法和类型
+	Removed []mergedMethod // 文件中有但 proto 中已删除的方法，prune 时从文件中删除，否则保留由用户确认后删除
+	Changed []mergedMethod // 签名与新生成的代码不一致的方法，保留用户的实现
+}
+
+// mergedMethod 已有文件中的一个方法
+type mergedMethod struct {
+	Name      string
+	Line      int
+	Signature string // 已有的签名
+	Want      string // 新生成的签名，仅 Changed 中有值
+}
+
+// mergeGoFile 用 go/ast 比较已有文件和新生成的代码，把已有文件缺少的顶层函数、方法和类型追加到文件末尾，
+// 同时补充它们用到的 import，已有的实现保持不变。recv 为生成的方法的接收者类型，
+// 只有该类型上导出的方法才会被检查是否已从 proto 删除。prune 为 true 时删除这些方法，
+// 以及只被它们用到的 import，用于 handler 这类调用了已删除接口方法、保留会导致编译失败的文件
+func mergeGoFile(existing, generated []byte, recv string, prune bool) (*mergeResult, error) {
+	fset := token.NewFileSet()
+	old, err := parser.ParseFile(fset, "existing.go", existing, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("解析已有文件失败: %w", err)
+	}
+	gen, err := parser.ParseFile(fset, "generated.go", generated, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("解析生成的代码失败: %w", err)
+	}
+
+	oldDecls := declsByKey(old)
+	genDecls := declsByKey(gen)
+	result := &mergeResult{}
+
+	// 追加缺少的声明，保持生成代码中的顺序
+	var appended bytes.Buffer
+	used := make(map[string]bool)
+	for _, decl := range gen.Decls {
+		for _, key := range declKeys(decl) {
+			if _, ok := oldDecls[key]; ok {
+				continue
+			}
+			appended.WriteString("\n")
+			appended.Write(declSource(fset, generated, decl))
+			appended.WriteString("\n")
+			result.Added = append(result.Added, key)
+			collectPackageRefs(decl, used)
+			break
+		}
+	}
+
+	var cuts []byteRange
+	pruned := make(map[ast.Decl]bool)
+	prunedRefs := make(map[string]bool)
+	for _, decl := range old.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || receiverType(fn) != recv || !fn.Name.IsExported() {
+			continue
+		}
+		m := mergedMethod{
+			Name:      fn.Name.Name,
+			Line:      fset.Position(fn.Pos()).Line,
+			Signature: signature(fn.Type),
+		}
+		want, ok := genDecls[recv+"."+fn.Name.Name]
+		if !ok {
+			result.Removed = append(result.Removed, m)
+			if prune {
+				cuts = append(cuts, declRange(fset, existing, decl))
+				pruned[decl] = true
+				collectPackageRefs(decl, prunedRefs)
+			}
+			continue
+		}
+		if m.Want = signature(want.(*ast.FuncDecl).Type); m.Want != m.Signature {
+			result.Changed = append(result.Changed, m)
+		}
+	}
+
+	if len(result.Added) == 0 && len(cuts) == 0 {
+		result.Src = existing
+		return result, nil
+	}
+
+	// 删除只被已删除的方法用到的 import
+	if len(cuts) > 0 {
+		remaining := make(map[string]bool)
+		for _, decl := range old.Decls {
+			if d, ok := decl.(*ast.GenDecl); (ok && d.Tok == token.IMPORT) || pruned[decl] {
+				continue
+			}
+			collectPackageRefs(decl, remaining)
+		}
+		for key := range used {
+			remaining[key] = true
+		}
+		cuts = append(cuts, unusedImports(fset, existing, old, prunedRefs, remaining)...)
+	}
+
+	// 补充追加的代码用到而已有文件中没有的 import
+	imported := make(map[string]bool)
+	for _, spec := range old.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		imported[p] = true
+	}
+	var imports []string
+	for _, spec := range gen.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if !imported[p] && used[importName(spec)] {
+			imports = append(imports, spec.Path.Value)
+		}
+	}
+
+	var buf bytes.Buffer
+	insertAt, insertText := len(existing), ""
+	if len(imports) > 0 {
+		insertAt, insertText = importInsertion(fset, old, imports)
+	}
+	sort.Slice(cuts, func(i, j int) bool { return cuts[i].start < cuts[j].start })
+	pos := 0
+	for _, cut := range cuts {
+		if insertAt >= pos && insertAt <= cut.start {
+			buf.Write(existing[pos:insertAt])
+			buf.WriteString(insertText)
+			pos, insertAt = insertAt, -1
+		}
+		buf.Write(existing[pos:cut.start])
+		pos = cut.end
+	}
+	if insertAt >= pos {
+		buf.Write(existing[pos:insertAt])
+		buf.WriteString(insertText)
+		pos = insertAt
+	}
+	buf.Write(existing[pos:])
+	buf.Write(appended.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("格式化合并后的代码失败: %w", err)
+	}
+	result.Src = src
+	return result, nil
+}
+
+// declsByKey 按 declKeys 索引文件中的顶层声明
+func declsByKey(f *ast.File) map[string]ast.Decl {
+	decls := make(map[string]ast.Decl)
+	for _, decl := range f.Decls {
+		for _, key := range declKeys(decl) {
+			decls[key] = decl
+		}
+	}
+	return decls
+}
+
+// declKeys 方法为 接收者.方法名，函数和类型为名称，import 和变量不参与合并
+func declKeys(decl ast.Decl) []string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if r := receiverType(d); r != "" {
+			return []string{r + "." + d.Name.Name}
+		}
+		return []string{d.Name.Name}
+	case *ast.GenDecl:
+		if d.Tok != token.TYPE {
+			return nil
+		}
+		var keys []string
+		for _, spec := range d.Specs {
+			keys = append(keys, spec.(*ast.TypeSpec).Name.Name)
+		}
+		return keys
+	}
+	return nil
+}
+
+// receiverType 返回方法接收者的类型名，去掉指针
+func receiverType(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// signature 参数和返回值的类型，忽略参数名，用户重命名参数不算签名变化
+func signature(ft *ast.FuncType) string {
+	list := func(fields *ast.FieldList) string {
+		if fields == nil {
+			return ""
+		}
+		var ts []string
+		for _, f := range fields.List {
+			n := len(f.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				ts = append(ts, types.ExprString(f.Type))
+			}
+		}
+		return strings.Join(ts, ", ")
+	}
+	return "(" + list(ft.Params) + ") (" + list(ft.Results) + ")"
+}
+
+// declSource 声明的源码，包含文档注释
+func declSource(fset *token.FileSet, src []byte, decl ast.Decl) []byte {
+	start := decl.Pos()
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+	}
+	return src[fset.Position(start).Offset:fset.Position(decl.End()).Offset]
+}
+
+// byteRange 文件中 [start, end) 的一段源码
+type byteRange struct {
+	start, end int
+}
+
+// declRange 声明连同文档注释和行尾换行在文件中的范围
+func declRange(fset *token.FileSet, src []byte, decl ast.Decl) byteRange {
+	start := decl.Pos()
+	if fn, ok := decl.(*ast.FuncDecl); ok && fn.Doc != nil {
+		start = fn.Doc.Pos()
+	}
+	return lineRange(src, fset.Position(start).Offset, fset.Position(decl.End()).Offset)
+}
+
+// lineRange 把 [start, end) 扩展到行首的缩进和行尾的换行，删除后不留下空行
+func lineRange(src []byte, start, end int) byteRange {
+	for start > 0 && (src[start-1] == ' ' || src[start-1] == '\t') {
+		start--
+	}
+	if end < len(src) && src[end] == '\n' {
+		end++
+	}
+	return byteRange{start: start, end: end}
+}
+
+// unusedImports 返回被 pruned 引用、删除后不再被 remaining 引用的 import 的范围，
+// 没有括号的单个 import 删除整条声明
+func unusedImports(fset *token.FileSet, src []byte, f *ast.File, pruned, remaining map[string]bool) []byteRange {
+	var cuts []byteRange
+	for _, decl := range f.Decls {
+		d, ok := decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range d.Specs {
+			is := spec.(*ast.ImportSpec)
+			name := importName(is)
+			if !pruned[name] || remaining[name] {
+				continue
+			}
+			if !d.Lparen.IsValid() {
+				cuts = append(cuts, lineRange(src, fset.Position(d.Pos()).Offset, fset.Position(d.End()).Offset))
+				continue
+			}
+			start := is.Pos()
+			if is.Doc != nil {
+				start = is.Doc.Pos()
+			}
+			end := is.End()
+			if is.Comment != nil {
+				end = is.Comment.End()
+			}
+			cuts = append(cuts, lineRange(src, fset.Position(start).Offset, fset.Position(end).Offset))
+		}
+	}
+	return cuts
+}
+
+// collectPackageRefs 收集声明中 pkg.Name 形式引用的包名
+func collectPackageRefs(decl ast.Decl, used map[string]bool) {
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+}
+
+// importName import 在代码中使用的包名，没有别名时使用路径的最后一段
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	p, _ := strconv.Unquote(spec.Path.Value)
+	return path.Base(p)
+}
+
+// importInsertion 返回插入 import 的位置和文本，有带括号的 import 时插入到括号内，
+// 否则在最后一个 import（没有时在 package）之后新增一个 import 块
+func importInsertion(fset *token.FileSet, f *ast.File, imports []string) (int, string) {
+	at := f.Name.End()
+	for _, decl := range f.Decls {
+		d, ok := decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.IMPORT {
+			continue
+		}
+		if d.Lparen.IsValid() {
+			return fset.Position(d.Rparen).Offset, "\t" + strings.Join(imports, "\n\t") + "\n"
+		}
+		at = d.End()
+	}
+	return fset.Position(at).Offset, "\n\nimport (\n\t" + strings.Join(imports, "\n\t") + "\n)"
+}
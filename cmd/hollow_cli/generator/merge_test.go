@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeGoFile(t *testing.T) {
+	existing := `package service
+
+import "context"
+
+// Svc 服务实现
+type Svc struct{ db string }
+
+// Get 用户实现
+func (s *Svc) Get(c context.Context, id string) (string, error) {
+	return s.db + id, nil
+}
+
+// Rename 参数类型已变化
+func (s *Svc) Rename(ctx context.Context, name string) error {
+	return nil
+}
+
+// Old proto 中已删除
+func (s *Svc) Old() {}
+
+func (s *Svc) helper() {}
+`
+	generated := `package service
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Svc 服务实现
+type Svc struct{}
+
+// Get 生成的实现
+func (s *Svc) Get(ctx context.Context, id string) (string, error) {
+	return "", nil
+}
+
+// Rename 参数类型已变化
+func (s *Svc) Rename(ctx context.Context, name []string) error {
+	return nil
+}
+
+// List 新增方法
+func (s *Svc) List(ctx context.Context) ([]string, error) {
+	return strings.Fields(""), nil
+}
+
+// Options 新增类型
+type Options struct{ Timeout time.Duration }
+`
+	result, err := mergeGoFile([]byte(existing), []byte(generated), "Svc", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Svc.List", "Options"}, result.Added)
+	require.Len(t, result.Removed, 1)
+	assert.Equal(t, "Old", result.Removed[0].Name)
+	assert.Equal(t, 19, result.Removed[0].Line)
+	require.Len(t, result.Changed, 1)
+	assert.Equal(t, "(context.Context, string) (error)", result.Changed[0].Signature)
+	assert.Equal(t, "(context.Context, []string) (error)", result.Changed[0].Want)
+
+	src := string(result.Src)
+	assert.Contains(t, src, "type Svc struct{ db string }")
+	assert.Contains(t, src, "return s.db + id, nil")
+	assert.Contains(t, src, "// List 新增方法\nfunc (s *Svc) List(")
+	assert.Contains(t, src, "type Options struct{ Timeout time.Duration }")
+	// 单行 import 改为 import 块，只补充用到的包
+	assert.Contains(t, src, "import \"context\"\n\nimport (\n\t\"strings\"\n\t\"time\"\n)")
+
+	// 没有新增时保持原文件
+	result, err = mergeGoFile([]byte(existing), []byte(existing), "Svc", false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Added)
+	assert.Equal(t, existing, string(result.Src))
+
+	_, err = mergeGoFile([]byte("package service\nfunc {"), []byte(generated), "Svc", false)
+	assert.Error(t, err)
+}
+
+func TestMergeGoFilePrune(t *testing.T) {
+	existing := `package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"example.com/library/proto"
+	"example.com/library/service"
+)
+
+import "fmt"
+
+// Handler 处理器
+type Handler struct{ svc service.Server }
+
+// Get 获取
+func (h *Handler) Get(name string) string {
+	return strings.TrimSpace(name)
+}
+
+// Update proto 中已改名
+func (h *Handler) Update(id string) (*proto.Book, error) {
+	n, _ := strconv.Atoi(fmt.Sprint(id))
+	return h.svc.Update(n)
+}
+`
+	generated := `package handler
+
+import (
+	"strings"
+	"time"
+
+	"example.com/library/proto"
+	"example.com/library/service"
+)
+
+// Handler 处理器
+type Handler struct{ svc service.Server }
+
+// Get 获取
+func (h *Handler) Get(name string) string {
+	return strings.TrimSpace(name)
+}
+
+// Patch 新名称
+func (h *Handler) Patch(id string) (*proto.Book, time.Duration) {
+	return nil, 0
+}
+`
+	result, err := mergeGoFile([]byte(existing), []byte(generated), "Handler", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Handler.Patch"}, result.Added)
+	require.Len(t, result.Removed, 1)
+	assert.Equal(t, "Update", result.Removed[0].Name)
+
+	src := string(result.Src)
+	assert.NotContains(t, src, "Update")
+	// 只被删除的方法用到的 import 一起删除，仍被使用的保留
+	assert.NotContains(t, src, "strconv")
+	assert.NotContains(t, src, "fmt")
+	assert.Contains(t, src, "import (\n\t\"strings\"\n\n\t\"example.com/library/proto\"\n\t\"example.com/library/service\"\n\t\"time\"\n)\n\n// Handler")
+	assert.Contains(t, src, "// Patch 新名称\nfunc (h *Handler) Patch(")
+
+	// 只有删除时也会改写文件
+	result, err = mergeGoFile([]byte(existing), []byte(strings.Replace(generated, "Patch", "Update", 2)), "Handler", true)
+	require.NoError(t, err)
+	assert.Empty(t, result.Added)
+	assert.Empty(t, result.Removed)
+	assert.Equal(t, existing, string(result.Src))
+}
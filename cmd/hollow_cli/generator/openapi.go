@@ -0,0 +1,817 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vaynedu/hollow/internal/idl"
+	"github.com/vaynedu/hollow/pkg/hecode"
+)
+
+// OpenAPIGenOptions OpenAPI 文档生成选项
+type OpenAPIGenOptions struct {
+	Output  string    // 输出文件，默认为第一个 proto 文件上级目录中的 docs/openapi.json
+	Title   string    // 文档标题，默认为第一个 proto 文件的包名
+	Version string    // 接口版本，默认为 1.0.0
+	Ecodes  []string  // 业务错误码定义文件（YAML 或 proto），与框架错误码一起列出
+	Out     io.Writer // 默认为标准输出
+}
+
+// GenerateOpenAPI 根据 proto 文件中的服务、google.api.http 注解、消息和注释生成 OpenAPI 3.1 文档，
+// 响应使用 hollow 的 {code, msg, request_id, data} 格式，错误码来自 hecode 注册表和 Ecodes 中的定义
+func GenerateOpenAPI(protoPaths []string, opts OpenAPIGenOptions) error {
+	if len(protoPaths) == 0 {
+		return fmt.Errorf("至少需要一个 proto 文件")
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+
+	var files []*idl.File
+	for _, p := range protoPaths {
+		converted, err := replaceTildeAndConvertPath(p)
+		if err != nil {
+			return err
+		}
+		file, err := idl.ParseFile(converted)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+
+	codes := hecode.DefaultRegistry().All()
+	for _, p := range opts.Ecodes {
+		def, err := LoadEcodeDefinition(p)
+		if err != nil {
+			return err
+		}
+		if err := def.Validate(); err != nil {
+			return err
+		}
+		for _, e := range def.SortedErrors() {
+			codes = append(codes, e.Meta())
+		}
+	}
+
+	b := newOpenAPIBuilder(files, codes)
+	doc, err := b.build(opts.Title, opts.Version)
+	if err != nil {
+		return err
+	}
+	for _, w := range b.warnings {
+		fmt.Fprintf(opts.Out, "⚠️  %s\n", w)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	output := opts.Output
+	if output == "" {
+		output = filepath.Join(filepath.Dir(filepath.Dir(files[0].Path)), "docs", "openapi.json")
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	if err := os.WriteFile(output, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", output, err)
+	}
+	fmt.Fprintf(opts.Out, "生成文件: %s\n", output)
+	return nil
+}
+
+// openAPIDoc OpenAPI 3.1 文档，只包含生成时用到的字段
+type openAPIDoc struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Tags       []openAPITag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Parameters  []openAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Ref         string                      `json:"$ref,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas   map[string]*openAPISchema   `json:"schemas"`
+	Responses map[string]*openAPIResponse `json:"responses"`
+}
+
+// openAPISchema JSON Schema 2020-12 的子集，数值约束保留 proto 中的原始写法
+type openAPISchema struct {
+	Ref                  string            `json:"$ref,omitempty"`
+	Type                 interface{}       `json:"type,omitempty"` // string 或 []string
+	Format               string            `json:"format,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	Properties           openAPIProperties `json:"properties,omitempty"`
+	Required             []string          `json:"required,omitempty"`
+	Items                *openAPISchema    `json:"items,omitempty"`
+	AdditionalProperties *openAPISchema    `json:"additionalProperties,omitempty"`
+	AllOf                []*openAPISchema  `json:"allOf,omitempty"`
+	Enum                 []interface{}     `json:"enum,omitempty"`
+	Const                interface{}       `json:"const,omitempty"`
+	MinLength            *json.Number      `json:"minLength,omitempty"`
+	MaxLength            *json.Number      `json:"maxLength,omitempty"`
+	Pattern              string            `json:"pattern,omitempty"`
+	Minimum              *json.Number      `json:"minimum,omitempty"`
+	Maximum              *json.Number      `json:"maximum,omitempty"`
+	ExclusiveMinimum     *json.Number      `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *json.Number      `json:"exclusiveMaximum,omitempty"`
+	MinItems             *json.Number      `json:"minItems,omitempty"`
+	MaxItems             *json.Number      `json:"maxItems,omitempty"`
+	UniqueItems          bool              `json:"uniqueItems,omitempty"`
+	ContentEncoding      string            `json:"contentEncoding,omitempty"`
+	Hecode               []hecode.Meta     `json:"x-hecode,omitempty"` // 错误码目录，供文档页面展示
+}
+
+// openAPIProperties 按 proto 中的字段顺序输出的属性
+type openAPIProperties []openAPIProperty
+
+type openAPIProperty struct {
+	Name   string
+	Schema *openAPISchema
+}
+
+// MarshalJSON 输出为 JSON 对象，保持字段顺序
+func (ps openAPIProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range ps {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(p.Name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		value, err := json.Marshal(p.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// wellKnownSchemas google.protobuf 中常用类型的 JSON 表示，与 protojson 一致
+var wellKnownSchemas = map[string]func() *openAPISchema{
+	"google.protobuf.Timestamp": func() *openAPISchema {
+		return &openAPISchema{Type: "string", Format: "date-time", Description: "RFC 3339 时间，例如 2024-01-01T00:00:00Z"}
+	},
+	"google.protobuf.Duration": func() *openAPISchema {
+		return &openAPISchema{Type: "string", Description: "以 s 结尾的秒数，例如 1.5s"}
+	},
+	"google.protobuf.FieldMask": func() *openAPISchema {
+		return &openAPISchema{Type: "string", Description: "逗号分隔的字段路径"}
+	},
+	"google.protobuf.Struct":      func() *openAPISchema { return &openAPISchema{Type: "object"} },
+	"google.protobuf.Value":       func() *openAPISchema { return &openAPISchema{} },
+	"google.protobuf.ListValue":   func() *openAPISchema { return &openAPISchema{Type: "array", Items: &openAPISchema{}} },
+	"google.protobuf.Empty":       func() *openAPISchema { return &openAPISchema{Type: "object"} },
+	"google.protobuf.Any":         func() *openAPISchema { return &openAPISchema{Type: "object", Description: "@type 指定具体类型"} },
+	"google.protobuf.StringValue": func() *openAPISchema { return scalarSchema("string") },
+	"google.protobuf.BytesValue":  func() *openAPISchema { return scalarSchema("bytes") },
+	"google.protobuf.BoolValue":   func() *openAPISchema { return scalarSchema("bool") },
+	"google.protobuf.DoubleValue": func() *openAPISchema { return scalarSchema("double") },
+	"google.protobuf.FloatValue":  func() *openAPISchema { return scalarSchema("float") },
+	"google.protobuf.Int32Value":  func() *openAPISchema { return scalarSchema("int32") },
+	"google.protobuf.UInt32Value": func() *openAPISchema { return scalarSchema("uint32") },
+	"google.protobuf.Int64Value":  func() *openAPISchema { return scalarSchema("int64") },
+	"google.protobuf.UInt64Value": func() *openAPISchema { return scalarSchema("uint64") },
+}
+
+// scalarSchema 标量类型的 JSON 表示，64 位整数在请求中可以是数字或字符串
+func scalarSchema(typ string) *openAPISchema {
+	switch typ {
+	case "double", "float":
+		return &openAPISchema{Type: "number", Format: typ}
+	case "int32", "sint32", "sfixed32":
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case "uint32", "fixed32":
+		return &openAPISchema{Type: "integer", Format: "uint32"}
+	case "int64", "sint64", "sfixed64":
+		return &openAPISchema{Type: []string{"integer", "string"}, Format: "int64"}
+	case "uint64", "fixed64":
+		return &openAPISchema{Type: []string{"integer", "string"}, Format: "uint64"}
+	case "bool":
+		return &openAPISchema{Type: "boolean"}
+	case "bytes":
+		return &openAPISchema{Type: "string", Format: "byte", ContentEncoding: "base64"}
+	}
+	return &openAPISchema{Type: "string"}
+}
+
+// openAPIMethods OpenAPI 支持的 HTTP 方法
+var openAPIMethods = map[string]bool{
+	"GET": true, "PUT": true, "POST": true, "DELETE": true, "OPTIONS": true, "HEAD": true, "PATCH": true, "TRACE": true,
+}
+
+type openAPIBuilder struct {
+	files    []*idl.File
+	codes    []hecode.Meta
+	schemas  map[string]*openAPISchema
+	warnings []string
+}
+
+func newOpenAPIBuilder(files []*idl.File, codes []hecode.Meta) *openAPIBuilder {
+	// 同一个错误码以后加载的定义为准
+	byCode := make(map[int]hecode.Meta)
+	for _, meta := range codes {
+		byCode[meta.Code] = meta
+	}
+	codes = codes[:0:0]
+	for _, meta := range byCode {
+		codes = append(codes, meta)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return &openAPIBuilder{files: files, codes: codes, schemas: make(map[string]*openAPISchema)}
+}
+
+func (b *openAPIBuilder) build(title, version string) (*openAPIDoc, error) {
+	first := b.files[0]
+	if title == "" {
+		title = first.Package
+	}
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(first.Path), filepath.Ext(first.Path))
+	}
+	if version == "" {
+		version = "1.0.0"
+	}
+	doc := &openAPIDoc{
+		OpenAPI: "3.1.0",
+		Info: openAPIInfo{
+			Title:   title,
+			Version: version,
+			Description: "所有接口返回 {code, msg, request_id, data} 格式的 JSON，成功时 code 为 200，" +
+				"失败时 code 为 hecode 错误码，HTTP 状态码由错误码决定，错误详情在 data.details 中。" +
+				"请求头 X-Request-ID 会原样返回在 request_id 中，Accept-Language 决定错误消息的语言。",
+		},
+		Paths: make(map[string]map[string]*openAPIOperation),
+	}
+
+	for _, file := range b.files {
+		for _, svc := range file.Services {
+			doc.Tags = append(doc.Tags, openAPITag{Name: svc.Name, Description: svc.Comment})
+			for _, m := range svc.Methods {
+				for i, rule := range m.HTTPRules {
+					if !openAPIMethods[rule.Method] {
+						b.warn("rpc %s.%s 的 %s 方法不能在 OpenAPI 中描述，已跳过", svc.Name, m.Name, rule.Method)
+						continue
+					}
+					path := openAPIPath(rule)
+					method := strings.ToLower(rule.Method)
+					if doc.Paths[path] == nil {
+						doc.Paths[path] = make(map[string]*openAPIOperation)
+					}
+					if _, ok := doc.Paths[path][method]; ok {
+						return nil, fmt.Errorf("重复的路由 %s %s", rule.Method, path)
+					}
+					op, err := b.operation(file, svc, m, rule)
+					if err != nil {
+						return nil, fmt.Errorf("rpc %s.%s: %w", svc.Name, m.Name, err)
+					}
+					if i > 0 {
+						op.OperationID += "_" + strconv.Itoa(i)
+					}
+					doc.Paths[path][method] = op
+				}
+			}
+		}
+	}
+
+	b.addEnvelopeSchemas()
+	doc.Components = openAPIComponents{
+		Schemas: b.schemas,
+		Responses: map[string]*openAPIResponse{
+			"InvalidParam": {
+				Description: "请求参数错误或未通过 proto 字段规则校验，data.details 中列出每个字段的违规",
+				Content:     jsonContent(&openAPISchema{Ref: "#/components/schemas/Error"}),
+			},
+			"Error": {
+				Description: "业务或系统错误，HTTP 状态码由错误码注册表决定，见 ErrorCode",
+				Content:     jsonContent(&openAPISchema{Ref: "#/components/schemas/Error"}),
+			},
+		},
+	}
+	return doc, nil
+}
+
+func (b *openAPIBuilder) warn(format string, args ...interface{}) {
+	b.warnings = append(b.warnings, fmt.Sprintf(format, args...))
+}
+
+// openAPIPath 把路径模板转换为 OpenAPI 路径，参数名与 gin 路由一致，例如 /v1/{name=shelves/*} 为 /v1/shelves/{name}
+func openAPIPath(rule idl.HTTPRule) string {
+	var sb strings.Builder
+	for _, s := range rule.Template.Segments {
+		sb.WriteByte('/')
+		if s.Param == "" {
+			sb.WriteString(s.Literal)
+		} else {
+			sb.WriteString("{" + s.Param + "}")
+		}
+	}
+	if sb.Len() == 0 {
+		sb.WriteByte('/')
+	}
+	if rule.Template.Verb != "" {
+		sb.WriteString(":" + rule.Template.Verb)
+	}
+	return sb.String()
+}
+
+func (b *openAPIBuilder) operation(file *idl.File, svc *idl.Service, m idl.Method, rule idl.HTTPRule) (*openAPIOperation, error) {
+	summary, description, _ := strings.Cut(m.Comment, "\n")
+	op := &openAPIOperation{
+		OperationID: svc.Name + "_" + m.Name,
+		Tags:        []string{svc.Name},
+		Summary:     summary,
+		Description: strings.TrimSpace(description),
+		Responses:   make(map[string]*openAPIResponse),
+	}
+	reqFile, req := b.message(file, m.RequestType)
+	respFile, resp := b.message(file, m.ResponseType)
+	if req == nil {
+		b.warn("rpc %s.%s 的请求类型 %s 未定义，不生成请求参数", svc.Name, m.Name, m.RequestType)
+	}
+
+	// 路径参数，{name=shelves/*} 这类变量由多个路径段拼接出字段的值
+	bound := make(map[string]bool)
+	for _, v := range rule.Template.Vars {
+		bound[v.Field] = true
+		var template []string
+		params := 0
+		for _, s := range v.Segments {
+			if s.Param == "" {
+				template = append(template, s.Literal)
+				continue
+			}
+			params++
+			template = append(template, "{"+s.Param+"}")
+		}
+		var field *idl.Field
+		if req != nil {
+			_, _, field = b.fieldPath(reqFile, req, v.Field)
+			if field == nil {
+				return nil, fmt.Errorf("路径变量 %s 不是请求消息中的字段", v.Field)
+			}
+		}
+		for _, s := range v.Segments {
+			if s.Param == "" {
+				continue
+			}
+			p := openAPIParameter{Name: s.Param, In: "path", Required: true, Schema: &openAPISchema{Type: "string"}}
+			if field != nil {
+				p.Description = field.Comment
+				if params == 1 && len(v.Segments) == 1 && !s.CatchAll {
+					p.Schema = b.fieldSchema(reqFile, *field, false)
+				}
+			}
+			if len(v.Segments) > 1 || s.CatchAll {
+				p.Description = joinSentences(p.Description, fmt.Sprintf("字段 %s 的值为 %s", v.Field, strings.Join(template, "/")))
+				if s.CatchAll {
+					p.Description = joinSentences(p.Description, "可以包含 /")
+				}
+			}
+			op.Parameters = append(op.Parameters, p)
+		}
+	}
+
+	if req != nil && !m.ClientStreaming && rule.Body != "*" {
+		op.Parameters = append(op.Parameters, b.queryParams(reqFile, req, "", rule.Body, bound, map[string]bool{})...)
+	}
+
+	switch {
+	case req == nil || m.ClientStreaming || rule.Body == "":
+	case rule.Body == "*":
+		op.RequestBody = &openAPIRequestBody{Required: true, Content: jsonContent(b.messageRef(reqFile, req))}
+	default:
+		f := req.Field(rule.Body)
+		if f == nil {
+			return nil, fmt.Errorf("body 字段 %s 不是请求消息中的字段", rule.Body)
+		}
+		op.RequestBody = &openAPIRequestBody{Required: true, Content: jsonContent(b.fieldSchema(reqFile, *f, true))}
+	}
+
+	var data *openAPISchema
+	switch {
+	case resp == nil:
+		data = &openAPISchema{Description: "未解析的类型 " + m.ResponseType}
+	case rule.ResponseBody != "":
+		f := resp.Field(rule.ResponseBody)
+		if f == nil {
+			return nil, fmt.Errorf("response_body 字段 %s 不是响应消息中的字段", rule.ResponseBody)
+		}
+		data = b.fieldSchema(respFile, *f, true)
+	default:
+		data = b.messageRef(respFile, resp)
+	}
+	envelope := &openAPISchema{AllOf: []*openAPISchema{
+		{Ref: "#/components/schemas/Envelope"},
+		{Type: "object", Properties: openAPIProperties{{"data", data}}},
+	}}
+
+	switch {
+	case m.ClientStreaming:
+		op.Description = joinSentences(op.Description, fmt.Sprintf("WebSocket 接口：客户端每条文本消息为一个 JSON 编码的 %s，发送空消息表示请求结束；"+
+			"服务端每条消息为标准格式的响应，出错时发送错误消息后以 1011 关闭连接", m.RequestType))
+		op.Responses["101"] = &openAPIResponse{Description: "升级为 WebSocket，消息格式见 200"}
+		op.Responses["200"] = &openAPIResponse{Description: "WebSocket 中服务端发送的每条消息", Content: jsonContent(envelope)}
+	case m.ServerStreaming:
+		op.Description = joinSentences(op.Description, "SSE 接口：每条 message 事件的 data 为一条标准格式的响应，流结束时发送 end 事件，"+
+			"开始推送后的错误以 error 事件发送")
+		op.Responses["200"] = &openAPIResponse{Description: "text/event-stream 事件流", Content: map[string]openAPIMediaType{
+			"text/event-stream": {Schema: envelope},
+		}}
+	default:
+		op.Responses["200"] = &openAPIResponse{Description: "成功", Content: jsonContent(envelope)}
+	}
+	op.Responses["400"] = &openAPIResponse{Ref: "#/components/responses/InvalidParam"}
+	op.Responses["default"] = &openAPIResponse{Ref: "#/components/responses/Error"}
+	return op, nil
+}
+
+// queryParams 没有通过路径和请求体绑定的字段作为查询参数，嵌套消息的字段展开为 a.b 形式，map 字段不支持查询参数
+func (b *openAPIBuilder) queryParams(file *idl.File, msg *idl.Message, prefix, body string, bound, visiting map[string]bool) []openAPIParameter {
+	if visiting[msg.Name] {
+		return nil
+	}
+	visiting[msg.Name] = true
+	defer delete(visiting, msg.Name)
+
+	var params []openAPIParameter
+	for _, f := range msg.Fields {
+		name := prefix + f.Name
+		if bound[name] || prefix == "" && f.Name == body || f.MapKey != "" {
+			continue
+		}
+		if nestedFile, nested := b.message(file, f.Type); nested != nil && !f.Repeated {
+			if _, ok := wellKnownSchemas[b.fullName(nestedFile, nested.Name)]; !ok {
+				params = append(params, b.queryParams(nestedFile, nested, name+".", body, bound, visiting)...)
+				continue
+			}
+		}
+		if !f.IsScalar() && b.enumSchema(file, f.Type) == "" {
+			if _, ok := wellKnownSchemas[strings.TrimPrefix(f.Type, ".")]; !ok {
+				continue
+			}
+		}
+		p := openAPIParameter{Name: name, In: "query", Description: f.Comment, Schema: b.fieldSchema(file, f, false)}
+		if f.Repeated {
+			p.Description = joinSentences(p.Description, "可以重复或用逗号分隔传多个值")
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// fieldPath 按 a.b.c 查找字段，返回字段所在的文件和消息
+func (b *openAPIBuilder) fieldPath(file *idl.File, msg *idl.Message, path string) (*idl.File, *idl.Message, *idl.Field) {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		f := msg.Field(part)
+		if f == nil {
+			return nil, nil, nil
+		}
+		if i == len(parts)-1 {
+			return file, msg, f
+		}
+		if file, msg = b.message(file, f.Type); msg == nil {
+			return nil, nil, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// message 先在引用所在的文件中查找消息，再查找其他文件
+func (b *openAPIBuilder) message(from *idl.File, name string) (*idl.File, *idl.Message) {
+	if m := from.Message(name); m != nil {
+		return from, m
+	}
+	for _, f := range b.files {
+		if f == from {
+			continue
+		}
+		if m := f.Message(name); m != nil {
+			return f, m
+		}
+	}
+	return nil, nil
+}
+
+func (b *openAPIBuilder) enum(from *idl.File, name string) (*idl.File, *idl.Enum) {
+	if e := from.Enum(name); e != nil {
+		return from, e
+	}
+	for _, f := range b.files {
+		if f == from {
+			continue
+		}
+		if e := f.Enum(name); e != nil {
+			return f, e
+		}
+	}
+	return nil, nil
+}
+
+// fullName 带包名的类型名，用作 components.schemas 中的名称
+func (b *openAPIBuilder) fullName(file *idl.File, name string) string {
+	if file.Package == "" {
+		return name
+	}
+	return file.Package + "." + name
+}
+
+// messageRef 引用消息的 schema，第一次引用时生成
+func (b *openAPIBuilder) messageRef(file *idl.File, msg *idl.Message) *openAPISchema {
+	name := b.fullName(file, msg.Name)
+	if wk, ok := wellKnownSchemas[name]; ok {
+		return wk()
+	}
+	ref := &openAPISchema{Ref: "#/components/schemas/" + name}
+	if _, ok := b.schemas[name]; ok {
+		return ref
+	}
+	s := &openAPISchema{Type: "object", Description: msg.Comment}
+	// 先占位，避免递归引用时重复生成
+	b.schemas[name] = s
+	for _, f := range msg.Fields {
+		fs := b.fieldSchema(file, f, true)
+		if f.Oneof != "" {
+			fs.Description = joinSentences(fs.Description, "属于 oneof "+f.Oneof+"，同一 oneof 中最多设置一个字段")
+		}
+		s.Properties = append(s.Properties, openAPIProperty{f.Name, fs})
+		if fieldRequired(parseFieldRules(f)) {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	return ref
+}
+
+// enumSchema 返回枚举 schema 的名称，第一次引用时生成，不是枚举时返回空
+func (b *openAPIBuilder) enumSchema(file *idl.File, typeName string) string {
+	ef, e := b.enum(file, typeName)
+	if e == nil {
+		return ""
+	}
+	name := b.fullName(ef, e.Name)
+	if _, ok := b.schemas[name]; ok {
+		return name
+	}
+	var values []interface{}
+	lines := []string{e.Comment}
+	for _, v := range e.Values {
+		values = append(values, v.Name)
+		lines = append(lines, fmt.Sprintf("- %s = %d %s", v.Name, v.Number, v.Comment))
+	}
+	for _, v := range e.Values {
+		values = append(values, v.Number)
+	}
+	lines = append(lines, "请求中可以使用名称或数值，响应中为数值")
+	b.schemas[name] = &openAPISchema{
+		Type:        []string{"string", "integer"},
+		Enum:        values,
+		Description: strings.TrimSpace(strings.Join(lines, "\n")),
+	}
+	return name
+}
+
+// fieldSchema 字段的 schema，withRules 为 true 时把校验规则转换为 JSON Schema 约束
+func (b *openAPIBuilder) fieldSchema(file *idl.File, f idl.Field, withRules bool) *openAPISchema {
+	var value *openAPISchema
+	switch {
+	case f.IsScalar():
+		value = scalarSchema(f.Type)
+	case b.enumSchema(file, f.Type) != "":
+		value = &openAPISchema{Ref: "#/components/schemas/" + b.enumSchema(file, f.Type)}
+	default:
+		if mf, m := b.message(file, f.Type); m != nil {
+			value = b.messageRef(mf, m)
+		} else if wk, ok := wellKnownSchemas[strings.TrimPrefix(f.Type, ".")]; ok {
+			value = wk()
+		} else {
+			b.warn("未解析的类型 %s，文档中不限制类型", f.Type)
+			value = &openAPISchema{Description: "未解析的类型 " + f.Type}
+		}
+	}
+
+	var rules []fieldRule
+	if withRules {
+		rules = parseFieldRules(f)
+	}
+	var s *openAPISchema
+	switch {
+	case f.MapKey != "":
+		s = &openAPISchema{Type: "object", AdditionalProperties: value}
+	case f.Repeated:
+		s = &openAPISchema{Type: "array", Items: value}
+	default:
+		s = value
+	}
+	// $ref 的兄弟字段在 3.1 中有效，但为了兼容旧的工具，带描述或约束的引用放到 allOf 中
+	if s.Ref != "" && (f.Comment != "" || hasSchemaRules(rules)) {
+		s = &openAPISchema{AllOf: []*openAPISchema{s}}
+	}
+	s.Description = joinSentences(f.Comment, s.Description)
+
+	for _, r := range rules {
+		target := s
+		name := r.Name
+		if r.Group == "repeated" && strings.HasPrefix(r.Name, "items.") && s.Items != nil {
+			target = s.Items
+			if target.Ref != "" {
+				continue
+			}
+			_, name, _ = strings.Cut(strings.TrimPrefix(r.Name, "items."), ".")
+		}
+		applySchemaRule(target, name, r.Values)
+	}
+	return s
+}
+
+// fieldRequired 字段是否标记为必填
+func fieldRequired(rules []fieldRule) bool {
+	for _, r := range rules {
+		if r.Name == "required" && (r.Group == "" || r.Group == "message") && r.Values[0] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSchemaRules(rules []fieldRule) bool {
+	for _, r := range rules {
+		if r.Name != "required" && r.Name != "skip" {
+			return true
+		}
+	}
+	return false
+}
+
+// applySchemaRule 把能用 JSON Schema 表达的校验规则写入 schema，其他规则只在生成的 Validate 中检查
+func applySchemaRule(s *openAPISchema, name string, values []string) {
+	number := func() *json.Number {
+		n := json.Number(values[0])
+		if _, err := n.Float64(); err != nil {
+			return nil
+		}
+		return &n
+	}
+	switch name {
+	case "min_len":
+		s.MinLength = number()
+	case "max_len":
+		s.MaxLength = number()
+	case "len":
+		s.MinLength, s.MaxLength = number(), number()
+	case "pattern":
+		s.Pattern = unescape(values[0])
+	case "email", "uuid", "hostname", "ipv4", "ipv6", "uri":
+		if values[0] == "true" {
+			s.Format = name
+		}
+	case "gt":
+		s.ExclusiveMinimum = number()
+	case "gte":
+		s.Minimum = number()
+	case "lt":
+		s.ExclusiveMaximum = number()
+	case "lte":
+		s.Maximum = number()
+	case "min_items":
+		s.MinItems = number()
+	case "max_items":
+		s.MaxItems = number()
+	case "unique":
+		s.UniqueItems = values[0] == "true"
+	case "in":
+		for _, v := range values {
+			if s.Type == "string" {
+				s.Enum = append(s.Enum, unescape(v))
+			} else {
+				s.Enum = append(s.Enum, json.Number(v))
+			}
+		}
+	case "const":
+		if s.Type == "string" {
+			s.Const = unescape(values[0])
+		} else {
+			s.Const = json.Number(values[0])
+		}
+	}
+}
+
+// addEnvelopeSchemas 标准响应格式、错误和错误码目录
+func (b *openAPIBuilder) addEnvelopeSchemas() {
+	b.schemas["Envelope"] = &openAPISchema{
+		Type:        "object",
+		Description: "标准响应格式",
+		Required:    []string{"code", "msg", "request_id"},
+		Properties: openAPIProperties{
+			{"code", &openAPISchema{Type: "integer", Const: 200, Description: "成功时为 200"}},
+			{"msg", &openAPISchema{Type: "string", Description: "成功时为 success"}},
+			{"request_id", &openAPISchema{Type: "string", Description: "请求 ID，与请求头 X-Request-ID 相同，未传时由服务端生成"}},
+		},
+	}
+	b.schemas["Error"] = &openAPISchema{
+		Type:        "object",
+		Description: "错误响应",
+		Required:    []string{"code", "msg", "request_id"},
+		Properties: openAPIProperties{
+			{"code", &openAPISchema{Ref: "#/components/schemas/ErrorCode"}},
+			{"msg", &openAPISchema{Type: "string", Description: "按 Accept-Language 本地化的错误消息"}},
+			{"request_id", &openAPISchema{Type: "string"}},
+			{"data", &openAPISchema{Type: "object", Properties: openAPIProperties{
+				{"details", &openAPISchema{Type: "array", Items: &openAPISchema{Ref: "#/components/schemas/ErrorDetail"}}},
+			}}},
+		},
+	}
+	b.schemas["ErrorDetail"] = &openAPISchema{
+		Type:        "object",
+		Description: "错误详情，type 为 field_violation 时有 field 和 description，retry_after 时有 seconds，resource_info 时有 resource_type 和 resource_id",
+		Required:    []string{"type"},
+		Properties: openAPIProperties{
+			{"type", &openAPISchema{Type: "string", Enum: []interface{}{"field_violation", "retry_after", "resource_info", "metadata"}}},
+			{"field", &openAPISchema{Type: "string", Description: "字段路径，例如 items[0].sku"}},
+			{"description", &openAPISchema{Type: "string"}},
+			{"seconds", &openAPISchema{Type: "integer"}},
+		},
+		AdditionalProperties: &openAPISchema{},
+	}
+
+	codes := make([]interface{}, len(b.codes))
+	for i, meta := range b.codes {
+		codes[i] = meta.Code
+	}
+	b.schemas["ErrorCode"] = &openAPISchema{
+		Type:        "integer",
+		Description: "hecode 错误码，x-hecode 中列出每个错误码的消息、HTTP 状态码和是否可重试",
+		Enum:        codes,
+		Hecode:      b.codes,
+	}
+}
+
+func jsonContent(s *openAPISchema) map[string]openAPIMediaType {
+	return map[string]openAPIMediaType{"application/json": {Schema: s}}
+}
+
+// joinSentences 用中文逗号连接非空的描述
+func joinSentences(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, "，")
+}
@@ -0,0 +1,181 @@
+package generator
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const shelfProto = `syntax = "proto3";
+package library.v1;
+
+import "google/protobuf/timestamp.proto";
+
+// 书籍
+message Book {
+  string name = 1;
+  // 书名
+  string title = 2 [(validate.rules).string = { min_len: 1 max_len: 64 }];
+  int64 id = 3;
+  Status status = 4;
+  repeated string tags = 5 [(validate.rules).repeated = { max_items: 3 unique: true }];
+  google.protobuf.Timestamp created_at = 6;
+  Author author = 7 [(buf.validate.field).required = true];
+}
+
+message Author { string name = 1; int32 age = 2 [(validate.rules).int32 = { gt: 0 lte: 150 }]; }
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  PUBLISHED = 1; // 已发布
+}
+
+message GetBookRequest { string name = 1; bool full = 2; Book filter = 3; }
+message WatchRequest { string shelf = 1; }
+
+// 书架服务
+service Library {
+  // 获取书籍
+  // 按资源名称查询
+  rpc GetBook (GetBookRequest) returns (Book) {
+    option (google.api.http) = {
+      get: "/v1/{name=shelves/*/books/*}"
+      additional_bindings { get: "/v1/books/{name}" response_body: "tags" }
+    };
+  }
+  rpc UpdateBook (Book) returns (Book) {
+    option (google.api.http) = { patch: "/v1/books/{name=**}" body: "*" };
+  }
+  rpc UpdateAuthor (GetBookRequest) returns (Author) {
+    option (google.api.http) = { put: "/v1/authors/{name}" body: "filter" };
+  }
+  rpc WatchBooks (WatchRequest) returns (stream Book);
+}
+`
+
+func TestGenerateOpenAPI(t *testing.T) {
+	dir := t.TempDir()
+	protoPath := filepath.Join(dir, "proto", "library.proto")
+	writeFile(t, protoPath, shelfProto)
+	require.NoError(t, GenerateOpenAPI([]string{protoPath}, OpenAPIGenOptions{Out: io.Discard}))
+
+	// 默认输出到项目的 docs 目录，与 App 默认读取的位置一致
+	data, err := os.ReadFile(filepath.Join(dir, "docs", "openapi.json"))
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	get := func(path ...string) interface{} {
+		var v interface{} = doc
+		for _, p := range path {
+			m, ok := v.(map[string]interface{})
+			require.True(t, ok, "路径 %v 中的 %s 不是对象", path, p)
+			v = m[p]
+		}
+		return v
+	}
+
+	assert.Equal(t, "3.1.0", doc["openapi"])
+	assert.Equal(t, "library.v1", get("info", "title"))
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "Library", "description": "书架服务"}}, doc["tags"])
+
+	// 路径模板与 gin 路由的参数一致，多段变量在描述中说明拼接方式
+	op := get("paths", "/v1/shelves/{name}/books/{name2}", "get").(map[string]interface{})
+	assert.Equal(t, "Library_GetBook", op["operationId"])
+	assert.Equal(t, "获取书籍", op["summary"])
+	assert.Equal(t, "按资源名称查询", op["description"])
+	params := op["parameters"].([]interface{})
+	var names []string
+	for _, p := range params {
+		names = append(names, p.(map[string]interface{})["name"].(string))
+	}
+	assert.Equal(t, []string{"name", "name2", "full", "filter.name", "filter.title", "filter.id", "filter.status", "filter.tags",
+		"filter.created_at", "filter.author.name", "filter.author.age"}, names)
+	assert.Contains(t, params[0].(map[string]interface{})["description"], "shelves/{name}/books/{name2}")
+	assert.Equal(t, "#/components/schemas/Envelope", get("paths", "/v1/shelves/{name}/books/{name2}", "get", "responses", "200",
+		"content", "application/json", "schema", "allOf").([]interface{})[0].(map[string]interface{})["$ref"])
+	assert.Equal(t, "#/components/responses/InvalidParam", get("paths", "/v1/shelves/{name}/books/{name2}", "get", "responses", "400", "$ref"))
+
+	// additional_bindings 和 response_body
+	op = get("paths", "/v1/books/{name}", "get").(map[string]interface{})
+	assert.Equal(t, "Library_GetBook_1", op["operationId"])
+	data200 := get("paths", "/v1/books/{name}", "get", "responses", "200", "content", "application/json", "schema", "allOf").([]interface{})[1]
+	assert.Equal(t, "array", data200.(map[string]interface{})["properties"].(map[string]interface{})["data"].(map[string]interface{})["type"])
+
+	// body 为 * 和字段
+	assert.Equal(t, "#/components/schemas/library.v1.Book",
+		get("paths", "/v1/books/{name}", "patch", "requestBody", "content", "application/json", "schema", "$ref"))
+	assert.Equal(t, "#/components/schemas/library.v1.Book",
+		get("paths", "/v1/authors/{name}", "put", "requestBody", "content", "application/json", "schema", "$ref"))
+	assert.Len(t, get("paths", "/v1/authors/{name}", "put", "parameters"), 2)
+
+	// 服务端流
+	assert.NotNil(t, get("paths", "/watchbooks", "post", "responses", "200", "content", "text/event-stream"))
+
+	// 消息 schema 保持字段顺序，校验规则转换为约束
+	book := get("components", "schemas", "library.v1.Book").(map[string]interface{})
+	assert.Equal(t, "书籍", book["description"])
+	assert.Equal(t, []interface{}{"author"}, book["required"])
+	title := get("components", "schemas", "library.v1.Book", "properties", "title").(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "书名", "minLength": 1.0, "maxLength": 64.0}, title)
+	assert.Equal(t, []interface{}{"integer", "string"}, get("components", "schemas", "library.v1.Book", "properties", "id", "type"))
+	assert.Equal(t, "date-time", get("components", "schemas", "library.v1.Book", "properties", "created_at", "format"))
+	assert.Equal(t, true, get("components", "schemas", "library.v1.Book", "properties", "tags", "uniqueItems"))
+	age := get("components", "schemas", "library.v1.Author", "properties", "age").(map[string]interface{})
+	assert.Equal(t, 0.0, age["exclusiveMinimum"])
+	assert.Equal(t, 150.0, age["maximum"])
+	assert.Contains(t, get("components", "schemas", "library.v1.Status", "enum"), "PUBLISHED")
+
+	// 错误码目录
+	codes := get("components", "schemas", "ErrorCode", "x-hecode").([]interface{})
+	assert.NotEmpty(t, codes)
+	assert.Contains(t, get("components", "schemas", "ErrorCode", "enum"), 1100.0)
+}
+
+func TestGenerateOpenAPIEcode(t *testing.T) {
+	dir := t.TempDir()
+	protoPath := filepath.Join(dir, "proto", "library.proto")
+	writeFile(t, protoPath, shelfProto)
+	ecodePath := filepath.Join(dir, "ecode.yaml")
+	writeFile(t, ecodePath, `package: ecode
+namespace:
+  name: library
+  min: 20000
+  max: 20999
+errors:
+  - name: BookNotFound
+    code: 20001
+    message: book not found
+    http_status: 404
+`)
+	output := filepath.Join(dir, "api.json")
+	require.NoError(t, GenerateOpenAPI([]string{protoPath}, OpenAPIGenOptions{
+		Output: output, Title: "Library", Version: "2.0.0", Ecodes: []string{ecodePath}, Out: io.Discard,
+	}))
+
+	var doc struct {
+		Info       openAPIInfo
+		Components struct {
+			Schemas map[string]struct {
+				Hecode []struct {
+					Code       int    `json:"code"`
+					HTTPStatus int    `json:"http_status"`
+					Message    string `json:"message"`
+				} `json:"x-hecode"`
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(readFile(t, output)), &doc))
+	assert.Equal(t, "Library", doc.Info.Title)
+	assert.Equal(t, "2.0.0", doc.Info.Version)
+	codes := doc.Components.Schemas["ErrorCode"].Hecode
+	require.NotEmpty(t, codes)
+	last := codes[len(codes)-1]
+	assert.Equal(t, 20001, last.Code)
+	assert.Equal(t, 404, last.HTTPStatus)
+	assert.Equal(t, "book not found", last.Message)
+}
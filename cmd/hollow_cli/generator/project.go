@@ -137,6 +137,17 @@ func main() {
 
 var configTemplate = `host: 127.0.0.1:8080
 
+server:
+  http:
+    addr: 127.0.0.1:8080
+    read_timeout: 15s
+    read_header_timeout: 5s
+    write_timeout: 30s
+    idle_timeout: 60s
+    max_header_bytes: 1048576
+    max_conns: 10000
+    shutdown_timeout: 10s
+
 log:
   level: debug
   output_mode: console
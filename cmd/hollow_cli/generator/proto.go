@@ -2,7 +2,11 @@
 package generator
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"go/format"
+	"io"
 	"os"
 	"os/exec"
 	"os/user"
@@ -10,7 +14,10 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/gin-gonic/gin"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/vaynedu/hollow/internal/idl"
+	"github.com/vaynedu/hollow/pkg/hbind"
 )
 
 // ProtoGenerator 代码生成器配置
@@ -19,11 +26,24 @@ type ProtoGenerator struct {
 	OutputDir       string
 	ModuleName      string
 	FrameworkImport string
-	Force           bool // 强制覆盖已存在的文件
+	Force           bool      // 强制覆盖已存在的文件
+	DryRun          bool      // 只打印将要写入的差异，不修改文件
+	Out             io.Writer // 输出生成过程和差异
+}
+
+// ProtoGenOptions proto 代码生成选项
+type ProtoGenOptions struct {
+	Force  bool      // 覆盖已存在的 handler 和 service 实现，默认只追加缺少的方法
+	DryRun bool      // 只打印差异，不写入文件
+	Client bool      // 生成调用服务的类型化 HTTP 客户端
+	Out    io.Writer // 默认为标准输出
 }
 
 // GenerateProto 生成HTTP处理代码
-func GenerateProto(protoPath string, force bool) error {
+//
+// 接口、mock、路由、字段校验和客户端每次重新生成；handler 和 service 实现由用户维护，已存在时用 go/ast 合并，
+// 只追加 proto 中新增的方法，已有的实现保持不变，proto 中删除或签名变化的方法只提示不修改
+func GenerateProto(protoPath string, opts ProtoGenOptions) error {
 	// 替换路径中的 ~ 并转换路径
 	convertedPath, err := replaceTildeAndConvertPath(protoPath)
 	if err != nil {
@@ -31,11 +51,14 @@ func GenerateProto(protoPath string, force bool) error {
 	}
 	protoPath = convertedPath
 
-	// 解析Protobuf文件（使用idl包解析服务和方法）
-	service, err := idl.ParseProto(protoPath)
+	// 解析Protobuf文件（使用idl包解析服务、消息和枚举）
+	file, err := idl.ParseFile(protoPath)
 	if err != nil {
 		return err
 	}
+	if len(file.Services) == 0 {
+		return fmt.Errorf("proto 文件中没有 service 定义: %s", protoPath)
+	}
 
 	// 获取项目模块名
 	moduleName := detectModuleName(filepath.Dir(protoPath))
@@ -45,22 +68,56 @@ func GenerateProto(protoPath string, force bool) error {
 		OutputDir:       filepath.Dir(protoPath),
 		ModuleName:      moduleName,
 		FrameworkImport: "github.com/vaynedu/hollow",
-		Force:           force,
+		Force:           opts.Force,
+		DryRun:          opts.DryRun,
+		Out:             opts.Out,
 	}
-
-	// 生成 Handler 文件
-	if err := gen.generateHandler(service); err != nil {
-		return fmt.Errorf("生成 handler 失败: %w", err)
+	if gen.Out == nil {
+		gen.Out = os.Stdout
 	}
 
-	// 生成 Service 文件
-	if err := gen.generateService(service); err != nil {
-		return fmt.Errorf("生成 service 失败: %w", err)
+	// 按字段规则生成请求校验，规则有误时在生成其他文件之前返回
+	if err := gen.generateValidate(file); err != nil {
+		return fmt.Errorf("生成校验代码失败: %w", err)
 	}
 
-	// 生成 Router 注册文件
-	if err := gen.generateRouter(service); err != nil {
-		return fmt.Errorf("生成 router 失败: %w", err)
+	// 每个服务分别生成 Handler、Service 接口和实现、Mock 和 Router 文件
+	for _, service := range file.Services {
+		if len(service.Methods) == 0 {
+			fmt.Fprintf(gen.Out, "⚠️  服务 %s 没有 rpc 方法，跳过生成\n", service.Name)
+			continue
+		}
+		// 先检查路由，避免生成一半的文件
+		if _, err := ginRoutes(service); err != nil {
+			return fmt.Errorf("生成 %s router 失败: %w", service.Name, err)
+		}
+
+		// 生成 Handler 文件
+		if err := gen.generateHandler(service); err != nil {
+			return fmt.Errorf("生成 %s handler 失败: %w", service.Name, err)
+		}
+
+		// 生成 Service 文件
+		if err := gen.generateService(service); err != nil {
+			return fmt.Errorf("生成 %s service 失败: %w", service.Name, err)
+		}
+
+		// 生成 gomock 模拟实现
+		if err := gen.generateMock(service); err != nil {
+			return fmt.Errorf("生成 %s mock 失败: %w", service.Name, err)
+		}
+
+		// 生成 Router 注册文件
+		if err := gen.generateRouter(service); err != nil {
+			return fmt.Errorf("生成 %s router 失败: %w", service.Name, err)
+		}
+
+		// 生成调用该服务的 HTTP 客户端
+		if opts.Client {
+			if err := gen.generateClient(service); err != nil {
+				return fmt.Errorf("生成 %s client 失败: %w", service.Name, err)
+			}
+		}
 	}
 
 	return nil
@@ -95,129 +152,374 @@ func detectModuleName(projectDir string) string {
 
 // 生成 Handler 文件
 func (g *ProtoGenerator) generateHandler(service *idl.Service) error {
-	handlerDir := filepath.Join(g.OutputDir, "..", "handler")
-	if err := os.MkdirAll(handlerDir, 0755); err != nil {
+	outputPath := filepath.Join(g.OutputDir, "..", "handler", strings.ToLower(service.Name)+"_handler.go")
+
+	data := map[string]interface{}{
+		"Package":         "handler",
+		"ModuleName":      g.ModuleName,
+		"FrameworkImport": g.FrameworkImport,
+		"ServiceName":     service.Name,
+		"Methods":         service.Methods,
+		"Streaming":       hasStreaming(service),
+		"BindsRequest":    bindsRequest(service),
+	}
+
+	src, err := renderGoFile(handlerTemplate, data)
+	if err != nil {
 		return err
 	}
+	// handler 调用 service 接口的方法，rpc 删除或改名后保留旧方法会编译失败
+	return g.writeUserFile(outputPath, src, service.Name+"Handler", true)
+}
 
-	outputPath := filepath.Join(handlerDir, strings.ToLower(service.Name)+"_handler.go")
+// 生成 Service 文件：接口每次重新生成，桩实现由用户维护
+func (g *ProtoGenerator) generateService(service *idl.Service) error {
+	serviceDir := filepath.Join(g.OutputDir, "..", "service")
 
-	// 检查文件是否已存在
-	if _, err := os.Stat(outputPath); err == nil {
-		if !g.Force {
-			fmt.Printf("⚠️  Handler 文件已存在，跳过生成 (使用 -f 强制覆盖): %s\n", outputPath)
-			return nil
-		}
-		fmt.Printf("📝 强制覆盖 Handler 文件: %s\n", outputPath)
+	data := map[string]interface{}{
+		"Package":         "service",
+		"ModuleName":      g.ModuleName,
+		"FrameworkImport": g.FrameworkImport,
+		"ServiceName":     service.Name,
+		"Comment":         service.Comment,
+		"Methods":         serviceMethods(service),
+		"Streaming":       hasStreaming(service),
 	}
 
-	tmpl, err := template.New("handler").Parse(handlerTemplate)
+	// 接口由 proto 决定，每次重新生成
+	src, err := renderGoFile(interfaceTemplate, data)
 	if err != nil {
 		return err
 	}
+	if err := g.writeFile(filepath.Join(serviceDir, strings.ToLower(service.Name)+"_interface.go"), src); err != nil {
+		return err
+	}
 
-	file, err := os.Create(outputPath)
+	src, err = renderGoFile(serviceTemplate, data)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return g.writeUserFile(filepath.Join(serviceDir, strings.ToLower(service.Name)+"_service.go"), src, service.Name+"Service", false)
+}
+
+// 生成 gomock 模拟实现，每次重新生成
+func (g *ProtoGenerator) generateMock(service *idl.Service) error {
 
 	data := map[string]interface{}{
-		"Package":         "handler",
+		"Package":         "mock",
 		"ModuleName":      g.ModuleName,
 		"FrameworkImport": g.FrameworkImport,
 		"ServiceName":     service.Name,
-		"Methods":         service.Methods,
+		"Methods":         serviceMethods(service),
+		"Streaming":       hasStreaming(service),
 	}
 
-	return tmpl.Execute(file, data)
+	src, err := renderGoFile(mockTemplate, data)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(filepath.Join(g.OutputDir, "..", "service", "mock", strings.ToLower(service.Name)+"_mock.go"), src)
 }
 
-// 生成 Service 文件
-func (g *ProtoGenerator) generateService(service *idl.Service) error {
-	serviceDir := filepath.Join(g.OutputDir, "..", "service")
-	if err := os.MkdirAll(serviceDir, 0755); err != nil {
+// 生成 Router 注册文件
+func (g *ProtoGenerator) generateRouter(service *idl.Service) error {
+	outputPath := filepath.Join(g.OutputDir, "..", "router", strings.ToLower(service.Name)+"_router.go")
+
+	routes, err := ginRoutes(service)
+	if err != nil {
 		return err
 	}
 
-	outputPath := filepath.Join(serviceDir, strings.ToLower(service.Name)+"_service.go")
-
-	// 检查文件是否已存在
-	if _, err := os.Stat(outputPath); err == nil {
-		if !g.Force {
-			fmt.Printf("⚠️  Service 文件已存在，跳过生成 (使用 -f 强制覆盖): %s\n", outputPath)
-			return nil
-		}
-		fmt.Printf("📝 强制覆盖 Service 文件: %s\n", outputPath)
+	data := map[string]interface{}{
+		"Package":         "router",
+		"ModuleName":      g.ModuleName,
+		"FrameworkImport": g.FrameworkImport,
+		"ServiceName":     service.Name,
+		"Routes":          routes,
 	}
 
-	tmpl, err := template.New("service").Parse(serviceTemplate)
+	// Router 文件每次重新生成（因为只是注册逻辑）
+	src, err := renderGoFile(routerTemplate, data)
 	if err != nil {
 		return err
 	}
+	return g.writeFile(outputPath, src)
+}
 
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return err
+// serviceMethod rpc 方法在 service 接口中的签名
+type serviceMethod struct {
+	idl.Method
+}
+
+func serviceMethods(service *idl.Service) []serviceMethod {
+	methods := make([]serviceMethod, len(service.Methods))
+	for i, m := range service.Methods {
+		methods[i] = serviceMethod{Method: m}
 	}
-	defer file.Close()
+	return methods
+}
 
-	data := map[string]interface{}{
-		"Package":     "service",
-		"ModuleName":  g.ModuleName,
-		"ServiceName": service.Name,
-		"ServiceVar":  strings.ToLower(service.Name[:1]) + service.Name[1:],
-		"Methods":     service.Methods,
+// Params 参数列表，请求流和响应流通过 hstream 的流类型传入
+func (m serviceMethod) Params() string {
+	req, resp := "*proto."+m.RequestType, "*proto."+m.ResponseType
+	switch {
+	case m.ClientStreaming && m.ServerStreaming:
+		return "ctx context.Context, stream hstream.BidiStream[" + req + ", " + resp + "]"
+	case m.ClientStreaming:
+		return "ctx context.Context, stream hstream.ClientStream[" + req + "]"
+	case m.ServerStreaming:
+		return "ctx context.Context, req " + req + ", stream hstream.ServerStream[" + resp + "]"
+	default:
+		return "ctx context.Context, req " + req
 	}
+}
 
-	return tmpl.Execute(file, data)
+// Args 与 Params 对应的参数名
+func (m serviceMethod) Args() []string {
+	switch {
+	case m.ClientStreaming:
+		return []string{"ctx", "stream"}
+	case m.ServerStreaming:
+		return []string{"ctx", "req", "stream"}
+	default:
+		return []string{"ctx", "req"}
+	}
 }
 
-// 生成 Router 注册文件
-func (g *ProtoGenerator) generateRouter(service *idl.Service) error {
-	routerDir := filepath.Join(g.OutputDir, "..", "router")
-	if err := os.MkdirAll(routerDir, 0755); err != nil {
-		return err
+// Results 返回值，服务端流通过 stream 发送响应，只返回 error
+func (m serviceMethod) Results() string {
+	if m.ServerStreaming {
+		return "error"
 	}
+	return "(*proto." + m.ResponseType + ", error)"
+}
 
-	outputPath := filepath.Join(routerDir, strings.ToLower(service.Name)+"_router.go")
+// Doc 接口方法的注释，proto 中没有注释时使用方法名
+func (m serviceMethod) Doc() string {
+	if m.Comment == "" {
+		return m.Name + " " + m.Name
+	}
+	return m.Name + " " + strings.ReplaceAll(m.Comment, "\n", "\n\t// ")
+}
 
-	// Router 文件每次重新生成（因为只是注册逻辑）
-	tmpl, err := template.New("router").Parse(routerTemplate)
-	if err != nil {
+// ginRoute 一个 HTTP 绑定对应的 gin 路由
+type ginRoute struct {
+	idl.HTTPRule
+	MethodName string // rpc 方法名，也是 handler 的方法名
+	GinPath    string
+	RegisterFn string // gin 的注册方法，标准 HTTP 方法为 GET、POST 等，其他为 Handle
+	Transport  string // 流式方法的传输方式，SSE 或 WebSocket
+}
+
+// ginRoutes 将每个 rpc 的主绑定和 additional_bindings 转换为 gin 路由，路径模板转换为 gin 的 :param 语法。
+// gin 要求同一位置的路径参数同名，参数名不同时沿用先出现的路由的参数名，由 hbind 按路由的路径对应回字段；
+// 改名也无法解决的冲突（例如同一位置的 :param 和 *param、重复的路由）返回错误，不等到服务启动时 panic
+func ginRoutes(service *idl.Service) ([]ginRoute, error) {
+	var routes []ginRoute
+	params := make(map[string]string)
+	for _, m := range service.Methods {
+		for _, rule := range m.HTTPRules {
+			ginPath, err := unifyParams(rule, params).GinPath()
+			if err != nil {
+				return nil, fmt.Errorf("rpc %s 的路径 %s: %w", m.Name, rule.Path, err)
+			}
+			route := ginRoute{
+				HTTPRule:   rule,
+				MethodName: m.Name,
+				GinPath:    ginPath,
+				RegisterFn: "Handle",
+			}
+			if m.ClientStreaming {
+				route.Transport = "WebSocket"
+			} else if m.ServerStreaming {
+				route.Transport = "SSE"
+			}
+			switch rule.Method {
+			case "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS":
+				route.RegisterFn = rule.Method
+			}
+			routes = append(routes, route)
+		}
+	}
+	if err := checkGinRoutes(routes); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// unifyParams 返回参数名统一后的路径模板，params 记录每个方法的 gin 路径前缀之后的参数名
+func unifyParams(rule idl.HTTPRule, params map[string]string) *hbind.PathTemplate {
+	t := *rule.Template
+	t.Segments = make([]hbind.PathSegment, len(rule.Template.Segments))
+	used := make(map[string]bool)
+	for i, s := range rule.Template.Segments {
+		if s.Param != "" {
+			prefix, _ := (&hbind.PathTemplate{Segments: t.Segments[:i]}).GinPath()
+			key := rule.Method + " " + prefix
+			if s.CatchAll {
+				key += " *"
+			}
+			if name, ok := params[key]; !ok {
+				params[key] = s.Param
+			} else if !used[name] {
+				s.Param = name
+			}
+			used[s.Param] = true
+		}
+		t.Segments[i] = s
+	}
+	return &t
+}
+
+// checkGinRoutes 把路由注册到 gin 中检查冲突，冲突时返回两个 rpc 的名称和路由
+func checkGinRoutes(routes []ginRoute) error {
+	for i, route := range routes {
+		if err := registerGinRoutes(routes[:i+1]); err == nil {
+			continue
+		}
+		for _, prev := range routes[:i] {
+			if err := registerGinRoutes([]ginRoute{prev, route}); err != nil {
+				return fmt.Errorf("rpc %s 的路由 %s %s 与 rpc %s 的路由 %s %s 冲突: %w",
+					route.MethodName, route.Method, route.Path, prev.MethodName, prev.Method, prev.Path, err)
+			}
+		}
+		return fmt.Errorf("rpc %s 的路由 %s %s 与其他路由冲突: %w", route.MethodName, route.Method, route.Path, registerGinRoutes(routes[:i+1]))
+	}
+	return nil
+}
+
+// registerGinRoutes 在新的 gin.Engine 中注册路由，gin 检测到冲突时 panic，转换为错误返回
+func registerGinRoutes(routes []ginRoute) (err error) {
+	mode := gin.Mode()
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(mode)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	engine := gin.New()
+	for _, route := range routes {
+		engine.Handle(route.Method, route.GinPath, func(*gin.Context) {})
+	}
+	return nil
+}
+
+// hasStreaming 服务中是否有流式方法，决定是否导入 hstream
+func hasStreaming(service *idl.Service) bool {
+	for _, m := range service.Methods {
+		if m.ClientStreaming || m.ServerStreaming {
+			return true
+		}
+	}
+	return false
+}
+
+// bindsRequest 服务中是否有需要 hbind 绑定请求的方法，客户端流的请求从 WebSocket 消息读取
+func bindsRequest(service *idl.Service) bool {
+	for _, m := range service.Methods {
+		if !m.ClientStreaming {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFile 写入每次重新生成的文件，DryRun 时只打印差异
+func (g *ProtoGenerator) writeFile(path string, src []byte) error {
+	if g.DryRun {
+		return g.printDiff(path, src)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
+	return os.WriteFile(path, src, 0644)
+}
 
-	file, err := os.Create(outputPath)
+// writeUserFile 写入由用户维护的文件：不存在时直接生成，Force 时覆盖，否则合并 proto 中新增的方法，
+// recv 为生成的方法的接收者类型，prune 时删除 proto 中已删除的方法，否则只提示
+func (g *ProtoGenerator) writeUserFile(path string, src []byte, recv string, prune bool) error {
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return g.writeFile(path, src)
+	}
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	// 处理方法信息，添加 HTTP 方法和路径
-	type MethodInfo struct {
-		idl.Method
-		HandlerName string
+	if g.Force {
+		fmt.Fprintf(g.Out, "📝 强制覆盖文件: %s\n", path)
+		return g.writeFile(path, src)
 	}
 
-	var methods []MethodInfo
-	for _, m := range service.Methods {
-		methods = append(methods, MethodInfo{
-			Method:      m,
-			HandlerName: m.Name + "Handler",
-		})
+	result, err := mergeGoFile(existing, src, recv, prune)
+	if err != nil {
+		return fmt.Errorf("合并 %s 失败: %w", path, err)
+	}
+	for _, name := range result.Added {
+		fmt.Fprintf(g.Out, "➕ 新增 %s: %s\n", name, path)
+	}
+	for _, m := range result.Removed {
+		if prune {
+			fmt.Fprintf(g.Out, "➖ 删除 %s.%s，proto 中已不存在: %s:%d\n", recv, m.Name, path, m.Line)
+			continue
+		}
+		fmt.Fprintf(g.Out, "⚠️  %s 不在 proto 中，已保留，确认后请手动删除: %s:%d\n", m.Name, path, m.Line)
 	}
+	for _, m := range result.Changed {
+		fmt.Fprintf(g.Out, "⚠️  %s 的签名与 proto 不一致，已保留原实现: %s:%d\n    现有: %s\n    生成: %s\n", m.Name, path, m.Line, m.Signature, m.Want)
+	}
+	if bytes.Equal(result.Src, existing) {
+		return nil
+	}
+	return g.writeFile(path, result.Src)
+}
 
-	data := map[string]interface{}{
-		"Package":         "router",
-		"ModuleName":      g.ModuleName,
-		"FrameworkImport": g.FrameworkImport,
-		"ServiceName":     service.Name,
-		"ServiceVar":      strings.ToLower(service.Name[:1]) + service.Name[1:],
-		"Methods":         methods,
+// printDiff 以 unified diff 格式打印文件将要发生的变化
+func (g *ProtoGenerator) printDiff(path string, src []byte) error {
+	old, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if bytes.Equal(old, src) {
+		return nil
 	}
+	from := path
+	if old == nil {
+		from = "/dev/null"
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(old)),
+		B:        difflib.SplitLines(string(src)),
+		FromFile: from,
+		ToFile:   path,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(g.Out, diff)
+	return err
+}
 
-	return tmpl.Execute(file, data)
+// renderGoFile 渲染模板并格式化
+func renderGoFile(text string, data interface{}) ([]byte, error) {
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		"join": strings.Join,
+		// comment 多行注释的后续行加上 //
+		"comment": func(s string) string { return strings.ReplaceAll(s, "\n", "\n// ") },
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成的代码失败: %w", err)
+	}
+	return src, nil
 }
 
 // Handler 模板
@@ -225,92 +527,269 @@ var handlerTemplate = `package handler
 
 import (
 	"github.com/gin-gonic/gin"
+{{- if .BindsRequest}}
+	"{{.FrameworkImport}}/pkg/hbind"
+{{- end}}
+{{- if .Streaming}}
+	"{{.FrameworkImport}}/pkg/hstream"
+{{- end}}
 	"{{.ModuleName}}/proto"
 	"{{.ModuleName}}/service"
 )
 
+// {{.ServiceName}}Handler {{.ServiceName}} 的 HTTP 处理器，负责绑定请求和返回响应，业务逻辑由 service.{{.ServiceName}}Server 实现
+type {{.ServiceName}}Handler struct {
+	svc service.{{.ServiceName}}Server
+}
+
+// New{{.ServiceName}}Handler 创建处理器，svc 可以是业务实现，也可以是测试中的模拟实现
+func New{{.ServiceName}}Handler(svc service.{{.ServiceName}}Server) *{{.ServiceName}}Handler {
+	return &{{.ServiceName}}Handler{svc: svc}
+}
+
 {{range .Methods}}
-// {{.Name}}Handler {{.Name}} 接口处理器
-// TODO: 实现业务逻辑
-func {{.Name}}Handler(c *gin.Context) {
+{{- if .ClientStreaming}}
+// {{.Name}} {{.Name}} 接口处理器，{{if .ServerStreaming}}双向流{{else}}客户端流{{end}}通过 WebSocket 传输
+func (h *{{$.ServiceName}}Handler) {{.Name}}(c *gin.Context) {
+	hstream.WebSocket(c, func(stream hstream.BidiStream[*proto.{{.RequestType}}, *proto.{{.ResponseType}}]) error {
+{{- if .ServerStreaming}}
+		return h.svc.{{.Name}}(stream.Context(), stream)
+{{- else}}
+		resp, err := h.svc.{{.Name}}(stream.Context(), stream)
+		if err != nil {
+			return err
+		}
+		return stream.Send(resp)
+{{- end}}
+	})
+}
+{{else if .ServerStreaming}}
+// {{.Name}} {{.Name}} 接口处理器，服务端流通过 SSE 传输
+func (h *{{$.ServiceName}}Handler) {{.Name}}(c *gin.Context) {
 	var req proto.{{.RequestType}}
-	{{if eq .HTTPMethod "GET"}}
-	// GET 请求：使用 ShouldBindQuery 绑定查询参数
-	if err := c.ShouldBindQuery(&req); err != nil {
+	// 按 google.api.http 注解绑定路径参数、查询参数和请求体，规则由路由中的 hbind.Rule 指定
+	if err := hbind.Bind(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
-	{{else}}
-	// POST/PUT/DELETE 请求：使用 ShouldBindJSON 绑定请求体
-	if err := c.ShouldBindJSON(&req); err != nil {
+
+	// 调用 service 层，service 每次 Send 推送一个事件，返回后流结束
+	hstream.SSE(c, func(stream hstream.ServerStream[*proto.{{.ResponseType}}]) error {
+		return h.svc.{{.Name}}(stream.Context(), &req, stream)
+	})
+}
+{{else}}
+// {{.Name}} {{.Name}} 接口处理器
+func (h *{{$.ServiceName}}Handler) {{.Name}}(c *gin.Context) {
+	var req proto.{{.RequestType}}
+	// 按 google.api.http 注解绑定路径参数、查询参数和请求体，规则由路由中的 hbind.Rule 指定
+	if err := hbind.Bind(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
-	{{end}}
 
 	// 调用 service 层
-	resp, err := service.{{.Name}}(c.Request.Context(), &req)
+	resp, err := h.svc.{{.Name}}(c.Request.Context(), &req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	// 设置数据，由responseMiddleware统一处理响应
-	c.Set("data", resp)
+	// 设置数据，由responseMiddleware统一处理响应，注解指定 response_body 时只返回该字段
+	c.Set("data", hbind.ResponseBody(c, resp))
 }
 {{end}}
+{{- end}}
 `
 
-// Service 模板
+// Service 接口模板
+var interfaceTemplate = `// Code generated by hollow-cli proto. DO NOT EDIT.
+
+package service
+
+import (
+	"context"
+
+{{- if .Streaming}}
+
+	"{{.FrameworkImport}}/pkg/hstream"
+{{- end}}
+
+	"{{.ModuleName}}/proto"
+)
+
+// {{.ServiceName}}Server {{if .Comment}}{{comment .Comment}}{{else}}{{.ServiceName}} 服务接口{{end}}
+//
+// handler 通过该接口调用业务逻辑，默认实现为 New{{.ServiceName}}Service，测试中可以使用 mock.NewMock{{.ServiceName}}Server
+type {{.ServiceName}}Server interface {
+{{- range .Methods}}
+	// {{.Doc}}
+	{{.Name}}({{.Params}}) {{.Results}}
+{{- end}}
+}
+`
+
+// Service 桩实现模板
 var serviceTemplate = `package service
 
 import (
 	"context"
 
+{{- if .Streaming}}
+
+	"{{.FrameworkImport}}/pkg/hstream"
+{{- end}}
+
 	"{{.ModuleName}}/proto"
 )
 
 // {{.ServiceName}}Service {{.ServiceName}} 服务实现
 type {{.ServiceName}}Service struct{}
 
-// New{{.ServiceName}}Service 创建服务实例
+// 编译期检查 {{.ServiceName}}Service 实现了 {{.ServiceName}}Server，proto 新增方法后这里会编译失败
+var _ {{.ServiceName}}Server = (*{{.ServiceName}}Service)(nil)
+
+// New{{.ServiceName}}Service 创建服务实例，依赖通过参数注入
 func New{{.ServiceName}}Service() *{{.ServiceName}}Service {
 	return &{{.ServiceName}}Service{}
 }
 
-// 全局服务实例
-var {{.ServiceVar}}Service = New{{.ServiceName}}Service()
-
 {{range .Methods}}
-// {{.Name}} {{.Name}} business logic (全局函数，供Handler调用)
-func {{.Name}}(ctx context.Context, req *proto.{{.RequestType}}) (*proto.{{.ResponseType}}, error) {
-	return {{$.ServiceVar}}Service.{{.Name}}(ctx, req)
+{{- if and .ClientStreaming .ServerStreaming}}
+// {{.Name}} {{.Name}} business logic，双向流，Recv 返回 io.EOF 表示客户端结束发送
+// TODO: Implement specific business logic
+func (s *{{$.ServiceName}}Service) {{.Name}}({{.Params}}) {{.Results}} {
+	// TODO: Implement business logic here
+	for req, err := range hstream.All(stream) {
+		if err != nil {
+			return err
+		}
+		_ = req
+		if err := stream.Send(&proto.{{.ResponseType}}{}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
-
+{{else if .ClientStreaming}}
+// {{.Name}} {{.Name}} business logic，客户端流，接收完所有请求后返回响应
+// TODO: Implement specific business logic
+func (s *{{$.ServiceName}}Service) {{.Name}}({{.Params}}) {{.Results}} {
+	// TODO: Implement business logic here
+	for req, err := range hstream.All(stream) {
+		if err != nil {
+			return nil, err
+		}
+		_ = req
+	}
+	return &proto.{{.ResponseType}}{}, nil
+}
+{{else if .ServerStreaming}}
+// {{.Name}} {{.Name}} business logic，服务端流，ctx 在客户端断开后取消
+// TODO: Implement specific business logic
+func (s *{{$.ServiceName}}Service) {{.Name}}({{.Params}}) {{.Results}} {
+	// TODO: Implement business logic here
+	return stream.Send(&proto.{{.ResponseType}}{})
+}
+{{else}}
 // {{.Name}} {{.Name}} business logic
 // TODO: Implement specific business logic
-func (s *{{$.ServiceName}}Service) {{.Name}}(ctx context.Context, req *proto.{{.RequestType}}) (*proto.{{.ResponseType}}, error) {
+func (s *{{$.ServiceName}}Service) {{.Name}}({{.Params}}) {{.Results}} {
 	// TODO: Implement business logic here
 	// 默认返回空响应，业务同学根据实际需求修改
 	return &proto.{{.ResponseType}}{}, nil
 }
 {{end}}
+{{- end}}
 `
 
-// Router 模板
-var routerTemplate = `package router
+// Mock 模板，与 mockgen 生成的代码相同，使用 go.uber.org/mock/gomock
+var mockTemplate = `// Code generated by hollow-cli proto. DO NOT EDIT.
+
+// Package mock service 接口的 gomock 模拟实现
+package mock
 
 import (
-	"{{.ModuleName}}/handler"
-	"github.com/gin-gonic/gin"
+	"context"
+	"reflect"
+
+	"go.uber.org/mock/gomock"
+
+{{- if .Streaming}}
+
+	"{{.FrameworkImport}}/pkg/hstream"
+{{- end}}
+
+	"{{.ModuleName}}/proto"
+	"{{.ModuleName}}/service"
 )
 
-// Register{{.ServiceName}}Routes 注册 {{.ServiceName}} 服务路由
-func Register{{.ServiceName}}Routes(r *gin.Engine) {
+// Mock{{.ServiceName}}Server service.{{.ServiceName}}Server 的模拟实现
+type Mock{{.ServiceName}}Server struct {
+	ctrl     *gomock.Controller
+	recorder *Mock{{.ServiceName}}ServerMockRecorder
+}
+
+// Mock{{.ServiceName}}ServerMockRecorder Mock{{.ServiceName}}Server 的调用预期记录器
+type Mock{{.ServiceName}}ServerMockRecorder struct {
+	mock *Mock{{.ServiceName}}Server
+}
+
+var _ service.{{.ServiceName}}Server = (*Mock{{.ServiceName}}Server)(nil)
+
+// NewMock{{.ServiceName}}Server 创建模拟实现
+func NewMock{{.ServiceName}}Server(ctrl *gomock.Controller) *Mock{{.ServiceName}}Server {
+	mock := &Mock{{.ServiceName}}Server{ctrl: ctrl}
+	mock.recorder = &Mock{{.ServiceName}}ServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT 返回记录器，用于设置调用预期
+func (m *Mock{{.ServiceName}}Server) EXPECT() *Mock{{.ServiceName}}ServerMockRecorder {
+	return m.recorder
+}
 {{range .Methods}}
-	// {{.Name}} - {{.HTTPMethod}} {{.Path}}
-	r.{{.HTTPMethod}}("{{.Path}}", handler.{{.HandlerName}})
+// {{.Name}} 模拟 {{.Name}} 方法
+func (m *Mock{{$.ServiceName}}Server) {{.Name}}({{.Params}}) {{.Results}} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "{{.Name}}", {{join .Args ", "}})
+{{- if .ServerStreaming}}
+	ret0, _ := ret[0].(error)
+	return ret0
+{{- else}}
+	ret0, _ := ret[0].(*proto.{{.ResponseType}})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+{{- end}}
+}
+
+// {{.Name}} 设置 {{.Name}} 方法的调用预期
+func (mr *Mock{{$.ServiceName}}ServerMockRecorder) {{.Name}}({{join .Args ", "}} any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "{{.Name}}", reflect.TypeOf((*Mock{{$.ServiceName}}Server)(nil).{{.Name}}), {{join .Args ", "}})
+}
 {{end}}
+`
+
+// Router 模板
+var routerTemplate = `// Code generated by hollow-cli proto. DO NOT EDIT.
+
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"{{.FrameworkImport}}/pkg/hbind"
+	"{{.ModuleName}}/handler"
+	"{{.ModuleName}}/service"
+)
+
+// Register{{.ServiceName}}Routes 注册 {{.ServiceName}} 服务路由，r 可以是 gin.Engine 或路由分组，svc 为业务实现
+func Register{{.ServiceName}}Routes(r gin.IRoutes, svc service.{{.ServiceName}}Server) {
+	h := handler.New{{.ServiceName}}Handler(svc)
+{{- range .Routes}}
+	// {{.MethodName}} - {{.Method}} {{.Path}}{{if .Transport}} ({{.Transport}}){{end}}
+	r.{{.RegisterFn}}({{if eq .RegisterFn "Handle"}}"{{.Method}}", {{end}}"{{.GinPath}}", hbind.Rule("{{.Path}}", "{{.Body}}", "{{.ResponseBody}}"), h.{{.MethodName}})
+{{- end}}
 }
 `
 
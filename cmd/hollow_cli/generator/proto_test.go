@@ -0,0 +1,242 @@
+package generator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const libraryProto = `syntax = "proto3";
+package library.v1;
+
+message Book { string name = 1; }
+message GetBookRequest { string name = 1; Book book = 2; }
+message ListBooksResponse { repeated Book books = 1; }
+
+service Library {
+  rpc GetBook (GetBookRequest) returns (Book) {
+    option (google.api.http) = {
+      get: "/v1/{name=shelves/*/books/*}"
+      additional_bindings { get: "/v1/books/{book.name}" }
+      additional_bindings { custom: { kind: "HEAD" path: "/v1/books/{name}" } }
+    };
+  }
+  rpc UpdateBook (Book) returns (Book) {
+    option (google.api.http) = { patch: "/v1/books/{name=**}" body: "*" };
+  }
+  rpc ListBooks (GetBookRequest) returns (ListBooksResponse) {
+    option (google.api.http) = { get: "/v1/books" response_body: "books" };
+  }
+}
+`
+
+func TestGenerateProtoRoutes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/library\n")
+	protoPath := filepath.Join(dir, "proto", "library.proto")
+	writeFile(t, protoPath, libraryProto)
+
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard}))
+
+	router, err := os.ReadFile(filepath.Join(dir, "router", "library_router.go"))
+	require.NoError(t, err)
+	for _, route := range []string{
+		`r.GET("/v1/shelves/:name/books/:name2", hbind.Rule("/v1/{name=shelves/*/books/*}", "", ""), h.GetBook)`,
+		`r.GET("/v1/books/:book_name", hbind.Rule("/v1/books/{book.name}", "", ""), h.GetBook)`,
+		`r.HEAD("/v1/books/:name", hbind.Rule("/v1/books/{name}", "", ""), h.GetBook)`,
+		`r.PATCH("/v1/books/*name", hbind.Rule("/v1/books/{name=**}", "*", ""), h.UpdateBook)`,
+		`r.GET("/v1/books", hbind.Rule("/v1/books", "", "books"), h.ListBooks)`,
+	} {
+		assert.Contains(t, string(router), route)
+	}
+
+	handler, err := os.ReadFile(filepath.Join(dir, "handler", "library_handler.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(handler), `"example.com/library/proto"`)
+	assert.Contains(t, string(handler), "if err := hbind.Bind(c, &req); err != nil {")
+	assert.Contains(t, string(handler), "resp, err := h.svc.GetBook(c.Request.Context(), &req)")
+	assert.Contains(t, string(handler), `c.Set("data", hbind.ResponseBody(c, resp))`)
+	assert.Contains(t, string(router), "func RegisterLibraryRoutes(r gin.IRoutes, svc service.LibraryServer) {")
+}
+
+func TestGenerateProtoService(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/library\n")
+	protoPath := filepath.Join(dir, "proto", "library.proto")
+	writeFile(t, protoPath, libraryProto)
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard}))
+
+	iface := readFile(t, filepath.Join(dir, "service", "library_interface.go"))
+	assert.Contains(t, iface, "type LibraryServer interface {")
+	assert.Contains(t, iface, "GetBook(ctx context.Context, req *proto.GetBookRequest) (*proto.Book, error)")
+
+	// 桩实现不再包含包级函数，通过编译期断言检查实现了接口
+	impl := readFile(t, filepath.Join(dir, "service", "library_service.go"))
+	assert.Contains(t, impl, "var _ LibraryServer = (*LibraryService)(nil)")
+	assert.Contains(t, impl, "func (s *LibraryService) ListBooks(ctx context.Context, req *proto.GetBookRequest) (*proto.ListBooksResponse, error) {")
+	assert.NotContains(t, impl, "func GetBook(")
+
+	handler := readFile(t, filepath.Join(dir, "handler", "library_handler.go"))
+	assert.Contains(t, handler, "func NewLibraryHandler(svc service.LibraryServer) *LibraryHandler {")
+
+	mock := readFile(t, filepath.Join(dir, "service", "mock", "library_mock.go"))
+	assert.Contains(t, mock, "var _ service.LibraryServer = (*MockLibraryServer)(nil)")
+	assert.Contains(t, mock, `ret := m.ctrl.Call(m, "UpdateBook", ctx, req)`)
+	assert.Contains(t, mock, "func (mr *MockLibraryServerMockRecorder) UpdateBook(ctx, req any) *gomock.Call {")
+}
+
+func TestGenerateProtoMerge(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/library\n")
+	protoPath := filepath.Join(dir, "proto", "library.proto")
+	writeFile(t, protoPath, libraryProto)
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard}))
+
+	// 用户实现了 GetBook
+	implPath := filepath.Join(dir, "service", "library_service.go")
+	impl := strings.Replace(readFile(t, implPath), "return &proto.Book{}, nil", `return &proto.Book{Name: "user code"}, nil`, 1)
+	writeFile(t, implPath, impl)
+
+	// proto 删除 UpdateBook，新增服务端流方法 WatchBooks
+	updated := strings.Replace(libraryProto, `  rpc UpdateBook (Book) returns (Book) {
+    option (google.api.http) = { patch: "/v1/books/{name=**}" body: "*" };
+  }
+`, "  rpc WatchBooks (GetBookRequest) returns (stream Book);\n", 1)
+	writeFile(t, protoPath, updated)
+
+	// dry-run 只打印差异
+	var out strings.Builder
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{DryRun: true, Out: &out}))
+	assert.Equal(t, impl, readFile(t, implPath))
+	assert.Contains(t, out.String(), "+++ "+implPath)
+	assert.Contains(t, out.String(), "+func (s *LibraryService) WatchBooks(")
+	assert.Contains(t, out.String(), "UpdateBook 不在 proto 中")
+
+	out.Reset()
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: &out}))
+	merged := readFile(t, implPath)
+	assert.Contains(t, merged, `return &proto.Book{Name: "user code"}, nil`)
+	assert.Contains(t, merged, "func (s *LibraryService) WatchBooks(ctx context.Context, req *proto.GetBookRequest, stream hstream.ServerStream[*proto.Book]) error {")
+	assert.Contains(t, merged, `"github.com/vaynedu/hollow/pkg/hstream"`)
+	// 删除的方法保留，由用户确认后删除
+	assert.Contains(t, merged, "func (s *LibraryService) UpdateBook(")
+	assert.Contains(t, out.String(), "➕ 新增 LibraryService.WatchBooks")
+	assert.Regexp(t, `UpdateBook 不在 proto 中，已保留，确认后请手动删除: .*library_service.go:\d+`, out.String())
+	// handler 调用的 service 方法已不存在，删除的方法从 handler 中删除
+	handler := readFile(t, filepath.Join(dir, "handler", "library_handler.go"))
+	assert.NotContains(t, handler, "UpdateBook")
+	assert.Contains(t, handler, "func (h *LibraryHandler) WatchBooks(")
+	assert.Regexp(t, `➖ 删除 LibraryHandler.UpdateBook，proto 中已不存在: .*library_handler.go:\d+`, out.String())
+
+	// 再次生成没有变化
+	out.Reset()
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{DryRun: true, Out: &out}))
+	assert.NotContains(t, out.String(), "+++")
+}
+
+func TestGenerateProtoRouteParams(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/users\n")
+	protoPath := filepath.Join(dir, "proto", "users.proto")
+	writeFile(t, protoPath, `syntax = "proto3";
+message User { string id = 1; string name = 2; }
+service Users {
+  rpc GetUser (User) returns (User) {
+    option (google.api.http) = { get: "/v1/users/{id}" };
+  }
+  rpc LookupUser (User) returns (User) {
+    option (google.api.http) = { get: "/v1/{name=users/*}/profile" };
+  }
+}`)
+
+	// 同一位置的路径参数使用先出现的路由的参数名，hbind 按路由的路径对应回 name 字段
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard}))
+	router, err := os.ReadFile(filepath.Join(dir, "router", "users_router.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(router), `r.GET("/v1/users/:id", hbind.Rule("/v1/users/{id}", "", ""), h.GetUser)`)
+	assert.Contains(t, string(router), `r.GET("/v1/users/:id/profile", hbind.Rule("/v1/{name=users/*}/profile", "", ""), h.LookupUser)`)
+
+	// 改名无法解决的冲突在生成时报告
+	writeFile(t, protoPath, `syntax = "proto3";
+message File { string id = 1; string path = 2; }
+service Files {
+  rpc GetFile (File) returns (File) {
+    option (google.api.http) = { get: "/v1/files/{id}" };
+  }
+  rpc Download (File) returns (File) {
+    option (google.api.http) = { get: "/v1/files/{path=**}" };
+  }
+}`)
+	err = GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rpc Download 的路由 GET /v1/files/{path=**} 与 rpc GetFile 的路由 GET /v1/files/{id} 冲突")
+	assert.NoFileExists(t, filepath.Join(dir, "router", "files_router.go"))
+}
+
+func TestGenerateProtoUnsupportedVerb(t *testing.T) {
+	dir := t.TempDir()
+	protoPath := filepath.Join(dir, "proto", "op.proto")
+	writeFile(t, protoPath, `syntax = "proto3";
+message Req { string name = 1; }
+service Operations {
+  rpc Cancel (Req) returns (Req) {
+    option (google.api.http) = { post: "/v1/{name=operations/*}:cancel" body: "*" };
+  }
+}`)
+	err := GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "不支持自定义动词 :cancel")
+}
+
+func TestGenerateProtoStreaming(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/chat\n")
+	protoPath := filepath.Join(dir, "proto", "chat.proto")
+	writeFile(t, protoPath, `syntax = "proto3";
+message Msg { string text = 1; }
+message WatchRequest { string room = 1; }
+service Chat {
+  rpc Watch (WatchRequest) returns (stream Msg) {
+    option (google.api.http) = { get: "/v1/rooms/{room}:watch" };
+  }
+  rpc Talk (stream Msg) returns (stream Msg);
+  rpc Upload (stream Msg) returns (WatchRequest);
+}`)
+	err := GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard})
+	require.Error(t, err, "gin 不支持自定义动词")
+
+	writeFile(t, protoPath, strings.Replace(readFile(t, protoPath), ":watch", "/watch", 1))
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard}))
+
+	router := readFile(t, filepath.Join(dir, "router", "chat_router.go"))
+	assert.Contains(t, router, "// Watch - GET /v1/rooms/{room}/watch (SSE)")
+	assert.Contains(t, router, `r.GET("/talk", hbind.Rule("/talk", "", ""), h.Talk)`)
+	assert.Contains(t, router, "// Upload - GET /upload (WebSocket)")
+
+	handler := readFile(t, filepath.Join(dir, "handler", "chat_handler.go"))
+	assert.Contains(t, handler, `"github.com/vaynedu/hollow/pkg/hstream"`)
+	assert.Contains(t, handler, "hstream.SSE(c, func(stream hstream.ServerStream[*proto.Msg]) error {")
+	assert.Contains(t, handler, "return h.svc.Watch(stream.Context(), &req, stream)")
+	assert.Contains(t, handler, "hstream.WebSocket(c, func(stream hstream.BidiStream[*proto.Msg, *proto.Msg]) error {")
+	assert.Contains(t, handler, "resp, err := h.svc.Upload(stream.Context(), stream)")
+
+	iface := readFile(t, filepath.Join(dir, "service", "chat_interface.go"))
+	assert.Contains(t, iface, "Watch(ctx context.Context, req *proto.WatchRequest, stream hstream.ServerStream[*proto.Msg]) error")
+	assert.Contains(t, iface, "Talk(ctx context.Context, stream hstream.BidiStream[*proto.Msg, *proto.Msg]) error")
+	assert.Contains(t, iface, "Upload(ctx context.Context, stream hstream.ClientStream[*proto.Msg]) (*proto.WatchRequest, error)")
+
+	mock := readFile(t, filepath.Join(dir, "service", "mock", "chat_mock.go"))
+	assert.Contains(t, mock, `ret := m.ctrl.Call(m, "Watch", ctx, req, stream)`)
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
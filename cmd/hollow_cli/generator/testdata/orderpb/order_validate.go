@@ -0,0 +1,81 @@
+// Code generated by hollow-cli proto. DO NOT EDIT.
+// source: order.proto
+
+package orderpb
+
+import (
+	"strings"
+
+	"github.com/vaynedu/hollow/pkg/hvalidate"
+)
+
+// Validate 按 proto 中的字段规则校验 Item，返回带所有字段违规详情的 hecode.ErrInvalidParam
+func (m *Item) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var v hvalidate.Validator
+	if hvalidate.Len(m.GetSku()) < 1 {
+		v.Add("sku", "value length must be at least 1 characters")
+	}
+	if hvalidate.Len(m.GetSku()) > 32 {
+		v.Add("sku", "value length must be at most 32 characters")
+	}
+	if !hvalidate.Match("^[A-Z]+-\\d+$", m.GetSku()) {
+		v.Add("sku", "value does not match regex pattern \"^[A-Z]+-\\\\d+$\"")
+	}
+	if m.GetQuantity() <= 0 {
+		v.Add("quantity", "value must be greater than 0")
+	}
+	return v.Err()
+}
+
+// Validate 按 proto 中的字段规则校验 CreateOrderRequest，返回带所有字段违规详情的 hecode.ErrInvalidParam
+func (m *CreateOrderRequest) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var v hvalidate.Validator
+	if !hvalidate.IsUUID(m.GetUserId()) {
+		v.Add("user_id", "value must be a valid UUID")
+	}
+	if len(m.GetItems()) < 1 {
+		v.Add("items", "value must contain at least 1 item(s)")
+	}
+	for i, item := range m.GetItems() {
+		v.Nested(hvalidate.Index("items", i), item)
+	}
+	if !hvalidate.Unique(m.GetTags()) {
+		v.Add("tags", "repeated value must contain unique items")
+	}
+	for i, item := range m.GetTags() {
+		if !hvalidate.In(item, "a", "b") {
+			v.Add(hvalidate.Index("tags", i), "value must be in list [\"a\", \"b\"]")
+		}
+	}
+	if !hvalidate.In(int32(m.GetStatus()), 0, 1, 2) {
+		v.Add("status", "value must be one of the defined enum values")
+	}
+	if hvalidate.Has(m, "email") {
+		if !hvalidate.IsEmail(m.GetEmail()) {
+			v.Add("email", "value must be a valid email address")
+		}
+	}
+	if m.GetAddress() == nil {
+		v.Add("address", "value is required")
+	}
+	v.Nested("address", m.GetAddress())
+	return v.Err()
+}
+
+// Validate 按 proto 中的字段规则校验 CreateOrderRequest.Address，返回带所有字段违规详情的 hecode.ErrInvalidParam
+func (m *CreateOrderRequest_Address) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var v hvalidate.Validator
+	if !strings.HasPrefix(m.GetCity(), "c") {
+		v.Add("city", "value does not have prefix \"c\"")
+	}
+	return v.Err()
+}
@@ -0,0 +1,539 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vaynedu/hollow/internal/idl"
+)
+
+// rulePrefixes 支持的字段校验选项：protoc-gen-validate、buf.validate 和 hollow 自定义选项，规则的写法相同
+var rulePrefixes = []string{"(validate.rules).", "(buf.validate.field).", "(hollow.rules)."}
+
+// fieldRule 字段上的一条校验规则，例如 Group 为 string、Name 为 min_len
+type fieldRule struct {
+	Option string // 完整的选项名，用于错误信息
+	Group  string
+	Name   string
+	Values []string
+}
+
+// validation 一个 proto 文件生成的校验代码
+type validation struct {
+	Messages []validateMessage
+	Strings  bool     // 是否用到 strings 包
+	Warnings []string // 不支持而被忽略的规则
+}
+
+// validateMessage 一个消息的 Validate 方法
+type validateMessage struct {
+	Name   string // proto 中的名称，例如 Order.Item
+	GoName string // 生成的 Go 类型名，例如 Order_Item
+	Checks []string
+}
+
+// buildValidation 根据字段选项生成校验代码，包含规则的消息以及通过字段引用了这些消息的消息都会生成 Validate 方法
+func buildValidation(file *idl.File) (*validation, error) {
+	var all []*idl.Message
+	var walk func(msgs []*idl.Message)
+	walk = func(msgs []*idl.Message) {
+		for _, m := range msgs {
+			all = append(all, m)
+			walk(m.Messages)
+		}
+	}
+	walk(file.Messages)
+
+	v := &validation{}
+	rules := make(map[*idl.Message][][]fieldRule)
+	need := make(map[string]bool)
+	for _, m := range all {
+		fieldRules := make([][]fieldRule, len(m.Fields))
+		for i, f := range m.Fields {
+			rs := parseFieldRules(f)
+			fieldRules[i] = rs
+			if len(rs) > 0 {
+				need[m.Name] = true
+			}
+		}
+		rules[m] = fieldRules
+	}
+	// 引用了需要校验的消息的消息也需要校验
+	for changed := true; changed; {
+		changed = false
+		for _, m := range all {
+			if need[m.Name] {
+				continue
+			}
+			for i, f := range m.Fields {
+				if nested := file.Message(f.Type); nested != nil && f.MapKey == "" && need[nested.Name] && !skipped(rules[m][i]) {
+					need[m.Name], changed = true, true
+					break
+				}
+			}
+		}
+	}
+
+	for _, m := range all {
+		if !need[m.Name] {
+			continue
+		}
+		vm := validateMessage{Name: m.Name, GoName: goTypeName(m.Name)}
+		for i, f := range m.Fields {
+			checks, err := v.fieldChecks(file, f, rules[m][i], need)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", m.Name, f.Name, err)
+			}
+			vm.Checks = append(vm.Checks, checks...)
+		}
+		v.Messages = append(v.Messages, vm)
+	}
+	return v, nil
+}
+
+// parseFieldRules 从字段选项中取出校验规则，同一规则出现多次（例如 in 列表）时合并为多个值
+func parseFieldRules(f idl.Field) []fieldRule {
+	var rules []fieldRule
+	index := make(map[string]int)
+	for _, o := range f.Options {
+		var rest string
+		for _, prefix := range rulePrefixes {
+			if strings.HasPrefix(o.Name, prefix) {
+				rest = strings.TrimPrefix(o.Name, prefix)
+				break
+			}
+		}
+		if rest == "" {
+			continue
+		}
+		var r fieldRule
+		// required 和 ignore 直接写在字段规则上，其他规则按类型分组
+		if group, name, ok := strings.Cut(rest, "."); ok {
+			r = fieldRule{Option: o.Name, Group: group, Name: name}
+		} else {
+			r = fieldRule{Option: o.Name, Name: rest}
+		}
+		if i, ok := index[o.Name]; ok {
+			rules[i].Values = append(rules[i].Values, o.Value)
+			continue
+		}
+		r.Values = []string{o.Value}
+		index[o.Name] = len(rules)
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// skipped 字段是否标记为不校验嵌套消息
+func skipped(rules []fieldRule) bool {
+	for _, r := range rules {
+		if r.Name == "skip" || r.Name == "ignore" && r.Group == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldChecks 生成一个字段的校验代码，optional 和 oneof 字段只在设置时校验
+func (v *validation) fieldChecks(file *idl.File, f idl.Field, rules []fieldRule, need map[string]bool) ([]string, error) {
+	getter := "m.Get" + goCamelCase(f.Name) + "()"
+	path := strconv.Quote(f.Name)
+	kind := fieldKind(file, f)
+
+	var checks []string
+	var items []fieldRule
+	for _, r := range rules {
+		switch {
+		case r.Group == "" && (r.Name == "ignore" || r.Name == "skip"):
+			// 不校验
+		case r.Group == "" && r.Name == "required", r.Group == "message" && r.Name == "required":
+			if r.Values[0] != "true" {
+				continue
+			}
+			checks = append(checks, requiredCheck(f, kind, getter, path))
+		case r.Group == "message" && r.Name == "skip":
+		case r.Group == "repeated" && f.Repeated && f.MapKey == "":
+			if strings.HasPrefix(r.Name, "items.") {
+				group, name, _ := strings.Cut(strings.TrimPrefix(r.Name, "items."), ".")
+				items = append(items, fieldRule{Option: r.Option, Group: group, Name: name, Values: r.Values})
+				continue
+			}
+			check, err := v.collectionCheck(f, r, getter, path)
+			if err != nil {
+				return nil, err
+			}
+			if check != "" {
+				checks = append(checks, check)
+			}
+		case r.Group == "map" && f.MapKey != "":
+			check, err := v.collectionCheck(f, r, getter, path)
+			if err != nil {
+				return nil, err
+			}
+			if check != "" {
+				checks = append(checks, check)
+			}
+		case f.Repeated || f.MapKey != "":
+			return nil, fmt.Errorf("%s 不能用于 repeated 或 map 字段，元素的规则请写在 repeated.items 中", r.Option)
+		default:
+			if r.Group != kind {
+				return nil, fmt.Errorf("%s 与字段类型 %s 不匹配", r.Option, f.Type)
+			}
+			check, err := v.valueCheck(file, f, r, getter, path)
+			if err != nil {
+				return nil, err
+			}
+			if check != "" {
+				checks = append(checks, check)
+			}
+		}
+	}
+
+	// repeated 元素的规则对每个元素校验
+	if len(items) > 0 {
+		var body []string
+		for _, r := range items {
+			if r.Group != kind {
+				return nil, fmt.Errorf("%s 与元素类型 %s 不匹配", r.Option, f.Type)
+			}
+			check, err := v.valueCheck(file, f, r, "item", `hvalidate.Index(`+path+`, i)`)
+			if err != nil {
+				return nil, err
+			}
+			if check != "" {
+				body = append(body, check)
+			}
+		}
+		if len(body) > 0 {
+			checks = append(checks, "for i, item := range "+getter+" {\n"+strings.Join(body, "\n")+"\n}")
+		}
+	}
+
+	// 嵌套消息
+	if nested := file.Message(f.Type); nested != nil && need[nested.Name] && f.MapKey == "" && !skipped(rules) {
+		if f.Repeated {
+			checks = append(checks, "for i, item := range "+getter+" {\nv.Nested(hvalidate.Index("+path+", i), item)\n}")
+		} else {
+			checks = append(checks, "v.Nested("+path+", "+getter+")")
+		}
+	}
+
+	if len(checks) > 0 && (f.Optional || f.Oneof != "") {
+		return []string{"if hvalidate.Has(m, " + path + ") {\n" + strings.Join(checks, "\n") + "\n}"}, nil
+	}
+	return checks, nil
+}
+
+// fieldKind 字段类型对应的规则分组：标量为类型名，枚举为 enum，其他为 message
+func fieldKind(file *idl.File, f idl.Field) string {
+	if f.IsScalar() {
+		return f.Type
+	}
+	if file.Enum(f.Type) != nil {
+		return "enum"
+	}
+	return "message"
+}
+
+func requiredCheck(f idl.Field, kind, getter, path string) string {
+	var cond string
+	switch {
+	case f.Repeated || f.MapKey != "" || kind == "bytes":
+		cond = "len(" + getter + ") == 0"
+	case kind == "message":
+		cond = getter + " == nil"
+	case kind == "string":
+		cond = getter + ` == ""`
+	case kind == "bool":
+		cond = "!" + getter
+	default:
+		cond = getter + " == 0"
+	}
+	return check(cond, path, "value is required")
+}
+
+// collectionCheck repeated 和 map 字段本身的规则
+func (v *validation) collectionCheck(f idl.Field, r fieldRule, getter, path string) (string, error) {
+	switch r.Name {
+	case "min_items", "min_pairs":
+		n, err := intValue(r)
+		if err != nil {
+			return "", err
+		}
+		return check("len("+getter+") < "+n, path, fmt.Sprintf("value must contain at least %s item(s)", n)), nil
+	case "max_items", "max_pairs":
+		n, err := intValue(r)
+		if err != nil {
+			return "", err
+		}
+		return check("len("+getter+") > "+n, path, fmt.Sprintf("value must contain at most %s item(s)", n)), nil
+	case "unique":
+		if r.Values[0] != "true" {
+			return "", nil
+		}
+		if !f.IsScalar() || f.Type == "bytes" {
+			return "", fmt.Errorf("%s 只支持标量类型的 repeated 字段", r.Option)
+		}
+		return check("!hvalidate.Unique("+getter+")", path, "repeated value must contain unique items"), nil
+	}
+	v.warn(r)
+	return "", nil
+}
+
+// valueCheck 标量和枚举值的规则
+func (v *validation) valueCheck(file *idl.File, f idl.Field, r fieldRule, expr, path string) (string, error) {
+	switch r.Group {
+	case "string":
+		return v.stringCheck(r, expr, path)
+	case "bytes":
+		switch r.Name {
+		case "len", "min_len", "max_len":
+			return lengthCheck(r, "len("+expr+")", path, "bytes")
+		}
+	case "bool":
+		if r.Name == "const" {
+			return check(expr+" != "+r.Values[0], path, "value must equal "+r.Values[0]), nil
+		}
+	case "enum":
+		if r.Name == "defined_only" {
+			if r.Values[0] != "true" {
+				return "", nil
+			}
+			e := file.Enum(f.Type)
+			return check("!hvalidate.In(int32("+expr+"), "+enumNumbers(e)+")", path, "value must be one of the defined enum values"), nil
+		}
+		return numberCheck(r, expr, path)
+	case "double", "float", "int32", "int64", "uint32", "uint64", "sint32", "sint64",
+		"fixed32", "fixed64", "sfixed32", "sfixed64":
+		return numberCheck(r, expr, path)
+	}
+	v.warn(r)
+	return "", nil
+}
+
+func (v *validation) stringCheck(r fieldRule, expr, path string) (string, error) {
+	value := unescape(r.Values[0])
+	switch r.Name {
+	case "len", "min_len", "max_len":
+		return lengthCheck(r, "hvalidate.Len("+expr+")", path, "characters")
+	case "len_bytes", "min_bytes", "max_bytes":
+		r.Name = strings.Replace(r.Name, "bytes", "len", 1)
+		if r.Name == "len_len" {
+			r.Name = "len"
+		}
+		return lengthCheck(r, "len("+expr+")", path, "bytes")
+	case "const":
+		return check(expr+" != "+strconv.Quote(value), path, "value must equal "+strconv.Quote(value)), nil
+	case "pattern":
+		if _, err := regexp.Compile(value); err != nil {
+			return "", fmt.Errorf("%s 不是合法的正则表达式: %w", r.Option, err)
+		}
+		return check("!hvalidate.Match("+strconv.Quote(value)+", "+expr+")", path, "value does not match regex pattern "+strconv.Quote(value)), nil
+	case "prefix":
+		v.Strings = true
+		return check("!strings.HasPrefix("+expr+", "+strconv.Quote(value)+")", path, "value does not have prefix "+strconv.Quote(value)), nil
+	case "suffix":
+		v.Strings = true
+		return check("!strings.HasSuffix("+expr+", "+strconv.Quote(value)+")", path, "value does not have suffix "+strconv.Quote(value)), nil
+	case "contains":
+		v.Strings = true
+		return check("!strings.Contains("+expr+", "+strconv.Quote(value)+")", path, "value does not contain substring "+strconv.Quote(value)), nil
+	case "not_contains":
+		v.Strings = true
+		return check("strings.Contains("+expr+", "+strconv.Quote(value)+")", path, "value contains substring "+strconv.Quote(value)), nil
+	case "in", "not_in":
+		values := make([]string, len(r.Values))
+		for i, s := range r.Values {
+			values[i] = strconv.Quote(unescape(s))
+		}
+		return inCheck(r.Name, expr, values, path), nil
+	case "email", "hostname", "ip", "ipv4", "ipv6", "uri", "uuid":
+		if r.Values[0] != "true" {
+			return "", nil
+		}
+		fn := map[string]string{"email": "IsEmail", "hostname": "IsHostname", "ip": "IsIP", "ipv4": "IsIPv4",
+			"ipv6": "IsIPv6", "uri": "IsURI", "uuid": "IsUUID"}[r.Name]
+		desc := map[string]string{"email": "email address", "hostname": "hostname", "ip": "IP address",
+			"ipv4": "IPv4 address", "ipv6": "IPv6 address", "uri": "absolute URI", "uuid": "UUID"}[r.Name]
+		return check("!hvalidate."+fn+"("+expr+")", path, "value must be a valid "+desc), nil
+	}
+	v.warn(r)
+	return "", nil
+}
+
+func lengthCheck(r fieldRule, length, path, unit string) (string, error) {
+	n, err := intValue(r)
+	if err != nil {
+		return "", err
+	}
+	switch r.Name {
+	case "len":
+		return check(length+" != "+n, path, fmt.Sprintf("value length must be %s %s", n, unit)), nil
+	case "min_len":
+		return check(length+" < "+n, path, fmt.Sprintf("value length must be at least %s %s", n, unit)), nil
+	default:
+		return check(length+" > "+n, path, fmt.Sprintf("value length must be at most %s %s", n, unit)), nil
+	}
+}
+
+func numberCheck(r fieldRule, expr, path string) (string, error) {
+	for _, s := range r.Values {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return "", fmt.Errorf("%s 的值 %s 不是数字", r.Option, s)
+		}
+	}
+	n := r.Values[0]
+	switch r.Name {
+	case "const":
+		return check(expr+" != "+n, path, "value must equal "+n), nil
+	case "gt":
+		return check(expr+" <= "+n, path, "value must be greater than "+n), nil
+	case "gte":
+		return check(expr+" < "+n, path, "value must be greater than or equal to "+n), nil
+	case "lt":
+		return check(expr+" >= "+n, path, "value must be less than "+n), nil
+	case "lte":
+		return check(expr+" > "+n, path, "value must be less than or equal to "+n), nil
+	case "in", "not_in":
+		return inCheck(r.Name, expr, r.Values, path), nil
+	}
+	return "", fmt.Errorf("不支持的校验规则 %s", r.Option)
+}
+
+func inCheck(name, expr string, values []string, path string) string {
+	list := strings.Join(values, ", ")
+	if name == "in" {
+		return check("!hvalidate.In("+expr+", "+list+")", path, "value must be in list ["+list+"]")
+	}
+	return check("hvalidate.In("+expr+", "+list+")", path, "value must not be in list ["+list+"]")
+}
+
+func check(cond, path, description string) string {
+	return "if " + cond + " {\nv.Add(" + path + ", " + strconv.Quote(description) + ")\n}"
+}
+
+func intValue(r fieldRule) (string, error) {
+	if _, err := strconv.ParseUint(r.Values[0], 10, 64); err != nil {
+		return "", fmt.Errorf("%s 的值 %s 不是非负整数", r.Option, r.Values[0])
+	}
+	return r.Values[0], nil
+}
+
+func enumNumbers(e *idl.Enum) string {
+	numbers := make([]int, 0, len(e.Values))
+	for _, ev := range e.Values {
+		numbers = append(numbers, ev.Number)
+	}
+	sort.Ints(numbers)
+	s := make([]string, len(numbers))
+	for i, n := range numbers {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ", ")
+}
+
+func (v *validation) warn(r fieldRule) {
+	v.Warnings = append(v.Warnings, r.Option)
+}
+
+// unescape 处理 proto 字符串字面量中的转义，例如正则中的 \\d
+func unescape(s string) string {
+	if u, err := strconv.Unquote(`"` + s + `"`); err == nil {
+		return u
+	}
+	return s
+}
+
+// goTypeName 与 protoc-gen-go 相同，嵌套消息 Outer.Inner 为 Outer_Inner
+func goTypeName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = goCamelCase(p)
+	}
+	return strings.Join(parts, "_")
+}
+
+// goCamelCase 与 protoc-gen-go 的字段名转换规则相同，例如 page_size 为 PageSize
+func goCamelCase(s string) string {
+	isLower := func(c byte) bool { return 'a' <= c && c <= 'z' }
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+			// 跳过小写字母前的下划线
+		case '0' <= c && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+// generateValidate 生成 proto 文件中消息的 Validate 方法，与 protoc 生成的代码放在同一个包中，没有校验规则时不生成
+func (g *ProtoGenerator) generateValidate(file *idl.File) error {
+	v, err := buildValidation(file)
+	if err != nil {
+		return err
+	}
+	for _, option := range v.Warnings {
+		fmt.Fprintf(g.Out, "⚠️  不支持的校验规则 %s，已忽略\n", option)
+	}
+	if len(v.Messages) == 0 {
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"Package":         file.GoPackageName(),
+		"FrameworkImport": g.FrameworkImport,
+		"Source":          filepath.Base(g.ProtoPath),
+		"Strings":         v.Strings,
+		"Messages":        v.Messages,
+	}
+	src, err := renderGoFile(validateTemplate, data)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(g.ProtoPath), filepath.Ext(g.ProtoPath)) + "_validate.go"
+	return g.writeFile(filepath.Join(g.OutputDir, name), src)
+}
+
+// Validate 模板
+var validateTemplate = `// Code generated by hollow-cli proto. DO NOT EDIT.
+// source: {{.Source}}
+
+package {{.Package}}
+
+import (
+{{- if .Strings}}
+	"strings"
+{{end}}
+	"{{.FrameworkImport}}/pkg/hvalidate"
+)
+{{range .Messages}}
+// Validate 按 proto 中的字段规则校验 {{.Name}}，返回带所有字段违规详情的 hecode.ErrInvalidParam
+func (m *{{.GoName}}) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var v hvalidate.Validator
+{{- range .Checks}}
+	{{.}}
+{{- end}}
+	return v.Err()
+}
+{{end}}
+`
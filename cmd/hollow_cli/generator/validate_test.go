@@ -0,0 +1,212 @@
+package generator
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/hollow/cmd/hollow_cli/generator/testdata/orderpb"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/protobuf/proto"
+)
+
+var update = flag.Bool("update", false, "update generated files in testdata")
+
+const orderProto = `syntax = "proto3";
+package order.v1;
+option go_package = "example.com/order/proto;orderpb";
+
+enum Status { STATUS_UNSPECIFIED = 0; STATUS_PAID = 2; STATUS_NEW = 1; }
+
+message Item {
+  string sku = 1 [(validate.rules).string = { min_len: 1 max_len: 32 pattern: "^[A-Z]+-\\d+$" }];
+  int32 quantity = 2 [(buf.validate.field).int32.gt = 0];
+}
+
+message CreateOrderRequest {
+  string user_id = 1 [(validate.rules).string.uuid = true];
+  repeated Item items = 2 [(validate.rules).repeated.min_items = 1];
+  repeated string tags = 3 [(validate.rules).repeated = { unique: true items: { string: { in: ["a", "b"] } } }];
+  Status status = 4 [(validate.rules).enum.defined_only = true];
+  optional string email = 5 [(hollow.rules).string.email = true];
+  Item gift = 6 [(validate.rules).message.skip = true];
+  Address address = 7 [(buf.validate.field).required = true];
+  message Address { string city = 1 [(validate.rules).string.prefix = "c"]; }
+  string note = 8 [(validate.rules).string.well_known_regex = HTTP_HEADER_NAME];
+}
+
+message CreateOrderResponse { string id = 1; }
+
+service Order {
+  rpc CreateOrder (CreateOrderRequest) returns (CreateOrderResponse);
+}
+`
+
+func TestGenerateProtoValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/order\n")
+	protoPath := filepath.Join(dir, "proto", "order.proto")
+	writeFile(t, protoPath, orderProto)
+
+	var out bytes.Buffer
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: &out}))
+	assert.Contains(t, out.String(), "不支持的校验规则 (validate.rules).string.well_known_regex")
+
+	src := readFile(t, filepath.Join(dir, "proto", "order_validate.go"))
+	for _, s := range []string{
+		"package orderpb",
+		`"strings"`,
+		"func (m *Item) Validate() error {",
+		`if hvalidate.Len(m.GetSku()) < 1 {`,
+		`v.Add("sku", "value length must be at least 1 characters")`,
+		`if !hvalidate.Match("^[A-Z]+-\\d+$", m.GetSku()) {`,
+		`if m.GetQuantity() <= 0 {`,
+		"func (m *CreateOrderRequest) Validate() error {",
+		`if !hvalidate.IsUUID(m.GetUserId()) {`,
+		`if len(m.GetItems()) < 1 {`,
+		`v.Nested(hvalidate.Index("items", i), item)`,
+		`if !hvalidate.Unique(m.GetTags()) {`,
+		`if !hvalidate.In(item, "a", "b") {`,
+		`v.Add(hvalidate.Index("tags", i), "value must be in list [\"a\", \"b\"]")`,
+		`if !hvalidate.In(int32(m.GetStatus()), 0, 1, 2) {`,
+		`if hvalidate.Has(m, "email") {`,
+		`if m.GetAddress() == nil {`,
+		`v.Nested("address", m.GetAddress())`,
+		"func (m *CreateOrderRequest_Address) Validate() error {",
+		`if !strings.HasPrefix(m.GetCity(), "c") {`,
+	} {
+		assert.Contains(t, src, s)
+	}
+	// skip 的字段不校验嵌套消息，没有规则的消息不生成 Validate
+	assert.NotContains(t, src, `v.Nested("gift"`)
+	assert.NotContains(t, src, "CreateOrderResponse")
+
+	// 没有规则时不生成校验文件
+	dir = t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/library\n")
+	protoPath = filepath.Join(dir, "proto", "library.proto")
+	writeFile(t, protoPath, libraryProto)
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard}))
+	_, err := os.Stat(filepath.Join(dir, "proto", "library_validate.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateProtoValidateRuntime(t *testing.T) {
+	// testdata/orderpb 中的 order.pb.go 由 protoc-gen-go 根据 orderProto 生成，
+	// order_validate.go 是生成的校验代码，go test -update 时重新生成
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/order\n")
+	protoPath := filepath.Join(dir, "proto", "order.proto")
+	writeFile(t, protoPath, strings.Replace(orderProto, "example.com/order/proto;orderpb", "github.com/vaynedu/hollow/cmd/hollow_cli/generator/testdata/orderpb;orderpb", 1))
+	require.NoError(t, GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard}))
+
+	src := readFile(t, filepath.Join(dir, "proto", "order_validate.go"))
+	golden := filepath.Join("testdata", "orderpb", "order_validate.go")
+	if *update {
+		require.NoError(t, os.WriteFile(golden, []byte(src), 0o644))
+	}
+	require.Equal(t, readFile(t, golden), src, "testdata 中的校验代码已过期，请执行 go test -update")
+
+	violations := func(m interface{ Validate() error }) []hecode.FieldViolation {
+		err := m.Validate()
+		if err == nil {
+			return nil
+		}
+		require.True(t, hecode.IsError(err, hecode.ErrInvalidParam))
+		var result []hecode.FieldViolation
+		for _, d := range hecode.Details(err) {
+			result = append(result, d.(hecode.FieldViolation))
+		}
+		return result
+	}
+
+	valid := &orderpb.CreateOrderRequest{
+		UserId:  "0f8fad5b-d9cb-469f-a165-70867728950e",
+		Items:   []*orderpb.Item{{Sku: "SKU-1", Quantity: 1}},
+		Tags:    []string{"a", "b"},
+		Status:  orderpb.Status_STATUS_PAID,
+		Gift:    &orderpb.Item{},
+		Address: &orderpb.CreateOrderRequest_Address{City: "chengdu"},
+	}
+	assert.Empty(t, violations(valid))
+
+	// 所有违规的字段都列出，嵌套字段带路径前缀，skip 的 gift 不校验
+	invalid := proto.Clone(valid).(*orderpb.CreateOrderRequest)
+	invalid.UserId = "user-1"
+	invalid.Items = []*orderpb.Item{{Sku: "SKU-1", Quantity: 1}, {Sku: "sku", Quantity: 0}}
+	invalid.Tags = []string{"a", "c", "a"}
+	invalid.Status = orderpb.Status(5)
+	invalid.Email = proto.String("not-an-email")
+	invalid.Address.City = "beijing"
+	assert.Equal(t, []hecode.FieldViolation{
+		{Field: "user_id", Description: "value must be a valid UUID"},
+		{Field: "items[1].sku", Description: `value does not match regex pattern "^[A-Z]+-\\d+$"`},
+		{Field: "items[1].quantity", Description: "value must be greater than 0"},
+		{Field: "tags", Description: "repeated value must contain unique items"},
+		{Field: "tags[1]", Description: `value must be in list ["a", "b"]`},
+		{Field: "status", Description: "value must be one of the defined enum values"},
+		{Field: "email", Description: "value must be a valid email address"},
+		{Field: "address.city", Description: `value does not have prefix "c"`},
+	}, violations(invalid))
+
+	// optional 字段只在设置时校验，required 的消息字段必须设置
+	invalid = proto.Clone(valid).(*orderpb.CreateOrderRequest)
+	invalid.Items = nil
+	invalid.Address = nil
+	assert.Equal(t, []hecode.FieldViolation{
+		{Field: "items", Description: "value must contain at least 1 item(s)"},
+		{Field: "address", Description: "value is required"},
+	}, violations(invalid))
+}
+
+func TestGenerateProtoValidateInvalidRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		err   string
+	}{
+		{"类型不匹配", `int32 age = 1 [(validate.rules).string.min_len = 1];`, "与字段类型 int32 不匹配"},
+		{"非法正则", `string name = 1 [(validate.rules).string.pattern = "("];`, "不是合法的正则表达式"},
+		{"长度不是整数", `string name = 1 [(validate.rules).string.max_len = -1];`, "不是非负整数"},
+		{"repeated 使用标量规则", `repeated string names = 1 [(validate.rules).string.min_len = 1];`, "repeated.items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/demo\n")
+			protoPath := filepath.Join(dir, "proto", "demo.proto")
+			writeFile(t, protoPath, strings.Join([]string{
+				`syntax = "proto3";`,
+				`message Req { ` + tt.field + ` }`,
+				`service Demo { rpc Get (Req) returns (Req); }`,
+			}, "\n"))
+
+			err := GenerateProto(protoPath, ProtoGenOptions{Out: io.Discard})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			// 规则有误时不生成任何文件
+			_, statErr := os.Stat(filepath.Join(dir, "handler"))
+			assert.True(t, os.IsNotExist(statErr))
+		})
+	}
+}
+
+func TestGoCamelCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"user_id":    "UserId",
+		"page_size2": "PageSize2",
+		"_hidden":    "XHidden",
+		"a_b_c":      "ABC",
+		"HTTPPort":   "HTTPPort",
+		"v_1":        "V_1",
+	} {
+		assert.Equal(t, want, goCamelCase(in), in)
+	}
+	assert.Equal(t, "Order_Item", goTypeName("Order.Item"))
+}
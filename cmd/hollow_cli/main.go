@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/vaynedu/hollow/cmd/hollow_cli/generator"
@@ -34,35 +35,110 @@ func main() {
 
 	// proto 命令 - 从 proto 文件生成代码
 	var protoImportPaths []string
-	var force bool
+	var protoGenOpts generator.ProtoGenOptions
 	var protoCmd = &cobra.Command{
 		Use:   "proto [proto文件路径]",
 		Short: "从 Protobuf 文件生成代码",
-		Long:  `解析 Protobuf 文件并生成对应的 Go 代码、Handler 和 Service 模板。`,
+		Long:  `解析 Protobuf 文件并生成对应的 Go 代码、Handler 和 Service 模板。已存在的 Handler 和 Service 实现只追加 proto 中新增的方法，保留已有的业务代码。`,
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			protoPath := args[0]
 
-			// 1. 生成 Go 代码 (protoc)
-			if err := generator.GenerateGoFromProto(protoPath, protoImportPaths); err != nil {
-				log.Printf("生成 Go 代码失败: %v", err)
-				log.Println("跳过 protoc 代码生成，继续生成 Handler 和 Service...")
+			// 1. 生成 Go 代码 (protoc)，dry-run 时不执行
+			if !protoGenOpts.DryRun {
+				if err := generator.GenerateGoFromProto(protoPath, protoImportPaths); err != nil {
+					log.Printf("生成 Go 代码失败: %v", err)
+					log.Println("跳过 protoc 代码生成，继续生成 Handler 和 Service...")
+				}
 			}
 
 			// 2. 生成 Handler 和 Service 模板
-			if err := generator.GenerateProto(protoPath, force); err != nil {
+			if err := generator.GenerateProto(protoPath, protoGenOpts); err != nil {
 				log.Fatalf("生成 Handler 和 Service 失败: %v", err)
 			}
 
-			log.Println("✅ 代码生成成功!")
+			if !protoGenOpts.DryRun {
+				log.Println("✅ 代码生成成功!")
+			}
 		},
 	}
 	protoCmd.Flags().StringSliceVarP(&protoImportPaths, "proto_path", "I", []string{}, "Protobuf 文件引用路径")
-	protoCmd.Flags().BoolVarP(&force, "force", "f", false, "强制覆盖已存在的文件")
+	protoCmd.Flags().BoolVarP(&protoGenOpts.Force, "force", "f", false, "强制覆盖已存在的 Handler 和 Service 实现，默认只追加新增的方法")
+	protoCmd.Flags().BoolVar(&protoGenOpts.DryRun, "dry-run", false, "只打印将要修改的差异，不写入文件")
+	protoCmd.Flags().BoolVar(&protoGenOpts.Client, "client", false, "生成调用服务的类型化 HTTP 客户端（client 目录）")
+
+	// openapi 命令 - 从 proto 文件生成 OpenAPI 文档
+	var openAPIOpts generator.OpenAPIGenOptions
+	var openAPICmd = &cobra.Command{
+		Use:   "openapi [proto文件路径...]",
+		Short: "从 Protobuf 文件生成 OpenAPI 3.1 文档",
+		Long:  `根据 proto 中的服务、google.api.http 注解、消息和注释生成 OpenAPI 3.1 文档，响应为 hollow 的标准格式，错误码来自 hecode 和 --ecode 指定的定义，不需要 protoc 插件。多个 proto 文件合并为一个文档。`,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := generator.GenerateOpenAPI(args, openAPIOpts); err != nil {
+				log.Fatalf("生成 OpenAPI 文档失败: %v", err)
+			}
+			log.Println("✅ OpenAPI 文档生成成功!")
+		},
+	}
+	openAPICmd.Flags().StringVarP(&openAPIOpts.Output, "output", "o", "", "输出文件，默认为 proto 目录上级的 docs/openapi.json")
+	openAPICmd.Flags().StringVar(&openAPIOpts.Title, "title", "", "文档标题，默认为 proto 包名")
+	openAPICmd.Flags().StringVar(&openAPIOpts.Version, "version", "", "接口版本，默认为 1.0.0")
+	openAPICmd.Flags().StringSliceVar(&openAPIOpts.Ecodes, "ecode", nil, "业务错误码定义文件（YAML 或 proto），可以指定多个")
+
+	// ecode 命令 - 错误码管理
+	var ecodeCmd = &cobra.Command{
+		Use:   "ecode",
+		Short: "错误码管理工具",
+	}
+	var ecodeCheckCmd = &cobra.Command{
+		Use:   "check [目录]",
+		Short: "检查错误码是否重复、是否超出命名空间号段",
+		Long:  `扫描目录下的 Go 源码中 hecode.New 等调用，报告重复的错误码和超出命名空间号段的错误码，建议在发布前执行。`,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			report, err := generator.CheckEcode(dir)
+			if err != nil {
+				log.Fatalf("检查错误码失败: %v", err)
+			}
+			report.Print(os.Stdout)
+			if report.HasIssues() {
+				os.Exit(1)
+			}
+		},
+	}
+	var ecodeGenOpts generator.EcodeGenOptions
+	var ecodeGenCmd = &cobra.Command{
+		Use:   "gen [定义文件路径]",
+		Short: "从 YAML 或 proto 枚举定义生成错误码",
+		Long:  `根据 YAML 或 proto 枚举中定义的错误码、消息和 HTTP 状态码，生成 Go 错误变量和 Is 函数、Markdown 文档以及供前端和移动端使用的 TypeScript/JSON 错误码目录。`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := generator.GenerateEcode(args[0], ecodeGenOpts); err != nil {
+				log.Fatalf("生成错误码失败: %v", err)
+			}
+			log.Println("✅ 错误码生成成功!")
+		},
+	}
+	ecodeGenCmd.Flags().StringVarP(&ecodeGenOpts.OutputDir, "output", "o", "", "输出目录，默认与定义文件同目录")
+	ecodeGenCmd.Flags().StringVarP(&ecodeGenOpts.Package, "package", "p", "", "Go 包名，默认使用定义中的包名")
+	ecodeGenCmd.Flags().BoolVar(&ecodeGenOpts.Go, "go", true, "生成 Go 代码")
+	ecodeGenCmd.Flags().BoolVar(&ecodeGenOpts.Markdown, "md", true, "生成 Markdown 文档")
+	ecodeGenCmd.Flags().BoolVar(&ecodeGenOpts.TS, "ts", true, "生成 TypeScript 错误码目录")
+	ecodeGenCmd.Flags().BoolVar(&ecodeGenOpts.JSON, "json", true, "生成 JSON 错误码目录")
+
+	ecodeCmd.AddCommand(ecodeCheckCmd)
+	ecodeCmd.AddCommand(ecodeGenCmd)
 
 	// 添加子命令
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(protoCmd)
+	rootCmd.AddCommand(openAPICmd)
+	rootCmd.AddCommand(ecodeCmd)
 
 	// 执行
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,77 @@
+// Code generated by hollow-cli proto. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+
+	"github.com/vaynedu/hollow/example/proto"
+	"github.com/vaynedu/hollow/pkg/hclient"
+)
+
+// UserServiceClient 用户服务
+//
+// 每个 rpc 对应一个方法，按 google.api.http 注解发送请求，服务端返回的错误码还原为 hecode 错误
+type UserServiceClient struct {
+	cc *hclient.Client
+}
+
+// NewUserServiceClient 创建客户端，cc 由 hclient.New 创建，可以在多个服务的客户端之间共享
+func NewUserServiceClient(cc *hclient.Client) *UserServiceClient {
+	return &UserServiceClient{cc: cc}
+}
+
+// CreateUser 创建用户
+//
+// POST /v1/users
+func (c *UserServiceClient) CreateUser(ctx context.Context, req *proto.CreateUserRequest, opts ...hclient.CallOption) (*proto.CreateUserResponse, error) {
+	resp := new(proto.CreateUserResponse)
+	if err := c.cc.Invoke(ctx, hclient.Rule{Method: "POST", Path: "/v1/users", Body: "*"}, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetUser 获取用户详情
+//
+// GET /v1/users/{id}，失败时自动重试
+func (c *UserServiceClient) GetUser(ctx context.Context, req *proto.GetUserRequest, opts ...hclient.CallOption) (*proto.GetUserResponse, error) {
+	resp := new(proto.GetUserResponse)
+	if err := c.cc.Invoke(ctx, hclient.Rule{Method: "GET", Path: "/v1/users/{id}"}, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// QueryUsers 查询用户列表
+//
+// GET /v1/users，失败时自动重试
+func (c *UserServiceClient) QueryUsers(ctx context.Context, req *proto.QueryUsersRequest, opts ...hclient.CallOption) (*proto.QueryUsersResponse, error) {
+	resp := new(proto.QueryUsersResponse)
+	if err := c.cc.Invoke(ctx, hclient.Rule{Method: "GET", Path: "/v1/users"}, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateUser 更新用户
+//
+// PUT /v1/users/{id}，失败时自动重试
+func (c *UserServiceClient) UpdateUser(ctx context.Context, req *proto.UpdateUserRequest, opts ...hclient.CallOption) (*proto.UpdateUserResponse, error) {
+	resp := new(proto.UpdateUserResponse)
+	if err := c.cc.Invoke(ctx, hclient.Rule{Method: "PUT", Path: "/v1/users/{id}", Body: "*"}, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteUser 删除用户
+//
+// DELETE /v1/users/{id}，失败时自动重试
+func (c *UserServiceClient) DeleteUser(ctx context.Context, req *proto.DeleteUserRequest, opts ...hclient.CallOption) (*proto.DeleteUserResponse, error) {
+	resp := new(proto.DeleteUserResponse)
+	if err := c.cc.Invoke(ctx, hclient.Rule{Method: "DELETE", Path: "/v1/users/{id}"}, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
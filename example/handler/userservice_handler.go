@@ -4,121 +4,115 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/vaynedu/hollow/example/proto"
 	"github.com/vaynedu/hollow/example/service"
+	"github.com/vaynedu/hollow/pkg/hbind"
 )
 
+// UserServiceHandler UserService 的 HTTP 处理器，负责绑定请求和返回响应，业务逻辑由 service.UserServiceServer 实现
+type UserServiceHandler struct {
+	svc service.UserServiceServer
+}
+
+// NewUserServiceHandler 创建处理器，svc 可以是业务实现，也可以是测试中的模拟实现
+func NewUserServiceHandler(svc service.UserServiceServer) *UserServiceHandler {
+	return &UserServiceHandler{svc: svc}
+}
 
-// CreateUserHandler CreateUser 接口处理器
-// TODO: 实现业务逻辑
-func CreateUserHandler(c *gin.Context) {
+// CreateUser CreateUser 接口处理器
+func (h *UserServiceHandler) CreateUser(c *gin.Context) {
 	var req proto.CreateUserRequest
-	
-	// POST/PUT/DELETE 请求：使用 ShouldBindJSON 绑定请求体
-	if err := c.ShouldBindJSON(&req); err != nil {
+	// 按 google.api.http 注解绑定路径参数、查询参数和请求体，规则由路由中的 hbind.Rule 指定
+	if err := hbind.Bind(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
-	
 
 	// 调用 service 层
-	resp, err := service.CreateUser(c.Request.Context(), &req)
+	resp, err := h.svc.CreateUser(c.Request.Context(), &req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	// 设置数据，由responseMiddleware统一处理响应
-	c.Set("data", resp)
+	// 设置数据，由responseMiddleware统一处理响应，注解指定 response_body 时只返回该字段
+	c.Set("data", hbind.ResponseBody(c, resp))
 }
 
-// GetUserHandler GetUser 接口处理器
-// TODO: 实现业务逻辑
-func GetUserHandler(c *gin.Context) {
+// GetUser GetUser 接口处理器
+func (h *UserServiceHandler) GetUser(c *gin.Context) {
 	var req proto.GetUserRequest
-	
-	// GET 请求：使用 ShouldBindQuery 绑定查询参数
-	if err := c.ShouldBindQuery(&req); err != nil {
+	// 按 google.api.http 注解绑定路径参数、查询参数和请求体，规则由路由中的 hbind.Rule 指定
+	if err := hbind.Bind(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
-	
 
 	// 调用 service 层
-	resp, err := service.GetUser(c.Request.Context(), &req)
+	resp, err := h.svc.GetUser(c.Request.Context(), &req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	// 设置数据，由responseMiddleware统一处理响应
-	c.Set("data", resp)
+	// 设置数据，由responseMiddleware统一处理响应，注解指定 response_body 时只返回该字段
+	c.Set("data", hbind.ResponseBody(c, resp))
 }
 
-// QueryUsersHandler QueryUsers 接口处理器
-// TODO: 实现业务逻辑
-func QueryUsersHandler(c *gin.Context) {
+// QueryUsers QueryUsers 接口处理器
+func (h *UserServiceHandler) QueryUsers(c *gin.Context) {
 	var req proto.QueryUsersRequest
-	
-	// GET 请求：使用 ShouldBindQuery 绑定查询参数
-	if err := c.ShouldBindQuery(&req); err != nil {
+	// 按 google.api.http 注解绑定路径参数、查询参数和请求体，规则由路由中的 hbind.Rule 指定
+	if err := hbind.Bind(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
-	
 
 	// 调用 service 层
-	resp, err := service.QueryUsers(c.Request.Context(), &req)
+	resp, err := h.svc.QueryUsers(c.Request.Context(), &req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	// 设置数据，由responseMiddleware统一处理响应
-	c.Set("data", resp)
+	// 设置数据，由responseMiddleware统一处理响应，注解指定 response_body 时只返回该字段
+	c.Set("data", hbind.ResponseBody(c, resp))
 }
 
-// UpdateUserHandler UpdateUser 接口处理器
-// TODO: 实现业务逻辑
-func UpdateUserHandler(c *gin.Context) {
+// UpdateUser UpdateUser 接口处理器
+func (h *UserServiceHandler) UpdateUser(c *gin.Context) {
 	var req proto.UpdateUserRequest
-	
-	// POST/PUT/DELETE 请求：使用 ShouldBindJSON 绑定请求体
-	if err := c.ShouldBindJSON(&req); err != nil {
+	// 按 google.api.http 注解绑定路径参数、查询参数和请求体，规则由路由中的 hbind.Rule 指定
+	if err := hbind.Bind(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
-	
 
 	// 调用 service 层
-	resp, err := service.UpdateUser(c.Request.Context(), &req)
+	resp, err := h.svc.UpdateUser(c.Request.Context(), &req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	// 设置数据，由responseMiddleware统一处理响应
-	c.Set("data", resp)
+	// 设置数据，由responseMiddleware统一处理响应，注解指定 response_body 时只返回该字段
+	c.Set("data", hbind.ResponseBody(c, resp))
 }
 
-// DeleteUserHandler DeleteUser 接口处理器
-// TODO: 实现业务逻辑
-func DeleteUserHandler(c *gin.Context) {
+// DeleteUser DeleteUser 接口处理器
+func (h *UserServiceHandler) DeleteUser(c *gin.Context) {
 	var req proto.DeleteUserRequest
-	
-	// POST/PUT/DELETE 请求：使用 ShouldBindJSON 绑定请求体
-	if err := c.ShouldBindJSON(&req); err != nil {
+	// 按 google.api.http 注解绑定路径参数、查询参数和请求体，规则由路由中的 hbind.Rule 指定
+	if err := hbind.Bind(c, &req); err != nil {
 		c.Error(err)
 		return
 	}
-	
 
 	// 调用 service 层
-	resp, err := service.DeleteUser(c.Request.Context(), &req)
+	resp, err := h.svc.DeleteUser(c.Request.Context(), &req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	// 设置数据，由responseMiddleware统一处理响应
-	c.Set("data", resp)
+	// 设置数据，由responseMiddleware统一处理响应，注解指定 response_body 时只返回该字段
+	c.Set("data", hbind.ResponseBody(c, resp))
 }
-
@@ -2,11 +2,18 @@ package router
 
 import (
 	"github.com/vaynedu/hollow"
+	"github.com/vaynedu/hollow/example/service"
+	"github.com/vaynedu/hollow/pkg/hdi"
 )
 
 // RegisterRoutes 注册所有路由
 // 在 main.go 中调用此函数注册路由
 func RegisterRoutes(app *hollow.App) {
+	// 通过依赖注入容器提供 UserService 的业务实现，实现的依赖可以在构造函数中 Resolve
+	hdi.MustProvide(app.Container, func(r hdi.Resolver) (service.UserServiceServer, error) {
+		return service.NewUserServiceService(), nil
+	})
+
 	// 注册 UserService 服务路由
-	RegisterUserServiceRoutes(app.Engine)
+	RegisterUserServiceRoutes(app.Engine, hdi.MustResolve[service.UserServiceServer](app.Container))
 }
@@ -1,26 +1,25 @@
+// Code generated by hollow-cli proto. DO NOT EDIT.
+
 package router
 
 import (
-	"github.com/vaynedu/hollow/example/handler"
 	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/hollow/example/handler"
+	"github.com/vaynedu/hollow/example/service"
+	"github.com/vaynedu/hollow/pkg/hbind"
 )
 
-// RegisterUserServiceRoutes 注册 UserService 服务路由
-func RegisterUserServiceRoutes(r *gin.Engine) {
-
+// RegisterUserServiceRoutes 注册 UserService 服务路由，r 可以是 gin.Engine 或路由分组，svc 为业务实现
+func RegisterUserServiceRoutes(r gin.IRoutes, svc service.UserServiceServer) {
+	h := handler.NewUserServiceHandler(svc)
 	// CreateUser - POST /v1/users
-	r.POST("/v1/users", handler.CreateUserHandler)
-
+	r.POST("/v1/users", hbind.Rule("/v1/users", "*", ""), h.CreateUser)
 	// GetUser - GET /v1/users/{id}
-	r.GET("/v1/users/{id}", handler.GetUserHandler)
-
+	r.GET("/v1/users/:id", hbind.Rule("/v1/users/{id}", "", ""), h.GetUser)
 	// QueryUsers - GET /v1/users
-	r.GET("/v1/users", handler.QueryUsersHandler)
-
+	r.GET("/v1/users", hbind.Rule("/v1/users", "", ""), h.QueryUsers)
 	// UpdateUser - PUT /v1/users/{id}
-	r.PUT("/v1/users/{id}", handler.UpdateUserHandler)
-
+	r.PUT("/v1/users/:id", hbind.Rule("/v1/users/{id}", "*", ""), h.UpdateUser)
 	// DeleteUser - DELETE /v1/users/{id}
-	r.DELETE("/v1/users/{id}", handler.DeleteUserHandler)
-
+	r.DELETE("/v1/users/:id", hbind.Rule("/v1/users/{id}", "", ""), h.DeleteUser)
 }
@@ -9,20 +9,14 @@ import (
 // UserServiceService UserService 服务实现
 type UserServiceService struct{}
 
-// NewUserServiceService 创建服务实例
+// 编译期检查 UserServiceService 实现了 UserServiceServer，proto 新增方法后这里会编译失败
+var _ UserServiceServer = (*UserServiceService)(nil)
+
+// NewUserServiceService 创建服务实例，依赖通过参数注入
 func NewUserServiceService() *UserServiceService {
 	return &UserServiceService{}
 }
 
-// 全局服务实例
-var userServiceService = NewUserServiceService()
-
-
-// CreateUser CreateUser business logic (全局函数，供Handler调用)
-func CreateUser(ctx context.Context, req *proto.CreateUserRequest) (*proto.CreateUserResponse, error) {
-	return userServiceService.CreateUser(ctx, req)
-}
-
 // CreateUser CreateUser business logic
 // TODO: Implement specific business logic
 func (s *UserServiceService) CreateUser(ctx context.Context, req *proto.CreateUserRequest) (*proto.CreateUserResponse, error) {
@@ -31,11 +25,6 @@ func (s *UserServiceService) CreateUser(ctx context.Context, req *proto.CreateUs
 	return &proto.CreateUserResponse{}, nil
 }
 
-// GetUser GetUser business logic (全局函数，供Handler调用)
-func GetUser(ctx context.Context, req *proto.GetUserRequest) (*proto.GetUserResponse, error) {
-	return userServiceService.GetUser(ctx, req)
-}
-
 // GetUser GetUser business logic
 // TODO: Implement specific business logic
 func (s *UserServiceService) GetUser(ctx context.Context, req *proto.GetUserRequest) (*proto.GetUserResponse, error) {
@@ -44,11 +33,6 @@ func (s *UserServiceService) GetUser(ctx context.Context, req *proto.GetUserRequ
 	return &proto.GetUserResponse{}, nil
 }
 
-// QueryUsers QueryUsers business logic (全局函数，供Handler调用)
-func QueryUsers(ctx context.Context, req *proto.QueryUsersRequest) (*proto.QueryUsersResponse, error) {
-	return userServiceService.QueryUsers(ctx, req)
-}
-
 // QueryUsers QueryUsers business logic
 // TODO: Implement specific business logic
 func (s *UserServiceService) QueryUsers(ctx context.Context, req *proto.QueryUsersRequest) (*proto.QueryUsersResponse, error) {
@@ -57,11 +41,6 @@ func (s *UserServiceService) QueryUsers(ctx context.Context, req *proto.QueryUse
 	return &proto.QueryUsersResponse{}, nil
 }
 
-// UpdateUser UpdateUser business logic (全局函数，供Handler调用)
-func UpdateUser(ctx context.Context, req *proto.UpdateUserRequest) (*proto.UpdateUserResponse, error) {
-	return userServiceService.UpdateUser(ctx, req)
-}
-
 // UpdateUser UpdateUser business logic
 // TODO: Implement specific business logic
 func (s *UserServiceService) UpdateUser(ctx context.Context, req *proto.UpdateUserRequest) (*proto.UpdateUserResponse, error) {
@@ -70,11 +49,6 @@ func (s *UserServiceService) UpdateUser(ctx context.Context, req *proto.UpdateUs
 	return &proto.UpdateUserResponse{}, nil
 }
 
-// DeleteUser DeleteUser business logic (全局函数，供Handler调用)
-func DeleteUser(ctx context.Context, req *proto.DeleteUserRequest) (*proto.DeleteUserResponse, error) {
-	return userServiceService.DeleteUser(ctx, req)
-}
-
 // DeleteUser DeleteUser business logic
 // TODO: Implement specific business logic
 func (s *UserServiceService) DeleteUser(ctx context.Context, req *proto.DeleteUserRequest) (*proto.DeleteUserResponse, error) {
@@ -82,4 +56,3 @@ func (s *UserServiceService) DeleteUser(ctx context.Context, req *proto.DeleteUs
 	// 默认返回空响应，业务同学根据实际需求修改
 	return &proto.DeleteUserResponse{}, nil
 }
-
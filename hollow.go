@@ -2,6 +2,8 @@ package hollow
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"slices"
@@ -9,8 +11,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/vaynedu/hollow/internal/config"
+	hgrpc "github.com/vaynedu/hollow/internal/grpc"
 	"github.com/vaynedu/hollow/internal/logger"
 	"github.com/vaynedu/hollow/internal/middleware"
+	"github.com/vaynedu/hollow/internal/server"
+	"github.com/vaynedu/hollow/pkg/hcond"
+	"github.com/vaynedu/hollow/pkg/hdi"
+	"github.com/vaynedu/hollow/pkg/hdocs"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"github.com/vaynedu/hollow/pkg/htime"
 	"go.uber.org/zap"
 )
 
@@ -22,13 +31,21 @@ type App struct {
 	Logger      *zap.Logger             // 日志实例
 	Engine      *gin.Engine             // gin引擎实例
 	Middlewares []middleware.Middleware // 中间件
+	HTTPServer  *server.HTTPServer      // HTTP 服务实例，Start 时创建
+	GRPCServer  *hgrpc.Server           // gRPC 服务实例，配置 server.grpc.enable 时创建
+	Container   *hdi.Container          // 依赖注入容器
+	Clock       htime.Clock             // 时钟，业务通过容器解析 htime.Clock 获取
 }
 
 type AppOption struct {
 	ConfigPath        string                  // 配置文件路径
 	ConfigName        string                  // 配置文件名
+	ConfigValues      map[string]interface{}  // 内存中的配置，不为空时不再读取配置文件
 	AddMiddlewares    []middleware.Middleware // 增加中间件
 	RemoveMiddlewares []middleware.Middleware // 移除中间件
+	Logger            *zap.Logger             // 日志实例，为空时根据配置创建
+	Clock             htime.Clock             // 时钟，为空时使用系统时钟
+	OpenAPISpec       []byte                  // OpenAPI 文档，可以用 go:embed 嵌入，为空时读取 server.docs.spec 文件
 }
 
 func NewApp(opts AppOption) (*App, error) {
@@ -50,22 +67,61 @@ func NewApp(opts AppOption) (*App, error) {
 	}
 
 	// 初始化配置
-	cfg, err := config.NewConfig(configPath, configName)
+	var cfg *config.Config
+	var err error
+	if opts.ConfigValues != nil {
+		cfg, err = config.NewConfigFromMap(opts.ConfigValues)
+	} else {
+		cfg, err = config.NewConfig(configPath, configName)
+	}
 	if err != nil {
 		return nil, err
 	}
 	app.Config = cfg
+	// db.dialect 为 hcond 生成 SQL 使用的方言，只注册到本 App 的容器中，不修改 hcond 的默认方言
+	var dialect hcond.Dialect
+	if cfg.Db.Dialect != "" {
+		if dialect, err = hcond.DialectOf(cfg.Db.Dialect); err != nil {
+			return nil, err
+		}
+	}
 
-	// 初始化日志
-	log, err := logger.InitLogger(cfg)
-	if err != nil {
+	// 初始化日志，优先使用调用方传入的实例
+	app.Logger = opts.Logger
+	if app.Logger == nil {
+		log, err := logger.InitLogger(cfg)
+		if err != nil {
+			return nil, err
+		}
+		app.Logger = log
+	}
+
+	// 初始化依赖注入容器，框架内置的依赖可以直接被业务的构造函数解析
+	app.Container = hdi.New()
+	if err := hdi.Supply(app.Container, app.Config); err != nil {
+		return nil, err
+	}
+	if err := hdi.Supply(app.Container, app.Logger); err != nil {
+		return nil, err
+	}
+	if dialect != nil {
+		if err := hdi.Supply(app.Container, dialect); err != nil {
+			return nil, err
+		}
+	}
+	app.Clock = opts.Clock
+	if app.Clock == nil {
+		app.Clock = htime.NewSystemClock()
+	}
+	if err := hdi.Supply(app.Container, app.Clock); err != nil {
 		return nil, err
 	}
-	app.Logger = log
 
 	// 导入默认的中间件
 	defaultMiddlewares := middleware.RegisterDefaultMiddlewares(app.Logger)
 	app.AddMiddleware(defaultMiddlewares...)
+	// 每个请求一个依赖作用域
+	app.AddMiddleware(middleware.NewContainerMiddleware(app.Container, app.Logger))
 	// 依赖注入，让用户可以自定义中间件
 	if len(opts.AddMiddlewares) > 0 {
 		app.AddMiddleware(opts.AddMiddlewares...)
@@ -76,18 +132,62 @@ func NewApp(opts AppOption) (*App, error) {
 	}
 	app.UseMiddleware(app.Middlewares...)
 
+	// 配置 server.docs.enable 时提供 OpenAPI 文档和文档页面
+	if cfg.Server.Docs.Enable {
+		docs := cfg.Server.Docs.WithDefaults()
+		spec := opts.OpenAPISpec
+		if spec == nil {
+			if spec, err = os.ReadFile(docs.Spec); err != nil {
+				return nil, fmt.Errorf("读取 OpenAPI 文档失败: %w", err)
+			}
+		}
+		if err := hdocs.Register(app.Engine, docs.Path, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	// 初始化 gRPC 服务，业务在 Start 前通过 app.GRPCServer 注册服务
+	if cfg.Server.GRPC.Enable {
+		app.GRPCServer = hgrpc.NewServer(cfg.Server.GRPC.WithDefaults(), app.Logger)
+	}
+
+	// 调用栈记录是进程级的设置，只在 App 的 context 取消（Shutdown）之前开启
+	if cfg.Ecode.Stack {
+		hecode.EnableStackUntil(app.Ctx)
+	}
+
 	return app, nil
 }
 
 // Start 启动服务
 func (app *App) Start() {
+	// 创建所有需要提前初始化的依赖，尽早暴露配置错误
+	if err := app.Container.Build(); err != nil {
+		app.Logger.Fatal("failed to build container", zap.Error(err))
+	}
+
+	// server.http.addr 优先，兼容旧的顶层 host 配置
+	httpCfg := app.Config.Server.HTTP.WithDefaults(app.Config.GetString("host"))
+	var handler http.Handler = app.Engine
+	if app.GRPCServer != nil && app.GRPCServer.Config().Multiplexed() {
+		// gRPC 与 HTTP 复用同一端口
+		handler = app.GRPCServer.MuxHandler(app.Engine)
+	}
+	app.HTTPServer = server.NewHTTPServer(httpCfg, handler, app.Logger)
+	if app.GRPCServer != nil && app.GRPCServer.Config().Multiplexed() {
+		hgrpc.EnableH2C(app.HTTPServer.Server())
+	}
+	app.startGRPC()
+
 	go func() {
-		app.Logger.Info("starting hollow server")
-		addr := app.Config.GetString("host")
-		if addr == "" {
-			addr = ":8181"
-		}
-		if err := app.Engine.Run(addr); err != nil {
+		app.Logger.Info("starting hollow server",
+			zap.String("addr", httpCfg.Addr),
+			zap.Duration("read_timeout", httpCfg.ReadTimeout),
+			zap.Duration("write_timeout", httpCfg.WriteTimeout),
+			zap.Duration("idle_timeout", httpCfg.IdleTimeout),
+			zap.Int("max_conns", httpCfg.MaxConns),
+		)
+		if err := app.HTTPServer.ListenAndServe(); err != nil {
 			app.Logger.Fatal("failed to start server", zap.Error(err))
 		}
 	}()
@@ -98,7 +198,37 @@ func (app *App) End() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	app.Shutdown()
+}
+
+// startGRPC 在独立端口上启动 gRPC 服务，复用端口时由 HTTP 服务处理
+func (app *App) startGRPC() {
+	if app.GRPCServer == nil || app.GRPCServer.Config().Multiplexed() {
+		return
+	}
+	go func() {
+		app.Logger.Info("starting hollow grpc server", zap.String("addr", app.GRPCServer.Config().Addr))
+		if err := app.GRPCServer.ListenAndServe(); err != nil {
+			app.Logger.Fatal("failed to start grpc server", zap.Error(err))
+		}
+	}()
+}
+
+// Shutdown 优雅关闭服务，等待处理中的请求完成
+func (app *App) Shutdown() {
 	app.Logger.Info("stopping hollow server")
+	if app.GRPCServer != nil {
+		app.GRPCServer.Shutdown(context.Background())
+	}
+	if app.HTTPServer != nil {
+		if err := app.HTTPServer.Shutdown(context.Background()); err != nil {
+			app.Logger.Error("failed to shutdown http server", zap.Error(err))
+		}
+	}
+	// 服务停止后按创建顺序的逆序释放依赖
+	if err := app.Container.Close(context.Background()); err != nil {
+		app.Logger.Error("failed to close container", zap.Error(err))
+	}
 	app.Cancel()
 	app.Logger.Sync() // 确保日志正确刷新
 }
@@ -0,0 +1,107 @@
+package hollow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/hollow/internal/logger"
+	"github.com/vaynedu/hollow/pkg/hcond"
+	"github.com/vaynedu/hollow/pkg/hdi"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"go.uber.org/zap"
+)
+
+func TestNewAppWithLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	values := map[string]interface{}{"host": "127.0.0.1:0"}
+
+	log1, logs1 := logger.NewObserved(zap.DebugLevel)
+	log2, logs2 := logger.NewObserved(zap.DebugLevel)
+	app1, err := NewApp(AppOption{ConfigValues: values, Logger: log1})
+	assert.NoError(t, err)
+	app2, err := NewApp(AppOption{ConfigValues: values, Logger: log2})
+	assert.NoError(t, err)
+
+	assert.Same(t, log1, app1.Logger)
+	assert.Same(t, log2, app2.Logger)
+
+	app1.Engine.GET("/panic", func(c *gin.Context) {
+		panic("app1 panic")
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	app1.Engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// 默认中间件使用各自 App 的日志实例，互不干扰
+	assert.Equal(t, 1, logs1.FilterMessage("panic recovered").Len())
+	assert.Equal(t, 0, logs2.Len())
+}
+
+func TestNewAppDialect(t *testing.T) {
+	dialect := func(name string) map[string]interface{} {
+		return map[string]interface{}{"db": map[string]interface{}{"dialect": name}}
+	}
+	app1, err := NewApp(AppOption{ConfigValues: dialect("postgres")})
+	assert.NoError(t, err)
+	app2, err := NewApp(AppOption{ConfigValues: dialect("sqlite")})
+	assert.NoError(t, err)
+
+	// 每个 App 的容器中是各自的方言，不修改 hcond 的默认方言
+	d, err := hdi.Resolve[hcond.Dialect](app1.Container)
+	assert.NoError(t, err)
+	assert.Equal(t, hcond.Postgres, d)
+	d, err = hdi.Resolve[hcond.Dialect](app2.Container)
+	assert.NoError(t, err)
+	assert.Equal(t, hcond.SQLite, d)
+	assert.Nil(t, hcond.DefaultDialect())
+
+	_, err = NewApp(AppOption{ConfigValues: map[string]interface{}{"db": map[string]interface{}{"dialect": "oracle"}}})
+	assert.Error(t, err)
+}
+
+func TestNewAppStack(t *testing.T) {
+	if hecode.StackEnabled() {
+		t.Skip("built with hecode_stack")
+	}
+	stack := map[string]interface{}{"ecode": map[string]interface{}{"stack": true}}
+	app1, err := NewApp(AppOption{ConfigValues: stack})
+	assert.NoError(t, err)
+	app2, err := NewApp(AppOption{ConfigValues: map[string]interface{}{}})
+	assert.NoError(t, err)
+	defer app2.Cancel()
+	assert.True(t, hecode.StackEnabled())
+
+	// 开启调用栈的 App 停止后恢复为关闭，不受其他 App 影响
+	app1.Cancel()
+	assert.Eventually(t, func() bool { return !hecode.StackEnabled() }, time.Second, time.Millisecond)
+}
+
+func TestNewAppDocs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	docs := map[string]interface{}{"server": map[string]interface{}{"docs": map[string]interface{}{"enable": true, "path": "/api-docs"}}}
+	app, err := NewApp(AppOption{ConfigValues: docs, OpenAPISpec: []byte(`{"openapi":"3.1.0"}`)})
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	app.Engine.ServeHTTP(w, httptest.NewRequest("GET", "/api-docs/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	// 文档原样返回，不被 response 中间件包装
+	assert.JSONEq(t, `{"openapi":"3.1.0"}`, w.Body.String())
+
+	// 没有内嵌文档时读取 server.docs.spec，文件不存在时返回错误
+	docs["server"].(map[string]interface{})["docs"].(map[string]interface{})["spec"] = "testdata/missing.json"
+	_, err = NewApp(AppOption{ConfigValues: docs})
+	assert.Error(t, err)
+
+	// 默认不提供文档
+	app, err = NewApp(AppOption{ConfigValues: map[string]interface{}{}})
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	app.Engine.ServeHTTP(w, httptest.NewRequest("GET", "/docs/", nil))
+	assert.NotContains(t, w.Header().Get("Content-Type"), "text/html")
+}
@@ -0,0 +1,84 @@
+// Package hollowtest 提供进程内测试 hollow.App 的工具
+//
+// 使用内存配置构建 App，不依赖磁盘上的 conf.yaml；日志写入内存，方便断言；
+// 时钟替换为可控的 FakeClock；通过 Client 基于 httptest 发起请求并解析标准响应。
+package hollowtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/hollow"
+	"github.com/vaynedu/hollow/internal/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// App 测试用的 App
+type App struct {
+	*hollow.App
+	Logs  *observer.ObservedLogs // 捕获的日志
+	Clock *FakeClock             // 可控时钟，使用 opts.Clock 时为 nil
+
+	t testing.TB
+}
+
+// NewApp 创建测试用的 App，测试结束时自动释放资源
+// opts.ConfigValues 和 opts.ConfigPath 都为空时使用空的内存配置；
+// opts.Logger 为空时使用捕获日志的实例；opts.Clock 为空时使用 FakeClock
+func NewApp(t testing.TB, opts hollow.AppOption) *App {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	if opts.ConfigValues == nil && opts.ConfigPath == "" {
+		opts.ConfigValues = map[string]interface{}{}
+	}
+
+	var logs *observer.ObservedLogs
+	if opts.Logger == nil {
+		var log *zap.Logger
+		log, logs = logger.NewObserved(zapcore.DebugLevel)
+		opts.Logger = log
+	}
+
+	var clock *FakeClock
+	if opts.Clock == nil {
+		clock = NewFakeClock()
+		opts.Clock = clock
+	}
+
+	app, err := hollow.NewApp(opts)
+	if err != nil {
+		t.Fatalf("hollowtest: create app failed: %v", err)
+	}
+	t.Cleanup(func() {
+		app.Cancel()
+		_ = app.Container.Close(context.Background())
+	})
+
+	return &App{
+		App:   app,
+		Logs:  logs,
+		Clock: clock,
+		t:     t,
+	}
+}
+
+// Client 返回请求 App 的客户端
+func (a *App) Client() *Client {
+	return NewClient(a.t, a.Engine)
+}
+
+// AssertLogged 断言捕获的日志中包含指定消息，返回匹配的日志条目
+func (a *App) AssertLogged(msg string) []observer.LoggedEntry {
+	a.t.Helper()
+	if a.Logs == nil {
+		a.t.Fatalf("hollowtest: logs are not captured when AppOption.Logger is set")
+	}
+	entries := a.Logs.FilterMessage(msg).All()
+	assert.NotEmpty(a.t, entries, "expected log message %q", msg)
+	return entries
+}
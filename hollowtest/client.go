@@ -0,0 +1,130 @@
+package hollowtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// Client 基于 httptest 的进程内 HTTP 客户端
+type Client struct {
+	t       testing.TB
+	handler http.Handler
+	headers http.Header
+}
+
+// NewClient 创建请求 handler 的客户端
+func NewClient(t testing.TB, handler http.Handler) *Client {
+	return &Client{
+		t:       t,
+		handler: handler,
+		headers: http.Header{},
+	}
+}
+
+// WithHeader 设置每个请求都携带的请求头
+func (c *Client) WithHeader(key, value string) *Client {
+	c.headers.Set(key, value)
+	return c
+}
+
+// GET 创建 GET 请求
+func (c *Client) GET(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+// POST 创建 POST 请求
+func (c *Client) POST(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+// PUT 创建 PUT 请求
+func (c *Client) PUT(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+// PATCH 创建 PATCH 请求
+func (c *Client) PATCH(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+// DELETE 创建 DELETE 请求
+func (c *Client) DELETE(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+// Request 创建指定方法的请求
+func (c *Client) Request(method, path string) *Request {
+	return &Request{
+		client:  c,
+		method:  method,
+		path:    path,
+		query:   url.Values{},
+		headers: c.headers.Clone(),
+	}
+}
+
+// Request 链式构造的请求
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	query   url.Values
+	headers http.Header
+	body    io.Reader
+}
+
+// Query 添加查询参数
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Header 设置请求头
+func (r *Request) Header(key, value string) *Request {
+	r.headers.Set(key, value)
+	return r
+}
+
+// RequestID 设置请求ID
+func (r *Request) RequestID(requestID string) *Request {
+	return r.Header("X-Request-ID", requestID)
+}
+
+// JSON 设置 JSON 请求体
+func (r *Request) JSON(body interface{}) *Request {
+	data, err := json.Marshal(body)
+	if err != nil {
+		r.client.t.Fatalf("hollowtest: marshal request body failed: %v", err)
+	}
+	r.body = bytes.NewReader(data)
+	r.headers.Set("Content-Type", "application/json")
+	return r
+}
+
+// Body 设置原始请求体
+func (r *Request) Body(contentType string, body io.Reader) *Request {
+	r.body = body
+	r.headers.Set("Content-Type", contentType)
+	return r
+}
+
+// Do 发起请求
+func (r *Request) Do() *Response {
+	r.client.t.Helper()
+
+	target := r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, target, r.body)
+	req.Header = r.headers
+
+	w := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(w, req)
+	return &Response{t: r.client.t, Recorder: w}
+}
@@ -0,0 +1,43 @@
+package hollowtest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock 可控时钟，实现 htime.Clock 接口
+type FakeClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewFakeClock 创建可控时钟，初始时间固定，保证测试结果可重复
+func NewFakeClock() *FakeClock {
+	return &FakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)}
+}
+
+// Now 返回当前时间
+func (c *FakeClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Since 返回从 t 到当前时间经过的时间
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Set 设置当前时间
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance 将时间向前推进 d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,84 @@
+package hollowtest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/hollow"
+	"github.com/vaynedu/hollow/pkg/hdi"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"github.com/vaynedu/hollow/pkg/htime"
+)
+
+func TestNewApp(t *testing.T) {
+	app := NewApp(t, hollow.AppOption{
+		ConfigValues: map[string]interface{}{
+			"log": map[string]interface{}{"level": "info"},
+		},
+	})
+
+	assert.Equal(t, "info", app.Config.Log.LogLevel)
+	assert.NotNil(t, app.Logs)
+	assert.NotNil(t, app.Clock)
+
+	// 容器中的时钟就是 FakeClock
+	clock := hdi.MustResolve[htime.Clock](app.Container)
+	assert.Same(t, app.Clock, clock)
+}
+
+func TestClient(t *testing.T) {
+	app := NewApp(t, hollow.AppOption{})
+	app.Engine.GET("/users/:id", func(c *gin.Context) {
+		c.Set("data", gin.H{"id": c.Param("id"), "name": c.Query("name")})
+	})
+	app.Engine.POST("/users", func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.Name == "" {
+			c.Error(hecode.WithDetails(hecode.ErrInvalidParam, hecode.FieldViolation{Field: "name", Description: "required"}))
+			return
+		}
+		c.Set("data", req)
+	})
+
+	var user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	app.Client().GET("/users/1").Query("name", "vayne").RequestID("req-1").Do().
+		AssertSuccess().
+		AssertRequestIDEqual("req-1").
+		DecodeData(&user)
+	assert.Equal(t, "1", user.ID)
+	assert.Equal(t, "vayne", user.Name)
+
+	var details struct {
+		Details []map[string]string `json:"details"`
+	}
+	app.Client().POST("/users").JSON(map[string]string{}).Do().
+		AssertStatus(http.StatusBadRequest).
+		AssertEcode(hecode.ErrInvalidParam).
+		AssertMsg("invalid parameter").
+		AssertRequestID().
+		DecodeData(&details)
+	assert.Equal(t, "field_violation", details.Details[0]["type"])
+	assert.Equal(t, "name", details.Details[0]["field"])
+
+	app.AssertLogged("HTTP Request")
+}
+
+func TestFakeClock(t *testing.T) {
+	clock := NewFakeClock()
+	start := clock.Now()
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, time.Minute, clock.Since(start))
+
+	at := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock.Set(at)
+	assert.Equal(t, at, clock.Now())
+}
@@ -0,0 +1,120 @@
+package hollowtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/hollow/pkg/hecode"
+)
+
+// Envelope 标准响应格式，Data 保留原始 JSON 以便解析为具体类型
+type Envelope struct {
+	Code      int             `json:"code"`
+	Msg       string          `json:"msg"`
+	RequestID string          `json:"request_id"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// Response 请求结果
+type Response struct {
+	t        testing.TB
+	Recorder *httptest.ResponseRecorder
+
+	envelope *Envelope
+}
+
+// Status 返回 HTTP 状态码
+func (r *Response) Status() int {
+	return r.Recorder.Code
+}
+
+// Header 返回响应头
+func (r *Response) Header() http.Header {
+	return r.Recorder.Header()
+}
+
+// Body 返回原始响应体
+func (r *Response) Body() string {
+	return r.Recorder.Body.String()
+}
+
+// Envelope 解析标准响应格式
+func (r *Response) Envelope() *Envelope {
+	r.t.Helper()
+	if r.envelope == nil {
+		var env Envelope
+		if err := json.Unmarshal(r.Recorder.Body.Bytes(), &env); err != nil {
+			r.t.Fatalf("hollowtest: decode response envelope failed: %v, body: %s", err, r.Body())
+		}
+		r.envelope = &env
+	}
+	return r.envelope
+}
+
+// DecodeData 将响应中的 data 解析到 v
+func (r *Response) DecodeData(v interface{}) *Response {
+	r.t.Helper()
+	data := r.Envelope().Data
+	if len(data) == 0 {
+		r.t.Fatalf("hollowtest: response has no data, body: %s", r.Body())
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		r.t.Fatalf("hollowtest: decode response data failed: %v, data: %s", err, data)
+	}
+	return r
+}
+
+// AssertStatus 断言 HTTP 状态码
+func (r *Response) AssertStatus(status int) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, status, r.Status(), "unexpected http status, body: %s", r.Body())
+	return r
+}
+
+// AssertSuccess 断言请求成功
+func (r *Response) AssertSuccess() *Response {
+	r.t.Helper()
+	r.AssertStatus(http.StatusOK)
+	assert.Equal(r.t, http.StatusOK, r.Envelope().Code, "unexpected code, msg: %s", r.Envelope().Msg)
+	return r
+}
+
+// AssertCode 断言响应中的错误码
+func (r *Response) AssertCode(code int) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, code, r.Envelope().Code, "unexpected code, msg: %s", r.Envelope().Msg)
+	return r
+}
+
+// AssertEcode 断言响应中的错误码与 hecode 错误一致
+func (r *Response) AssertEcode(err error) *Response {
+	r.t.Helper()
+	return r.AssertCode(hecode.Code(err))
+}
+
+// AssertMsg 断言响应中的消息
+func (r *Response) AssertMsg(msg string) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, msg, r.Envelope().Msg)
+	return r
+}
+
+// AssertRequestID 断言响应头和响应体中都包含相同的请求ID
+func (r *Response) AssertRequestID() *Response {
+	r.t.Helper()
+	headerID := r.Header().Get("X-Request-ID")
+	assert.NotEmpty(r.t, headerID, "missing X-Request-ID header")
+	assert.Equal(r.t, headerID, r.Envelope().RequestID)
+	return r
+}
+
+// AssertRequestIDEqual 断言请求ID为指定值
+func (r *Response) AssertRequestIDEqual(requestID string) *Response {
+	r.t.Helper()
+	r.AssertRequestID()
+	assert.Equal(r.t, requestID, r.Envelope().RequestID)
+	return r
+}
@@ -13,12 +13,19 @@ type LogConfig struct {
 	MaxAge      int    `mapstructure:"max_age"`
 }
 
+// EcodeConfig 定义错误码配置结构体
+type EcodeConfig struct {
+	Stack bool `mapstructure:"stack"` // 是否在创建和包装错误时记录调用栈
+}
+
 type Config struct {
 	*viper.Viper
-	Host  string      `mapstructure:"host"`
-	Log   LogConfig   `mapstructure:"log"`
-	Db    DbConfig    `mapstructure:"db"`
-	Redis RedisConfig `mapstructure:"redis"`
+	Host   string       `mapstructure:"host"`
+	Server ServerConfig `mapstructure:"server"`
+	Log    LogConfig    `mapstructure:"log"`
+	Db     DbConfig     `mapstructure:"db"`
+	Redis  RedisConfig  `mapstructure:"redis"`
+	Ecode  EcodeConfig  `mapstructure:"ecode"`
 }
 
 func NewConfig(path string, configFileName string) (*Config, error) {
@@ -34,6 +41,20 @@ func NewConfig(path string, configFileName string) (*Config, error) {
 		return nil, err
 	}
 
+	return newConfig(v)
+}
+
+// NewConfigFromMap 从内存中的配置创建 Config，不依赖配置文件，主要用于测试
+// values 的结构与 yaml 配置文件一致，例如 {"log": {"level": "info"}}
+func NewConfigFromMap(values map[string]interface{}) (*Config, error) {
+	v := viper.New()
+	if err := v.MergeConfigMap(values); err != nil {
+		return nil, err
+	}
+	return newConfig(v)
+}
+
+func newConfig(v *viper.Viper) (*Config, error) {
 	var config Config
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, err
@@ -47,6 +68,20 @@ func NewConfig(path string, configFileName string) (*Config, error) {
 //# server:
 //#   http:
 //#     addr: ":8080"
+//#     read_timeout: 15s
+//#     read_header_timeout: 5s
+//#     write_timeout: 30s
+//#     idle_timeout: 60s
+//#     max_header_bytes: 1048576
+//#     disable_keep_alive: false
+//#     keep_alive_period: 3m
+//#     max_conns: 10000
+//#     shutdown_timeout: 10s
+//#   grpc:
+//#     enable: true
+//#     addr: ":9090"      # 为空时与 http 复用同一端口
+//# ecode:
+//#   stack: true          # App 停止前记录错误的调用栈（进程级），也可以使用 -tags hecode_stack 构建
 //# log:
 //#   level: "debug"
 //#   output_mode: "console"
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -47,3 +48,69 @@ redis:
 	t.Logf("config redis: %v", config.Redis)
 
 }
+
+func TestServerConfig(t *testing.T) {
+	configContent := `host: 127.0.0.1:8090
+server:
+  http:
+    addr: ":9090"
+    read_timeout: 3s
+    write_timeout: 1m
+    max_conns: 100
+`
+	configFile, err := os.CreateTemp(".", "test_server_config.yaml")
+	if err != nil {
+		t.Fatalf("创建临时配置文件失败: %v", err)
+	}
+	defer os.Remove(configFile.Name())
+
+	_, err = configFile.WriteString(configContent)
+	if err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+	configFile.Close()
+
+	config, err := NewConfig(".", configFile.Name())
+	assert.NoError(t, err)
+
+	httpCfg := config.Server.HTTP.WithDefaults(config.Host)
+	assert.Equal(t, ":9090", httpCfg.Addr)
+	assert.Equal(t, 3*time.Second, httpCfg.ReadTimeout)
+	assert.Equal(t, time.Minute, httpCfg.WriteTimeout)
+	assert.Equal(t, 100, httpCfg.MaxConns)
+	// 未配置的字段使用默认值
+	assert.Equal(t, DefaultReadHeaderTimeout, httpCfg.ReadHeaderTimeout)
+	assert.Equal(t, DefaultIdleTimeout, httpCfg.IdleTimeout)
+	assert.Equal(t, DefaultMaxHeaderBytes, httpCfg.MaxHeaderBytes)
+}
+
+func TestHTTPServerConfigWithDefaults(t *testing.T) {
+	// addr 未配置时兼容顶层 host
+	cfg := HTTPServerConfig{}.WithDefaults("127.0.0.1:8090")
+	assert.Equal(t, "127.0.0.1:8090", cfg.Addr)
+
+	cfg = HTTPServerConfig{}.WithDefaults("")
+	assert.Equal(t, DefaultHTTPAddr, cfg.Addr)
+	assert.Equal(t, DefaultReadTimeout, cfg.ReadTimeout)
+	assert.Equal(t, DefaultWriteTimeout, cfg.WriteTimeout)
+	assert.Equal(t, DefaultServerShutdownTimeout, cfg.ShutdownTimeout)
+	assert.Equal(t, 0, cfg.MaxConns)
+}
+
+func TestNewConfigFromMap(t *testing.T) {
+	config, err := NewConfigFromMap(map[string]interface{}{
+		"host": "127.0.0.1:8090",
+		"log": map[string]interface{}{
+			"level": "info",
+		},
+		"server": map[string]interface{}{
+			"http": map[string]interface{}{
+				"read_timeout": "3s",
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8090", config.GetString("host"))
+	assert.Equal(t, "info", config.Log.LogLevel)
+	assert.Equal(t, 3*time.Second, config.Server.HTTP.ReadTimeout)
+}
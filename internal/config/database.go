@@ -2,7 +2,7 @@ package config
 
 type DbConfig struct {
 	DSN     string `mapstructure:"dsn"`
-	Dialect string `mapstructure:"dialect"`
+	Dialect string `mapstructure:"dialect"` // mysql、postgres、sqlite，App 把对应的 hcond.Dialect 注册到容器中
 }
 
 type RedisConfig struct {
@@ -0,0 +1,121 @@
+package config
+
+import "time"
+
+// 服务默认参数，未配置时使用，防止慢连接（slowloris）长期占用服务
+const (
+	DefaultHTTPAddr              = ":8181"
+	DefaultReadTimeout           = 15 * time.Second
+	DefaultReadHeaderTimeout     = 5 * time.Second
+	DefaultWriteTimeout          = 30 * time.Second
+	DefaultIdleTimeout           = 60 * time.Second
+	DefaultMaxHeaderBytes        = 1 << 20 // 1MB
+	DefaultKeepAlivePeriod       = 3 * time.Minute
+	DefaultServerShutdownTimeout = 10 * time.Second
+	DefaultGRPCMaxMsgSize        = 4 << 20 // 4MB
+	DefaultDocsPath              = "/docs"
+	DefaultDocsSpec              = "docs/openapi.json"
+)
+
+// ServerConfig 服务配置
+type ServerConfig struct {
+	HTTP HTTPServerConfig `mapstructure:"http"`
+	GRPC GRPCServerConfig `mapstructure:"grpc"`
+	Docs DocsConfig       `mapstructure:"docs"`
+}
+
+// HTTPServerConfig HTTP 服务配置
+type HTTPServerConfig struct {
+	Addr              string        `mapstructure:"addr"`                // 监听地址
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`        // 读取整个请求（含body）的超时时间
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"` // 读取请求头的超时时间
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`       // 写响应的超时时间
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`        // keep-alive 空闲连接超时时间
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes"`    // 请求头最大字节数
+	DisableKeepAlive  bool          `mapstructure:"disable_keep_alive"`  // 是否关闭 HTTP keep-alive
+	KeepAlivePeriod   time.Duration `mapstructure:"keep_alive_period"`   // TCP keep-alive 探测周期
+	MaxConns          int           `mapstructure:"max_conns"`           // 最大并发连接数，0 表示不限制
+	ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout"`    // 优雅关闭的最长等待时间
+}
+
+// WithDefaults 返回填充了默认值的 HTTP 服务配置
+// host 为兼容旧配置的顶层 host 字段，仅在 addr 未配置时使用
+func (c HTTPServerConfig) WithDefaults(host string) HTTPServerConfig {
+	if c.Addr == "" {
+		c.Addr = host
+	}
+	if c.Addr == "" {
+		c.Addr = DefaultHTTPAddr
+	}
+	if c.ReadTimeout <= 0 {
+		c.ReadTimeout = DefaultReadTimeout
+	}
+	if c.ReadHeaderTimeout <= 0 {
+		c.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = DefaultWriteTimeout
+	}
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = DefaultIdleTimeout
+	}
+	if c.MaxHeaderBytes <= 0 {
+		c.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+	if c.KeepAlivePeriod <= 0 {
+		c.KeepAlivePeriod = DefaultKeepAlivePeriod
+	}
+	if c.MaxConns < 0 {
+		c.MaxConns = 0
+	}
+	if c.ShutdownTimeout <= 0 {
+		c.ShutdownTimeout = DefaultServerShutdownTimeout
+	}
+	return c
+}
+
+// GRPCServerConfig gRPC 服务配置
+type GRPCServerConfig struct {
+	Enable          bool          `mapstructure:"enable"`            // 是否启用 gRPC 服务
+	Addr            string        `mapstructure:"addr"`              // 独立监听地址，为空时与 HTTP 复用同一端口
+	MaxRecvMsgSize  int           `mapstructure:"max_recv_msg_size"` // 最大接收消息字节数
+	MaxSendMsgSize  int           `mapstructure:"max_send_msg_size"` // 最大发送消息字节数
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`  // 优雅关闭的最长等待时间
+}
+
+// Multiplexed 是否与 HTTP 服务复用同一端口
+func (c GRPCServerConfig) Multiplexed() bool {
+	return c.Addr == ""
+}
+
+// WithDefaults 返回填充了默认值的 gRPC 服务配置
+func (c GRPCServerConfig) WithDefaults() GRPCServerConfig {
+	if c.MaxRecvMsgSize <= 0 {
+		c.MaxRecvMsgSize = DefaultGRPCMaxMsgSize
+	}
+	if c.MaxSendMsgSize <= 0 {
+		c.MaxSendMsgSize = DefaultGRPCMaxMsgSize
+	}
+	if c.ShutdownTimeout <= 0 {
+		c.ShutdownTimeout = DefaultServerShutdownTimeout
+	}
+	return c
+}
+
+// DocsConfig 接口文档配置
+type DocsConfig struct {
+	Enable bool   `mapstructure:"enable"` // 是否提供 OpenAPI 文档和文档页面，生产环境按需开启
+	Path   string `mapstructure:"path"`   // 文档页面的路由
+	Spec   string `mapstructure:"spec"`   // hollow-cli openapi 生成的文档路径，AppOption.OpenAPISpec 不为空时不读取
+}
+
+// WithDefaults 返回填充了默认值的文档配置
+func (c DocsConfig) WithDefaults() DocsConfig {
+	if c.Path == "" {
+		c.Path = DefaultDocsPath
+	}
+	if c.Spec == "" {
+		c.Spec = DefaultDocsSpec
+	}
+	return c
+}
@@ -0,0 +1,198 @@
+package grpc
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/vaynedu/hollow/internal/middleware"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"github.com/vaynedu/hollow/pkg/hidgenerator"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// RequestIDHeader 请求ID的 metadata key，与 HTTP 的 X-Request-ID 对应
+	RequestIDHeader = "x-request-id"
+	// EcodeTrailer hecode 错误码的 trailer key，方便客户端还原业务错误码
+	EcodeTrailer = "x-ecode"
+)
+
+// DefaultUnaryInterceptors 默认的一元拦截器，顺序与 HTTP 默认中间件一致
+func DefaultUnaryInterceptors(logger *zap.Logger) []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		UnaryRequestIDInterceptor(),
+		UnaryLoggingInterceptor(logger),
+		UnaryRecoveryInterceptor(logger),
+		UnaryErrorInterceptor(),
+	}
+}
+
+// DefaultStreamInterceptors 默认的流式拦截器，顺序与 HTTP 默认中间件一致
+func DefaultStreamInterceptors(logger *zap.Logger) []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{
+		StreamRequestIDInterceptor(),
+		StreamLoggingInterceptor(logger),
+		StreamRecoveryInterceptor(logger),
+		StreamErrorInterceptor(),
+	}
+}
+
+// UnaryRequestIDInterceptor 请求ID拦截器
+func UnaryRequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+// StreamRequestIDInterceptor 流式请求ID拦截器
+func StreamRequestIDInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+	}
+}
+
+// UnaryLoggingInterceptor 日志拦截器
+func UnaryLoggingInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRPC(ctx, logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamLoggingInterceptor 流式日志拦截器
+func StreamLoggingInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logRPC(ss.Context(), logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// UnaryRecoveryInterceptor 恢复拦截器，panic 转换为 Internal 错误
+func UnaryRecoveryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(ctx, logger, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor 流式恢复拦截器
+func StreamRecoveryInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(ss.Context(), logger, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// UnaryErrorInterceptor 错误转换拦截器，将 hecode 错误转换为 gRPC status
+func UnaryErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			setEcodeTrailer(ctx, err)
+			return nil, ToStatus(err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamErrorInterceptor 流式错误转换拦截器
+func StreamErrorInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			if code := hecode.Code(err); code != 0 {
+				ss.SetTrailer(metadata.Pairs(EcodeTrailer, strconv.Itoa(code)))
+			}
+			return ToStatus(err)
+		}
+		return nil
+	}
+}
+
+// RequestIDFromContext 从上下文中获取请求ID
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(middleware.RequestIDKey).(string)
+	return requestID
+}
+
+// withRequestID 从 metadata 读取请求ID，没有则生成，并写入上下文和响应头
+func withRequestID(ctx context.Context) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = hidgenerator.NewUuid().GenerateRequestID()
+	}
+
+	// 与 HTTP 中间件使用同一个 key，业务代码无需区分协议
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID))
+	return context.WithValue(ctx, middleware.RequestIDKey, requestID)
+}
+
+func setEcodeTrailer(ctx context.Context, err error) {
+	if code := hecode.Code(err); code != 0 {
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(EcodeTrailer, strconv.Itoa(code)))
+	}
+}
+
+func logRPC(ctx context.Context, logger *zap.Logger, method string, start time.Time, err error) {
+	clientIP := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		clientIP = p.Addr.String()
+	}
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("request_id", RequestIDFromContext(ctx)),
+		zap.String("code", status.Code(ToStatus(err)).String()),
+		zap.Duration("cost", time.Since(start)),
+		zap.String("client_ip", clientIP),
+	}
+	if err != nil {
+		fields = append(fields, hecode.ZapError(err))
+	}
+	logger.Info("gRPC Request", fields...)
+}
+
+func recoverPanic(ctx context.Context, logger *zap.Logger, method string, r any) error {
+	buf := make([]byte, 64<<10)
+	buf = buf[:runtime.Stack(buf, false)]
+	logger.Error("panic recovered",
+		zap.Any("error", r),
+		zap.String("stack", string(buf)),
+		zap.String("method", method),
+		zap.String("request_id", RequestIDFromContext(ctx)),
+	)
+	return status.Error(codes.Internal, "Internal Server Error")
+}
+
+// wrappedStream 替换 ServerStream 的上下文
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,116 @@
+// Package grpc 提供与 HTTP 服务共享生命周期的 gRPC 服务
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/vaynedu/hollow/internal/config"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// Server gRPC 服务封装
+type Server struct {
+	cfg    config.GRPCServerConfig
+	server *grpc.Server
+	logger *zap.Logger
+}
+
+// NewServer 创建 gRPC 服务，cfg 需要已经填充默认值
+// 默认安装请求ID、日志、恢复和错误转换拦截器，opts 会追加在默认选项之后
+func NewServer(cfg config.GRPCServerConfig, logger *zap.Logger, opts ...grpc.ServerOption) *Server {
+	serverOpts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(cfg.MaxSendMsgSize),
+		grpc.ChainUnaryInterceptor(DefaultUnaryInterceptors(logger)...),
+		grpc.ChainStreamInterceptor(DefaultStreamInterceptors(logger)...),
+	}
+	serverOpts = append(serverOpts, opts...)
+
+	return &Server{
+		cfg:    cfg,
+		server: grpc.NewServer(serverOpts...),
+		logger: logger,
+	}
+}
+
+// Server 返回底层的 grpc.Server，用于注册服务
+func (s *Server) Server() *grpc.Server {
+	return s.server
+}
+
+// RegisterService 注册 gRPC 服务，实现 grpc.ServiceRegistrar 接口
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.server.RegisterService(desc, impl)
+}
+
+// Config 返回 gRPC 服务配置
+func (s *Server) Config() config.GRPCServerConfig {
+	return s.cfg
+}
+
+// ListenAndServe 在独立端口上提供服务，正常关闭时返回 nil
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve 在指定 Listener 上提供服务，正常关闭时返回 nil
+func (s *Server) Serve(ln net.Listener) error {
+	if err := s.server.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 优雅关闭服务，超过 ShutdownTimeout 后强制关闭
+func (s *Server) Shutdown(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.Warn("grpc graceful stop timeout, force stop")
+		s.server.Stop()
+	}
+}
+
+// MuxHandler 返回同端口复用的 http.Handler
+// gRPC 请求（HTTP/2 且 Content-Type 为 application/grpc）交给 gRPC 服务，其余交给 httpHandler
+// 需要 HTTP 服务开启明文 HTTP/2（h2c），见 EnableH2C
+func (s *Server) MuxHandler(httpHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsGRPCRequest(r) {
+			s.server.ServeHTTP(w, r)
+			return
+		}
+		httpHandler.ServeHTTP(w, r)
+	})
+}
+
+// IsGRPCRequest 判断是否为 gRPC 请求
+func IsGRPCRequest(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// EnableH2C 为 http.Server 开启明文 HTTP/2，使 gRPC 客户端可以直接连接 HTTP 端口
+func EnableH2C(srv *http.Server) {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	srv.Protocols = protocols
+}
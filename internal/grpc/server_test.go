@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/hollow/internal/config"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// echoServer 测试用服务，根据请求内容返回不同结果
+type echoServer struct{}
+
+func (echoServer) Echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	switch in.GetValue() {
+	case "panic":
+		panic("test panic")
+	case "not_found":
+		return nil, hecode.Wrap(hecode.ErrNotFound, "user not found")
+	case "request_id":
+		return wrapperspb.String(RequestIDFromContext(ctx)), nil
+	}
+	return in, nil
+}
+
+var echoServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hollow.test.Echo",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(wrapperspb.StringValue)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/hollow.test.Echo/Echo"}
+				handler := func(ctx context.Context, req any) (any, error) {
+					return srv.(echoServer).Echo(ctx, req.(*wrapperspb.StringValue))
+				}
+				return interceptor(ctx, in, info, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			ServerStreams: true,
+			Handler: func(srv any, stream grpc.ServerStream) error {
+				in := new(wrapperspb.StringValue)
+				if err := stream.RecvMsg(in); err != nil {
+					return err
+				}
+				if in.GetValue() == "not_found" {
+					return hecode.ErrNotFound
+				}
+				return stream.SendMsg(wrapperspb.String(RequestIDFromContext(stream.Context())))
+			},
+		},
+	},
+}
+
+func newTestConn(t *testing.T) *grpc.ClientConn {
+	cfg := config.GRPCServerConfig{Enable: true}.WithDefaults()
+	srv := NewServer(cfg, zap.NewNop())
+	srv.RegisterService(&echoServiceDesc, echoServer{})
+
+	ln := bufconn.Listen(1 << 20)
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServerUnary(t *testing.T) {
+	conn := newTestConn(t)
+	ctx := context.Background()
+
+	out := new(wrapperspb.StringValue)
+	var header metadata.MD
+	err := conn.Invoke(ctx, "/hollow.test.Echo/Echo", wrapperspb.String("hello"), out, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", out.GetValue())
+	// 未传入请求ID时自动生成并写入响应头
+	assert.NotEmpty(t, header.Get(RequestIDHeader))
+
+	// 透传客户端的请求ID
+	ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, "req-123")
+	err = conn.Invoke(ctx, "/hollow.test.Echo/Echo", wrapperspb.String("request_id"), out)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-123", out.GetValue())
+}
+
+func TestServerErrorMapping(t *testing.T) {
+	conn := newTestConn(t)
+
+	var trailer metadata.MD
+	err := conn.Invoke(context.Background(), "/hollow.test.Echo/Echo", wrapperspb.String("not_found"),
+		new(wrapperspb.StringValue), grpc.Trailer(&trailer))
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "user not found", st.Message())
+	assert.Equal(t, []string{"1200"}, trailer.Get(EcodeTrailer))
+}
+
+func TestServerRecovery(t *testing.T) {
+	conn := newTestConn(t)
+
+	err := conn.Invoke(context.Background(), "/hollow.test.Echo/Echo", wrapperspb.String("panic"), new(wrapperspb.StringValue))
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// panic 后服务仍然可用
+	out := new(wrapperspb.StringValue)
+	err = conn.Invoke(context.Background(), "/hollow.test.Echo/Echo", wrapperspb.String("ok"), out)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", out.GetValue())
+}
+
+func TestServerStream(t *testing.T) {
+	conn := newTestConn(t)
+	desc := &echoServiceDesc.Streams[0]
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDHeader, "req-stream")
+	stream, err := conn.NewStream(ctx, desc, "/hollow.test.Echo/Stream")
+	assert.NoError(t, err)
+	assert.NoError(t, stream.SendMsg(wrapperspb.String("hello")))
+	assert.NoError(t, stream.CloseSend())
+
+	out := new(wrapperspb.StringValue)
+	assert.NoError(t, stream.RecvMsg(out))
+	assert.Equal(t, "req-stream", out.GetValue())
+	assert.Equal(t, io.EOF, stream.RecvMsg(out))
+
+	stream, err = conn.NewStream(context.Background(), desc, "/hollow.test.Echo/Stream")
+	assert.NoError(t, err)
+	assert.NoError(t, stream.SendMsg(wrapperspb.String("not_found")))
+	assert.NoError(t, stream.CloseSend())
+	err = stream.RecvMsg(out)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, []string{"1200"}, stream.Trailer().Get(EcodeTrailer))
+}
+
+func TestGRPCCode(t *testing.T) {
+	assert.Equal(t, codes.NotFound, GRPCCode(hecode.Code(hecode.ErrNotFound)))
+	assert.Equal(t, codes.InvalidArgument, GRPCCode(hecode.Code(hecode.ErrMissingParam)))
+	assert.Equal(t, codes.Internal, GRPCCode(hecode.Code(hecode.ErrDatabase)))
+	assert.Nil(t, ToStatus(nil))
+	assert.Equal(t, codes.Unknown, status.Code(ToStatus(io.EOF)))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(ToStatus(context.DeadlineExceeded)))
+}
@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCCode 将 hecode 错误码转换为 gRPC 状态码
+// 已注册的错误码使用注册表中的映射，未注册的错误码按号段归类
+func GRPCCode(code int) codes.Code {
+	if meta, ok := hecode.Lookup(code); ok {
+		return meta.GRPCCode
+	}
+	switch {
+	case code >= 1100 && code < 1200:
+		return codes.InvalidArgument
+	case code >= 1300 && code < 1400:
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
+
+// ToStatus 将错误转换为 gRPC status 错误
+// 已经是 status 的错误原样返回，hecode 错误按错误码映射，消息使用错误码对应的消息
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	var ec *hecode.EcodeError
+	if !errors.As(err, &ec) {
+		return status.Error(codes.Unknown, err.Error())
+	}
+	return status.Error(GRPCCode(ec.Code()), ec.GetMessage())
+}
@@ -0,0 +1,137 @@
+package idl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emicklei/proto"
+	"github.com/vaynedu/hollow/pkg/hbind"
+)
+
+// HTTPRule google.api.http 注解中的一个 HTTP 绑定
+type HTTPRule struct {
+	Method       string // GET、POST、PUT、DELETE、PATCH，custom 绑定为 kind 的大写形式
+	Path         string // 路径模板，例如 /v1/{name=users/*}
+	Body         string // 请求体对应的字段，* 表示整个请求，空表示没有请求体
+	ResponseBody string // 作为响应体的字段，空表示整个响应
+	Template     *hbind.PathTemplate
+}
+
+// parseHTTPRules 解析 google.api.http 注解，第一个为主绑定，其余为 additional_bindings
+func parseHTTPRules(rule *proto.Literal) ([]HTTPRule, error) {
+	primary, err := parseHTTPRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	rules := []HTTPRule{primary}
+	for _, nl := range rule.OrderedMap {
+		if nl.Name != "additional_bindings" {
+			continue
+		}
+		bindings := nl.Array
+		if len(bindings) == 0 {
+			bindings = []*proto.Literal{nl.Literal}
+		}
+		for _, b := range bindings {
+			if _, nested := b.OrderedMap.Get("additional_bindings"); nested {
+				return nil, fmt.Errorf("additional_bindings 不能嵌套")
+			}
+			r, err := parseHTTPRule(b)
+			if err != nil {
+				return nil, fmt.Errorf("additional_bindings: %w", err)
+			}
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+func parseHTTPRule(l *proto.Literal) (HTTPRule, error) {
+	var r HTTPRule
+	for _, nl := range l.OrderedMap {
+		switch nl.Name {
+		case "get", "post", "put", "delete", "patch":
+			if r.Method != "" {
+				return r, fmt.Errorf("同一个绑定只能指定一个 HTTP 方法")
+			}
+			r.Method, r.Path = strings.ToUpper(nl.Name), nl.Source
+		case "custom":
+			if r.Method != "" {
+				return r, fmt.Errorf("同一个绑定只能指定一个 HTTP 方法")
+			}
+			kind, _ := nl.OrderedMap.Get("kind")
+			path, _ := nl.OrderedMap.Get("path")
+			if kind.Source == "" || path.Source == "" {
+				return r, fmt.Errorf("custom 需要指定 kind 和 path")
+			}
+			r.Method, r.Path = strings.ToUpper(kind.Source), path.Source
+		case "body":
+			r.Body = nl.Source
+		case "response_body":
+			r.ResponseBody = nl.Source
+		}
+	}
+	if r.Method == "" {
+		return r, fmt.Errorf("缺少 HTTP 方法，需要 get、post、put、delete、patch 或 custom 之一")
+	}
+	t, err := hbind.ParsePathTemplate(r.Path)
+	if err != nil {
+		return r, err
+	}
+	r.Template = t
+	return r, nil
+}
+
+// inferHTTPRule 没有注解时根据方法名推断，GET 和 DELETE 从查询参数绑定，其他方法绑定整个请求体；
+// 客户端流使用 WebSocket，总是 GET
+func inferHTTPRule(methodName string, clientStreaming bool) HTTPRule {
+	r := HTTPRule{
+		Method: inferHTTPMethod(methodName),
+		Path:   "/" + strings.ToLower(methodName),
+	}
+	if clientStreaming {
+		r.Method = "GET"
+	}
+	if r.Method != "GET" && r.Method != "DELETE" {
+		r.Body = "*"
+	}
+	r.Template, _ = hbind.ParsePathTemplate(r.Path)
+	return r
+}
+
+// checkHTTPRule 检查 body 和路径变量引用的字段是否存在于请求消息中
+func (f *File) checkHTTPRule(m Method, r HTTPRule) error {
+	req := f.Message(m.RequestType)
+	if req == nil {
+		// 请求消息定义在其他文件中，无法检查
+		return nil
+	}
+	if r.Body != "" && r.Body != "*" && req.Field(r.Body) == nil {
+		return fmt.Errorf("body 字段 %s 不存在于 %s 中", r.Body, req.Name)
+	}
+	if r.ResponseBody != "" {
+		if resp := f.Message(m.ResponseType); resp != nil && resp.Field(r.ResponseBody) == nil {
+			return fmt.Errorf("response_body 字段 %s 不存在于 %s 中", r.ResponseBody, resp.Name)
+		}
+	}
+	for _, v := range r.Template.Vars {
+		msg := req
+		parts := strings.Split(v.Field, ".")
+		for i, part := range parts {
+			field := msg.Field(part)
+			if field == nil {
+				return fmt.Errorf("路径变量 %s 不存在于 %s 中", v.Field, req.Name)
+			}
+			if field.Repeated || field.MapKey != "" {
+				return fmt.Errorf("路径变量 %s 不能是 repeated 或 map 字段", v.Field)
+			}
+			if i == len(parts)-1 {
+				break
+			}
+			if msg = f.Message(field.Type); msg == nil {
+				break
+			}
+		}
+	}
+	return nil
+}
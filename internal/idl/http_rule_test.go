@@ -0,0 +1,141 @@
+package idl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bookProto = `syntax = "proto3";
+package library.v1;
+
+message Book {
+  string name = 1;
+  repeated string tags = 2;
+}
+
+message GetBookRequest {
+  string name = 1;
+  Book book = 2;
+}
+
+message UpdateBookRequest {
+  Book book = 1;
+  bool validate_only = 2;
+}
+
+message ListBooksResponse {
+  repeated Book books = 1;
+}
+
+service Library {
+  rpc GetBook (GetBookRequest) returns (Book) {
+    option (google.api.http) = {
+      get: "/v1/{name=shelves/*/books/*}"
+      additional_bindings { get: "/v1/books/{book.name}" }
+      additional_bindings {
+        custom: { kind: "head" path: "/v1/{name=shelves/*/books/*}" }
+      }
+    };
+  }
+
+  rpc UpdateBook (UpdateBookRequest) returns (Book) {
+    option (google.api.http) = {
+      patch: "/v1/{book.name=shelves/*/books/*}"
+      body: "book"
+    };
+  }
+
+  rpc ListBooks (GetBookRequest) returns (ListBooksResponse) {
+    option (google.api.http) = {
+      get: "/v1/books"
+      response_body: "books"
+    };
+  }
+
+  rpc CreateBook (Book) returns (Book);
+}
+`
+
+func TestParseHTTPRules(t *testing.T) {
+	file, err := Parse(strings.NewReader(bookProto), "library.proto")
+	require.NoError(t, err)
+	methods := file.Service("Library").Methods
+
+	get := methods[0]
+	require.Len(t, get.HTTPRules, 3)
+	assert.Equal(t, "GET", get.HTTPMethod)
+	assert.Equal(t, "/v1/{name=shelves/*/books/*}", get.Path)
+	gin, err := get.HTTPRules[0].Template.GinPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/shelves/:name/books/:name2", gin)
+	assert.Equal(t, []string{"book.name"}, get.HTTPRules[1].Template.Fields())
+	assert.Equal(t, "HEAD", get.HTTPRules[2].Method)
+
+	update := methods[1]
+	assert.Equal(t, HTTPRule{
+		Method:   "PATCH",
+		Path:     "/v1/{book.name=shelves/*/books/*}",
+		Body:     "book",
+		Template: update.HTTPRules[0].Template,
+	}, update.HTTPRules[0])
+	assert.Equal(t, "JSON", update.BindingType)
+
+	assert.Equal(t, "books", methods[2].HTTPRules[0].ResponseBody)
+
+	// 没有注解时推断
+	create := methods[3]
+	require.Len(t, create.HTTPRules, 1)
+	assert.Equal(t, "POST", create.HTTPRules[0].Method)
+	assert.Equal(t, "/createbook", create.HTTPRules[0].Path)
+	assert.Equal(t, "*", create.HTTPRules[0].Body)
+}
+
+func TestParseHTTPRulesInvalid(t *testing.T) {
+	tests := []struct {
+		rule string
+		err  string
+	}{
+		{`get: "/v1/books" post: "/v1/books"`, "只能指定一个 HTTP 方法"},
+		{`body: "*"`, "缺少 HTTP 方法"},
+		{`get: "v1/books"`, "必须以 / 开头"},
+		{`custom: { kind: "HEAD" }`, "custom 需要指定 kind 和 path"},
+		{`get: "/v1/{title}"`, "路径变量 title 不存在于 GetBookRequest 中"},
+		{`get: "/v1/{book.title}"`, "路径变量 book.title 不存在于 GetBookRequest 中"},
+		{`get: "/v1/{tags}"`, "不能是 repeated 或 map 字段"},
+		{`post: "/v1/books" body: "title"`, "body 字段 title 不存在于 GetBookRequest 中"},
+		{`get: "/v1/books" response_body: "title"`, "response_body 字段 title 不存在于 Book 中"},
+		{`get: "/v1/books" additional_bindings { get: "/v2/books" additional_bindings { get: "/v3/books" } }`, "不能嵌套"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			src := `syntax = "proto3";
+message Book { string name = 1; }
+message GetBookRequest { string name = 1; Book book = 2; repeated string tags = 3; }
+service Library {
+  rpc GetBook (GetBookRequest) returns (Book) {
+    option (google.api.http) = { ` + tt.rule + ` };
+  }
+}`
+			_, err := Parse(strings.NewReader(src), "library.proto")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Contains(t, err.Error(), "Library.GetBook")
+		})
+	}
+}
+
+func TestParseHTTPRulesClientStreaming(t *testing.T) {
+	src := `syntax = "proto3";
+message Book { string name = 1; }
+service Library {
+  rpc UploadBooks (stream Book) returns (Book) {
+    option (google.api.http) = { post: "/v1/books:upload" body: "*" };
+  }
+}`
+	_, err := Parse(strings.NewReader(src), "library.proto")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "客户端流只支持没有 body 的 get 绑定")
+}
@@ -1,234 +1,452 @@
 package idl
 
 import (
-	"bufio"
 	"errors"
+	"fmt"
+	"io"
 	"os"
-	"regexp"
+	"path/filepath"
 	"strings"
+
+	"github.com/emicklei/proto"
 )
 
+// File 表示一个 Protobuf 文件的完整定义
+type File struct {
+	Path      string
+	Syntax    string
+	Package   string
+	GoPackage string
+	Imports   []string
+	Options   []Option
+	Services  []*Service
+	Messages  []*Message // 顶层消息，嵌套消息在 Message.Messages 中
+	Enums     []*Enum    // 顶层枚举
+
+	messages map[string]*Message
+	enums    map[string]*Enum
+}
+
 // Service 表示从Protobuf解析出的服务定义
 type Service struct {
 	Name    string
+	Comment string
 	Methods []Method
 }
 
 // Method 表示服务中的方法定义
 type Method struct {
 	Name         string
+	Comment      string
 	RequestType  string
 	ResponseType string
-	HTTPMethod   string
-	Path         string
-	BindingType  string
+	// ClientStreaming 和 ServerStreaming 分别对应请求和响应前的 stream 关键字
+	ClientStreaming bool
+	ServerStreaming bool
+	HTTPMethod      string // 主绑定的 HTTP 方法，与 HTTPRules[0] 相同
+	Path            string // 主绑定的路径模板
+	BindingType     string // 主绑定有请求体时为 JSON，否则为 Query
+	HTTPRules       []HTTPRule
+	Options         []Option
 }
 
-// ParseProto 解析Protobuf文件并提取服务和方法信息
-func ParseProto(protoPath string) (*Service, error) {
-	file, err := os.Open(protoPath)
+// Message 消息定义，嵌套消息的名称为 Outer.Inner
+type Message struct {
+	Name     string
+	Comment  string
+	Fields   []Field
+	Messages []*Message
+	Enums    []*Enum
+}
+
+// Field 消息字段，map 字段的 Type 为值类型，MapKey 为键类型
+type Field struct {
+	Name     string
+	Type     string
+	Number   int
+	Repeated bool
+	Optional bool
+	MapKey   string
+	Oneof    string // 所属的 oneof，不属于 oneof 时为空
+	Comment  string
+	Options  []Option
+}
+
+// Enum 枚举定义
+type Enum struct {
+	Name    string
+	Comment string
+	Values  []EnumValue
+}
+
+// EnumValue 枚举值
+type EnumValue struct {
+	Name    string
+	Number  int
+	Comment string
+}
+
+// Option 选项，名称保留括号，例如 (validate.rules).string.min_len；
+// 聚合值 { a: 1 b: { c: 2 } } 展开为 Name.a、Name.b.c 多个选项，字符串值去掉引号
+type Option struct {
+	Name  string
+	Value string
+}
+
+// ParseFile 解析Protobuf文件
+func ParseFile(protoPath string) (*File, error) {
+	f, err := os.Open(protoPath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	defer f.Close()
 
-	scanner := bufio.NewScanner(file)
-	var content strings.Builder
-	for scanner.Scan() {
-		content.WriteString(scanner.Text() + "\n")
-	}
-	if err := scanner.Err(); err != nil {
+	return Parse(f, protoPath)
+}
+
+// Parse 从 reader 解析Protobuf定义，filename 用于错误信息中的位置
+func Parse(r io.Reader, filename string) (*File, error) {
+	p := proto.NewParser(r)
+	p.Filename(filename)
+	parsed, err := p.Parse()
+	if err != nil {
 		return nil, err
 	}
 
-	protoContent := content.String()
-
-	// 移除单行注释
-	reSingleLineComment := regexp.MustCompile(`//.*`)
-	protoContent = reSingleLineComment.ReplaceAllString(protoContent, "")
-
-	// 移除多行注释
-	reMultiLineComment := regexp.MustCompile(`/\*.*?\*/`)
-	protoContent = reMultiLineComment.ReplaceAllString(protoContent, "")
+	file := &File{
+		Path:     filename,
+		messages: make(map[string]*Message),
+		enums:    make(map[string]*Enum),
+	}
+	for _, elem := range parsed.Elements {
+		switch e := elem.(type) {
+		case *proto.Syntax:
+			file.Syntax = e.Value
+		case *proto.Package:
+			file.Package = e.Name
+		case *proto.Import:
+			file.Imports = append(file.Imports, e.Filename)
+		case *proto.Option:
+			file.Options = append(file.Options, flattenOption(e)...)
+			if e.Name == "go_package" {
+				file.GoPackage = e.Constant.Source
+			}
+		case *proto.Service:
+			service, err := newService(e)
+			if err != nil {
+				return nil, err
+			}
+			file.Services = append(file.Services, service)
+		case *proto.Message:
+			if e.IsExtend {
+				continue
+			}
+			file.Messages = append(file.Messages, file.addMessage(e, ""))
+		case *proto.Enum:
+			file.Enums = append(file.Enums, file.addEnum(e, ""))
+		}
+	}
+	for _, s := range file.Services {
+		for _, m := range s.Methods {
+			for _, r := range m.HTTPRules {
+				if err := file.checkHTTPRule(m, r); err != nil {
+					return nil, fmt.Errorf("%s: rpc %s.%s: %w", filename, s.Name, m.Name, err)
+				}
+			}
+		}
+	}
+	return file, nil
+}
 
-	// 匹配服务定义 - 使用更灵活的方式
-	serviceRegex := regexp.MustCompile(`service\s+(\w+)\s*\{`)
-	serviceMatches := serviceRegex.FindStringSubmatchIndex(protoContent)
-	if len(serviceMatches) < 4 {
+// ParseProto 解析Protobuf文件并返回第一个服务，文件中有多个服务时使用 ParseFile
+func ParseProto(protoPath string) (*Service, error) {
+	file, err := ParseFile(protoPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Services) == 0 {
 		return nil, errors.New("no service definition found in proto file")
 	}
-
-	// 提取服务名
-	serviceNameMatch := serviceRegex.FindStringSubmatch(protoContent)
-	service := &Service{
-		Name: serviceNameMatch[1],
+	service := file.Services[0]
+	if len(service.Methods) == 0 {
+		return nil, errors.New("no valid rpc methods found")
 	}
+	return service, nil
+}
 
-	// 找到服务定义的结束位置（匹配大括号）
-	matchStart := serviceMatches[0]
-
-	// 从匹配字符串的开始位置向后查找第一个 {
-	startIdx := matchStart
-	for i := matchStart; i < len(protoContent); i++ {
-		if protoContent[i] == '{' {
-			startIdx = i + 1
-			break
+// Service 按名称查找服务
+func (f *File) Service(name string) *Service {
+	for _, s := range f.Services {
+		if s.Name == name {
+			return s
 		}
 	}
+	return nil
+}
 
-	braceCount := 1
-	endIdx := startIdx
+// Message 按名称查找消息，支持 Name、Outer.Inner 以及带包名的 pkg.Name、.pkg.Name，
+// 找不到时按嵌套消息的短名称查找，例如在 Outer 中引用的 Inner
+func (f *File) Message(name string) *Message {
+	name = f.localName(name)
+	if m, ok := f.messages[name]; ok {
+		return m
+	}
+	var found *Message
+	for full, m := range f.messages {
+		if strings.HasSuffix(full, "."+name) {
+			if found != nil {
+				return nil
+			}
+			found = m
+		}
+	}
+	return found
+}
 
-	for i := startIdx; i < len(protoContent); i++ {
-		if protoContent[i] == '{' {
-			braceCount++
-		} else if protoContent[i] == '}' {
-			braceCount--
-			if braceCount == 0 {
-				endIdx = i
-				break
+// Enum 按名称查找枚举，名称规则与 Message 相同
+func (f *File) Enum(name string) *Enum {
+	name = f.localName(name)
+	if e, ok := f.enums[name]; ok {
+		return e
+	}
+	var found *Enum
+	for full, e := range f.enums {
+		if strings.HasSuffix(full, "."+name) {
+			if found != nil {
+				return nil
 			}
+			found = e
 		}
 	}
+	return found
+}
 
-	// 提取服务体内容
-	serviceBody := protoContent[startIdx:endIdx]
+// GoPackageName go_package 中的包名，例如 "github.com/a/b/proto;userpb" 返回 userpb，
+// 没有 go_package 时使用 package 的最后一段
+func (f *File) GoPackageName() string {
+	if f.GoPackage != "" {
+		if i := strings.LastIndex(f.GoPackage, ";"); i >= 0 {
+			return f.GoPackage[i+1:]
+		}
+		return filepath.Base(strings.TrimSuffix(f.GoPackage, "/"))
+	}
+	return f.Package[strings.LastIndex(f.Package, ".")+1:]
+}
 
-	// 解析 RPC 方法
-	methods := parseRPCMethods(serviceBody)
-	service.Methods = methods
+// localName 去掉前导点号和本文件的包名
+func (f *File) localName(name string) string {
+	name = strings.TrimPrefix(name, ".")
+	if f.Package != "" {
+		name = strings.TrimPrefix(name, f.Package+".")
+	}
+	return name
+}
 
-	if len(service.Methods) == 0 {
-		return nil, errors.New("no valid rpc methods found")
+// Field 按名称查找字段
+func (m *Message) Field(name string) *Field {
+	for i := range m.Fields {
+		if m.Fields[i].Name == name {
+			return &m.Fields[i]
+		}
 	}
+	return nil
+}
 
-	return service, nil
+// Option 返回名称为 name 的第一个选项的值
+func (f Field) Option(name string) (string, bool) {
+	return lookupOption(f.Options, name)
 }
 
-// parseRPCMethods 解析服务体中的 RPC 方法
-func parseRPCMethods(serviceBody string) []Method {
-	var methods []Method
-	lines := strings.Split(serviceBody, "\n")
+// Option 返回名称为 name 的第一个选项的值
+func (m Method) Option(name string) (string, bool) {
+	return lookupOption(m.Options, name)
+}
 
-	var currentMethod *Method
-	var inOptionBlock bool
-	var optionBraceCount int
-	var optionBuffer strings.Builder
+// IsScalar 字段类型是否为 Protobuf 标量类型
+func (f Field) IsScalar() bool {
+	switch f.Type {
+	case "double", "float", "int32", "int64", "uint32", "uint64", "sint32", "sint64",
+		"fixed32", "fixed64", "sfixed32", "sfixed64", "bool", "string", "bytes":
+		return true
+	}
+	return false
+}
 
-	for i := 0; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
+func lookupOption(options []Option, name string) (string, bool) {
+	for _, o := range options {
+		if o.Name == name {
+			return o.Value, true
 		}
+	}
+	return "", false
+}
 
-		// 检测 RPC 方法定义开始
-		if strings.HasPrefix(line, "rpc ") {
-			// 如果之前有方法，保存它
-			if currentMethod != nil {
-				methods = append(methods, *currentMethod)
+func newService(s *proto.Service) (*Service, error) {
+	service := &Service{
+		Name:    s.Name,
+		Comment: commentText(s.Comment),
+	}
+	for _, elem := range s.Elements {
+		if rpc, ok := elem.(*proto.RPC); ok {
+			m, err := newMethod(rpc)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rpc %s.%s: %w", rpc.Position, s.Name, rpc.Name, err)
 			}
+			service.Methods = append(service.Methods, m)
+		}
+	}
+	return service, nil
+}
 
-			// 解析 RPC 定义
-			// 格式: rpc MethodName (Request) returns (Response) {
-			rpcRegex := regexp.MustCompile(`rpc\s+(\w+)\s*\(([\w.]+)\)\s*returns\s*\(([\w.]+)\)\s*\{?`)
-			matches := rpcRegex.FindStringSubmatch(line)
-			if len(matches) >= 4 {
-				methodName := matches[1]
-				currentMethod = &Method{
-					Name:         methodName,
-					RequestType:  matches[2],
-					ResponseType: matches[3],
-					HTTPMethod:   inferHTTPMethod(methodName),
-					Path:         "/" + strings.ToLower(methodName),
-					BindingType:  "JSON",
-				}
-			}
+func newMethod(rpc *proto.RPC) (Method, error) {
+	m := Method{
+		Name:         rpc.Name,
+		Comment:      commentText(rpc.Comment, rpc.InlineComment),
+		RequestType:  rpc.RequestType,
+		ResponseType: rpc.ReturnsType,
 
-			// 检查是否在同一行开始了 option 块
-			if strings.Contains(line, "option") || strings.Contains(line, "{") {
-				// 查找本行或后续行的 option 块
-				if strings.Contains(line, "option") {
-					inOptionBlock = true
-					optionBraceCount = 0
-					optionBuffer.Reset()
-				}
-			}
+		ClientStreaming: rpc.StreamsRequest,
+		ServerStreaming: rpc.StreamsReturns,
+	}
+	for _, elem := range rpc.Elements {
+		o, ok := elem.(*proto.Option)
+		if !ok {
 			continue
 		}
-
-		// 如果在 option 块中
-		if inOptionBlock {
-			optionBuffer.WriteString(line + "\n")
-
-			// 计算大括号
-			for _, ch := range line {
-				if ch == '{' {
-					optionBraceCount++
-				} else if ch == '}' {
-					optionBraceCount--
-					if optionBraceCount <= 0 {
-						// option 块结束，解析 HTTP 注解
-						if currentMethod != nil {
-							httpMethod, httpPath := parseHTTPAnnotation(optionBuffer.String())
-							if httpMethod != "" {
-								currentMethod.HTTPMethod = httpMethod
-							}
-							if httpPath != "" {
-								currentMethod.Path = httpPath
-							}
-						}
-						inOptionBlock = false
-						optionBuffer.Reset()
-						break
-					}
-				}
+		m.Options = append(m.Options, flattenOption(o)...)
+		if o.Name == "(google.api.http)" {
+			rules, err := parseHTTPRules(&o.Constant)
+			if err != nil {
+				return m, fmt.Errorf("google.api.http: %w", err)
 			}
-			continue
+			m.HTTPRules = rules
 		}
+	}
+	if len(m.HTTPRules) == 0 {
+		m.HTTPRules = []HTTPRule{inferHTTPRule(rpc.Name, m.ClientStreaming)}
+	}
+	if m.ClientStreaming {
+		// 客户端流通过 WebSocket 传输，握手只能是没有请求体的 GET
+		for _, r := range m.HTTPRules {
+			if r.Method != "GET" || r.Body != "" {
+				return m, fmt.Errorf("客户端流只支持没有 body 的 get 绑定")
+			}
+		}
+	}
+	m.HTTPMethod, m.Path = m.HTTPRules[0].Method, m.HTTPRules[0].Path
+	m.BindingType = "Query"
+	if m.HTTPRules[0].Body != "" {
+		m.BindingType = "JSON"
+	}
+	return m, nil
+}
 
-		// 检测 option 块开始
-		if strings.HasPrefix(line, "option") && currentMethod != nil {
-			inOptionBlock = true
-			optionBraceCount = 0
-			optionBuffer.Reset()
-			optionBuffer.WriteString(line + "\n")
-
-			// 计算本行的大括号
-			for _, ch := range line {
-				if ch == '{' {
-					optionBraceCount++
-				} else if ch == '}' {
-					optionBraceCount--
-					if optionBraceCount <= 0 {
-						// 单行 option 块
-						httpMethod, httpPath := parseHTTPAnnotation(optionBuffer.String())
-						if httpMethod != "" {
-							currentMethod.HTTPMethod = httpMethod
-						}
-						if httpPath != "" {
-							currentMethod.Path = httpPath
-						}
-						inOptionBlock = false
-						optionBuffer.Reset()
-					}
+// addMessage 记录消息以及嵌套的消息和枚举，prefix 为外层消息的名称
+func (f *File) addMessage(pm *proto.Message, prefix string) *Message {
+	m := &Message{
+		Name:    prefix + pm.Name,
+		Comment: commentText(pm.Comment),
+	}
+	f.messages[m.Name] = m
+	for _, elem := range pm.Elements {
+		switch e := elem.(type) {
+		case *proto.NormalField:
+			field := newField(e.Field, e.Options)
+			field.Repeated = e.Repeated
+			field.Optional = e.Optional
+			m.Fields = append(m.Fields, field)
+		case *proto.MapField:
+			field := newField(e.Field, e.Options)
+			field.MapKey = e.KeyType
+			m.Fields = append(m.Fields, field)
+		case *proto.Oneof:
+			for _, oe := range e.Elements {
+				if of, ok := oe.(*proto.OneOfField); ok {
+					field := newField(of.Field, of.Options)
+					field.Oneof = e.Name
+					m.Fields = append(m.Fields, field)
 				}
 			}
-			continue
+		case *proto.Message:
+			if !e.IsExtend {
+				m.Messages = append(m.Messages, f.addMessage(e, m.Name+"."))
+			}
+		case *proto.Enum:
+			m.Enums = append(m.Enums, f.addEnum(e, m.Name+"."))
 		}
+	}
+	return m
+}
 
-		// 检测 RPC 方法结束（闭合大括号）
-		if line == "}" && currentMethod != nil && !inOptionBlock {
-			methods = append(methods, *currentMethod)
-			currentMethod = nil
+func newField(pf *proto.Field, options []*proto.Option) Field {
+	field := Field{
+		Name:    pf.Name,
+		Type:    pf.Type,
+		Number:  pf.Sequence,
+		Comment: commentText(pf.Comment, pf.InlineComment),
+	}
+	for _, o := range options {
+		field.Options = append(field.Options, flattenOption(o)...)
+	}
+	return field
+}
+
+func (f *File) addEnum(pe *proto.Enum, prefix string) *Enum {
+	e := &Enum{
+		Name:    prefix + pe.Name,
+		Comment: commentText(pe.Comment),
+	}
+	f.enums[e.Name] = e
+	for _, elem := range pe.Elements {
+		if v, ok := elem.(*proto.EnumField); ok {
+			e.Values = append(e.Values, EnumValue{
+				Name:    v.Name,
+				Number:  v.Integer,
+				Comment: commentText(v.Comment, v.InlineComment),
+			})
 		}
 	}
+	return e
+}
 
-	// 处理最后一个方法
-	if currentMethod != nil {
-		methods = append(methods, *currentMethod)
+// commentText 合并前置注释和行尾注释，每行去掉首尾空白
+func commentText(comments ...*proto.Comment) string {
+	var lines []string
+	for _, c := range comments {
+		if c == nil {
+			continue
+		}
+		for _, line := range c.Lines {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
 	}
+	return strings.Join(lines, "\n")
+}
+
+// flattenOption 展开选项的聚合值
+func flattenOption(o *proto.Option) []Option {
+	return flattenLiteral(o.Name, &o.Constant)
+}
 
-	return methods
+func flattenLiteral(name string, l *proto.Literal) []Option {
+	switch {
+	case len(l.OrderedMap) > 0:
+		var options []Option
+		for _, nl := range l.OrderedMap {
+			options = append(options, flattenLiteral(name+"."+nl.Name, nl.Literal)...)
+		}
+		return options
+	case len(l.Array) > 0:
+		var options []Option
+		for _, item := range l.Array {
+			options = append(options, flattenLiteral(name, item)...)
+		}
+		return options
+	default:
+		return []Option{{Name: name, Value: l.Source}}
+	}
 }
 
 func inferHTTPMethod(methodName string) string {
@@ -245,28 +463,3 @@ func inferHTTPMethod(methodName string) string {
 		return "POST"
 	}
 }
-
-// parseHTTPAnnotation 解析 HTTP 注解，返回 HTTP 方法和路径
-func parseHTTPAnnotation(optionBody string) (string, string) {
-	// 匹配 google.api.http option 中的各种 HTTP 方法
-	// 支持 get, post, put, delete, patch 等
-	patterns := []struct {
-		method string
-		regex  *regexp.Regexp
-	}{
-		{"GET", regexp.MustCompile(`get:\s*"([^"]+)"`)},
-		{"POST", regexp.MustCompile(`post:\s*"([^"]+)"`)},
-		{"PUT", regexp.MustCompile(`put:\s*"([^"]+)"`)},
-		{"DELETE", regexp.MustCompile(`delete:\s*"([^"]+)"`)},
-		{"PATCH", regexp.MustCompile(`patch:\s*"([^"]+)"`)},
-	}
-
-	for _, p := range patterns {
-		matches := p.regex.FindStringSubmatch(optionBody)
-		if len(matches) > 1 {
-			return p.method, matches[1]
-		}
-	}
-
-	return "", ""
-}
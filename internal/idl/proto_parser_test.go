@@ -0,0 +1,187 @@
+package idl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderProto = `syntax = "proto3";
+
+package shop.order.v1;
+
+option go_package = "github.com/example/shop/proto;orderpb";
+option (hollow.doc) = "see http://x//y";
+
+import "google/api/annotations.proto";
+import "validate/validate.proto";
+
+// 订单状态
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  PAID = 1; // 已支付
+}
+
+// 订单
+message Order {
+  // 订单号
+  string id = 1;
+  repeated Item items = 2;
+  map<string, string> labels = 3;
+  optional string remark = 4 [(validate.rules).string = {max_len: 200, min_len: 1}];
+  oneof payer {
+    string user_id = 5;
+    string company_id = 6;
+  }
+  Status status = 7;
+
+  // 订单项
+  message Item {
+    string sku = 1;
+    int32 count = 2 [(validate.rules).int32.gt = 0];
+    enum Kind { KIND_UNSPECIFIED = 0; }
+  }
+}
+
+message GetOrderRequest { string id = 1; }
+
+/* 订单服务，注释里的 service Fake { } 不应被解析 */
+service OrderService {
+  // 获取订单
+  rpc GetOrder (GetOrderRequest)
+      returns (Order) {
+    option (google.api.http) = {
+      get: "/v1/orders/{id}" // 行尾注释
+    };
+  }
+
+  // 监听订单变化
+  rpc WatchOrders (stream GetOrderRequest) returns (stream Order);
+
+  rpc Echo (GetOrderRequest) returns (Order) {
+    option (google.api.http) = { post: "/v1/echo" body: "*" };
+  }
+}
+
+service AdminService {
+  rpc RemoveOrder (GetOrderRequest) returns (Order);
+}
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse(strings.NewReader(orderProto), "order.proto")
+	require.NoError(t, err)
+
+	assert.Equal(t, "proto3", file.Syntax)
+	assert.Equal(t, "shop.order.v1", file.Package)
+	assert.Equal(t, "github.com/example/shop/proto;orderpb", file.GoPackage)
+	assert.Equal(t, "orderpb", file.GoPackageName())
+	assert.Equal(t, []string{"google/api/annotations.proto", "validate/validate.proto"}, file.Imports)
+
+	require.Len(t, file.Services, 2)
+	svc := file.Service("OrderService")
+	require.NotNil(t, svc)
+	assert.Equal(t, "订单服务，注释里的 service Fake { } 不应被解析", svc.Comment)
+	require.Len(t, svc.Methods, 3)
+
+	get := svc.Methods[0]
+	assert.Equal(t, "GetOrder", get.Name)
+	assert.Equal(t, "获取订单", get.Comment)
+	assert.Equal(t, "GetOrderRequest", get.RequestType)
+	assert.Equal(t, "Order", get.ResponseType)
+	assert.Equal(t, "GET", get.HTTPMethod)
+	assert.Equal(t, "/v1/orders/{id}", get.Path)
+	path, ok := get.Option("(google.api.http).get")
+	assert.True(t, ok)
+	assert.Equal(t, "/v1/orders/{id}", path)
+
+	// 没有注解时根据方法名推断，客户端流总是 GET
+	watch := svc.Methods[1]
+	assert.Equal(t, "WatchOrders", watch.Name)
+	assert.True(t, watch.ClientStreaming)
+	assert.True(t, watch.ServerStreaming)
+	assert.Equal(t, "GET", watch.HTTPMethod)
+	assert.Equal(t, "/watchorders", watch.Path)
+	assert.False(t, get.ClientStreaming || get.ServerStreaming)
+
+	assert.Equal(t, "/v1/echo", svc.Methods[2].Path)
+	assert.Equal(t, "JSON", svc.Methods[2].BindingType)
+	assert.Equal(t, "Query", get.BindingType)
+
+	// 字符串中的 // 不是注释
+	assert.Contains(t, file.Options, Option{Name: "(hollow.doc)", Value: "see http://x//y"})
+
+	remove := file.Service("AdminService").Methods[0]
+	assert.Equal(t, "DELETE", remove.HTTPMethod)
+}
+
+func TestParseMessages(t *testing.T) {
+	file, err := Parse(strings.NewReader(orderProto), "order.proto")
+	require.NoError(t, err)
+
+	require.Len(t, file.Messages, 2)
+	order := file.Message("shop.order.v1.Order")
+	require.NotNil(t, order)
+	assert.Same(t, order, file.Message(".shop.order.v1.Order"))
+	assert.Equal(t, "订单", order.Comment)
+	require.Len(t, order.Fields, 7)
+
+	assert.Equal(t, Field{Name: "id", Type: "string", Number: 1, Comment: "订单号"}, order.Fields[0])
+	assert.True(t, order.Field("items").Repeated)
+	assert.Equal(t, "string", order.Field("labels").MapKey)
+	assert.Equal(t, "payer", order.Field("company_id").Oneof)
+	assert.False(t, order.Field("status").IsScalar())
+
+	remark := order.Field("remark")
+	assert.True(t, remark.Optional)
+	assert.Equal(t, []Option{
+		{Name: "(validate.rules).string.max_len", Value: "200"},
+		{Name: "(validate.rules).string.min_len", Value: "1"},
+	}, remark.Options)
+
+	item := file.Message("Item")
+	require.NotNil(t, item)
+	assert.Equal(t, "Order.Item", item.Name)
+	assert.Same(t, item, order.Messages[0])
+	gt, ok := item.Field("count").Option("(validate.rules).int32.gt")
+	assert.True(t, ok)
+	assert.Equal(t, "0", gt)
+
+	status := file.Enum("Status")
+	require.NotNil(t, status)
+	assert.Equal(t, []EnumValue{{Name: "STATUS_UNSPECIFIED"}, {Name: "PAID", Number: 1, Comment: "已支付"}}, status.Values)
+
+	// 嵌套枚举与嵌套消息一样可以用短名称查找
+	kind := file.Enum("Kind")
+	require.NotNil(t, kind)
+	assert.Equal(t, "Order.Item.Kind", kind.Name)
+	assert.Same(t, kind, file.Enum("Item.Kind"))
+
+	assert.Nil(t, file.Message("Missing"))
+}
+
+func TestParseProto(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "order.proto")
+	require.NoError(t, os.WriteFile(path, []byte(orderProto), 0644))
+
+	svc, err := ParseProto(path)
+	require.NoError(t, err)
+	assert.Equal(t, "OrderService", svc.Name)
+
+	require.NoError(t, os.WriteFile(path, []byte(`syntax = "proto3"; message A {}`), 0644))
+	_, err = ParseProto(path)
+	assert.EqualError(t, err, "no service definition found in proto file")
+
+	require.NoError(t, os.WriteFile(path, []byte(`syntax = "proto3"; message A {`), 0644))
+	_, err = ParseFile(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "order.proto")
+
+	_, err = ParseFile(filepath.Join(dir, "missing.proto"))
+	assert.Error(t, err)
+}
@@ -1,7 +1,6 @@
 package logger
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/vaynedu/hollow/internal/config"
@@ -10,43 +9,34 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var logger *zap.Logger
-
-// GetLogger 获取全局日志实例
-func GetLogger() *zap.Logger {
-	if logger == nil {
-		InitLogger(nil)
-	}
-	return logger
-}
-
-// InitLogger 初始化日志
+// InitLogger 根据配置创建日志实例
+// 每次调用都返回新的实例，不保存全局状态，由调用方（通常是 App）持有并传递
 func InitLogger(cfg *config.Config) (*zap.Logger, error) {
-	if cfg == nil {
-		cfg = &config.Config{
-			Log: config.LogConfig{},
-		}
+	// 复制一份日志配置，设置默认值时不修改调用方的配置
+	var logCfg config.LogConfig
+	if cfg != nil {
+		logCfg = cfg.Log
 	}
 
 	// 设置默认值
-	if cfg.Log.LogLevel == "" {
-		cfg.Log.LogLevel = "debug"
+	if logCfg.LogLevel == "" {
+		logCfg.LogLevel = "debug"
 	}
-	if cfg.Log.OutputMode == "" {
-		cfg.Log.OutputMode = "console"
+	if logCfg.OutputMode == "" {
+		logCfg.OutputMode = "console"
 	}
-	if cfg.Log.LogFileName == "" {
-		cfg.Log.LogFileName = "app.log"
+	if logCfg.LogFileName == "" {
+		logCfg.LogFileName = "app.log"
 	}
-	if cfg.Log.MaxSize == 0 {
-		cfg.Log.MaxSize = 100 // MB
+	if logCfg.MaxSize == 0 {
+		logCfg.MaxSize = 100 // MB
 	}
-	if cfg.Log.MaxAge == 0 {
-		cfg.Log.MaxAge = 30 // 天
+	if logCfg.MaxAge == 0 {
+		logCfg.MaxAge = 30 // 天
 	}
 
 	var level zapcore.Level
-	switch cfg.Log.LogLevel {
+	switch logCfg.LogLevel {
 	case "debug":
 		level = zap.DebugLevel
 	case "info":
@@ -71,19 +61,19 @@ func InitLogger(cfg *config.Config) (*zap.Logger, error) {
 		EncodeDuration: zapcore.SecondsDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
-	if cfg.Log.OutputMode == "console" {
+	if logCfg.OutputMode == "console" {
 		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	} else {
 		encoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
 	}
 
 	var core zapcore.Core
-	if cfg.Log.OutputMode == "file" {
+	if logCfg.OutputMode == "file" {
 		writer := &lumberjack.Logger{
-			Filename:   cfg.Log.LogFileName,
-			MaxSize:    cfg.Log.MaxSize,
+			Filename:   logCfg.LogFileName,
+			MaxSize:    logCfg.MaxSize,
 			MaxBackups: 3,
-			MaxAge:     cfg.Log.MaxAge,
+			MaxAge:     logCfg.MaxAge,
 			Compress:   false,
 		}
 		core = zapcore.NewCore(
@@ -100,66 +90,5 @@ func InitLogger(cfg *config.Config) (*zap.Logger, error) {
 		)
 	}
 
-	logger = zap.New(core, zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel))
-	return logger, nil
-}
-
-// Debug 打印 Debug 级别日志
-func Debug(msg string, fields ...zap.Field) {
-	GetLogger().Debug(msg, fields...)
-}
-
-// Info 打印 Info 级别日志
-func Info(msg string, fields ...zap.Field) {
-	GetLogger().Info(msg, fields...)
-}
-
-// Warn 打印 Warn 级别日志
-func Warn(msg string, fields ...zap.Field) {
-	GetLogger().Warn(msg, fields...)
-}
-
-// Error 打印 Error 级别日志
-func Error(msg string, fields ...zap.Field) {
-	GetLogger().Error(msg, fields...)
-}
-
-// Fatal 打印 Fatal 级别日志并退出
-func Fatal(msg string, fields ...zap.Field) {
-	GetLogger().Fatal(msg, fields...)
-}
-
-// Debugf 格式化打印 Debug 级别日志
-func Debugf(format string, args ...interface{}) {
-	GetLogger().Debug(fmt.Sprintf(format, args...))
-}
-
-// Infof 格式化打印 Info 级别日志
-func Infof(format string, args ...interface{}) {
-	GetLogger().Info(fmt.Sprintf(format, args...))
-}
-
-// Warnf 格式化打印 Warn 级别日志
-func Warnf(format string, args ...interface{}) {
-	GetLogger().Warn(fmt.Sprintf(format, args...))
-}
-
-// Errorf 格式化打印 Error 级别日志
-func Errorf(format string, args ...interface{}) {
-	GetLogger().Error(fmt.Sprintf(format, args...))
-}
-
-// Fatalf 格式化打印 Fatal 级别日志并退出
-func Fatalf(format string, args ...interface{}) {
-	GetLogger().Fatal(fmt.Sprintf(format, args...))
-}
-
-// WithFields 创建带字段的日志实例
-func WithFields(fields ...zap.Field) *zap.Logger {
-	return GetLogger().With(fields...)
-}
-
-// Sync 刷新日志缓冲区
-func Sync() error {
-	return GetLogger().Sync()
+	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel)), nil
 }
@@ -57,35 +57,30 @@ func TestInitLogger(t *testing.T) {
 	}
 }
 
-func TestGetLogger(t *testing.T) {
-	// Reset logger to nil for testing
-	logger = nil
-	
-	log := GetLogger()
-	assert.NotNil(t, log)
-}
-
-func TestLogFunctions(t *testing.T) {
-	// Initialize logger
-	InitLogger(nil)
+func TestInitLoggerIndependent(t *testing.T) {
+	cfg := &config.Config{}
 
-	// Test formatted logging functions
-	Debugf("test debug: %s", "message")
-	Infof("test info: %s", "message")
-	Warnf("test warn: %s", "message")
-	Errorf("test error: %s", "message")
+	log1, err := InitLogger(cfg)
+	assert.NoError(t, err)
+	log2, err := InitLogger(&config.Config{Log: config.LogConfig{LogLevel: "error"}})
+	assert.NoError(t, err)
 
-	// Test logging with fields
-	Debug("debug message", zap.String("key", "value"))
-	Info("info message", zap.String("key", "value"))
-	Warn("warn message", zap.String("key", "value"))
-	Error("error message", zap.String("key", "value"))
+	// 每次调用返回独立的实例，互不影响
+	assert.NotSame(t, log1, log2)
+	assert.True(t, log1.Core().Enabled(zap.DebugLevel))
+	assert.False(t, log2.Core().Enabled(zap.WarnLevel))
+	// 设置默认值时不修改调用方的配置
+	assert.Empty(t, cfg.Log.LogLevel)
 }
 
-func TestWithFields(t *testing.T) {
-	InitLogger(nil)
-	
-	log := WithFields(zap.String("request_id", "12345"))
-	assert.NotNil(t, log)
-	log.Info("test with fields")
+func TestNewObserved(t *testing.T) {
+	log, logs := NewObserved(zap.InfoLevel)
+
+	log.Debug("debug message")
+	log.With(zap.String("request_id", "12345")).Info("info message")
+
+	assert.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "info message", entry.Message)
+	assert.Equal(t, "12345", entry.ContextMap()["request_id"])
 }
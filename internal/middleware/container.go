@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/hollow/pkg/hdi"
+	"go.uber.org/zap"
+)
+
+// ContainerMiddleware 实现Middleware接口的依赖注入中间件
+// 每个请求创建一个依赖作用域，请求结束时释放作用域内的资源
+type ContainerMiddleware struct {
+	container *hdi.Container
+	logger    *zap.Logger
+}
+
+// NewContainerMiddleware 创建ContainerMiddleware实例
+func NewContainerMiddleware(container *hdi.Container, logger *zap.Logger) *ContainerMiddleware {
+	return &ContainerMiddleware{
+		container: container,
+		logger:    logger,
+	}
+}
+
+// HandlerFunc 返回中间件处理函数
+func (m *ContainerMiddleware) HandlerFunc() gin.HandlerFunc {
+	return m.containerMiddleware
+}
+
+// Identifier 返回中间件唯一标识
+func (m *ContainerMiddleware) Identifier() string {
+	return "container"
+}
+
+func (m *ContainerMiddleware) containerMiddleware(c *gin.Context) {
+	scope := m.container.Scope()
+	defer func() {
+		if err := scope.Close(c.Request.Context()); err != nil {
+			m.logger.Error("failed to close request scope", zap.Error(err))
+		}
+	}()
+
+	// 将作用域保存到 context 中，handler 和 service 通过 hdi.FromContext 获取
+	c.Request = c.Request.WithContext(hdi.NewContext(c.Request.Context(), scope))
+
+	c.Next()
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/hollow/pkg/hecode"
 	"go.uber.org/zap"
 	"time"
 )
@@ -36,12 +37,17 @@ func (m *LoggingMiddleware) loggingMiddleware(c *gin.Context) {
 	c.Next()
 
 	cost := time.Since(start)
-	m.logger.Info("HTTP Request",
+	fields := []zap.Field{
 		zap.String("method", c.Request.Method),
 		zap.String("path", path),
 		zap.String("query", query),
 		zap.Int("status", c.Writer.Status()),
 		zap.Duration("cost", cost),
 		zap.String("client_ip", c.ClientIP()),
-	)
+	}
+	// 记录业务错误的完整错误链
+	if err := c.Errors.Last(); err != nil {
+		fields = append(fields, hecode.ZapError(err.Err))
+	}
+	m.logger.Info("HTTP Request", fields...)
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/hollow/internal/logger"
+	"github.com/vaynedu/hollow/pkg/hdi"
 	"go.uber.org/zap"
 )
 
@@ -31,7 +33,7 @@ func TestMiddlewareInterface(t *testing.T) {
 		},
 		{
 			name:       "recovery middleware",
-			middleware: NewRecoveryMiddleware(),
+			middleware: NewRecoveryMiddleware(logger),
 			identifier: "recovery",
 		},
 		{
@@ -66,6 +68,27 @@ func TestRequestIDMiddleware(t *testing.T) {
 	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
 }
 
+func TestRequestIDMiddlewareTraceHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRequestIDMiddleware().HandlerFunc())
+	var requestID interface{}
+	var trace http.Header
+	router.GET("/test", func(c *gin.Context) {
+		requestID = c.Request.Context().Value(RequestIDKey)
+		trace, _ = c.Request.Context().Value(TraceHeadersKey).(http.Header)
+	})
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Custom", "ignored")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "req-1", requestID)
+	assert.Equal(t, http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}, trace)
+}
+
 func TestLoggingMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	logger := zap.NewNop()
@@ -85,9 +108,10 @@ func TestLoggingMiddleware(t *testing.T) {
 
 func TestRecoveryMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	log, logs := logger.NewObserved(zap.ErrorLevel)
 
 	router := gin.New()
-	router.Use(NewRecoveryMiddleware().HandlerFunc())
+	router.Use(NewRecoveryMiddleware(log).HandlerFunc())
 	router.GET("/panic", func(c *gin.Context) {
 		panic("test panic")
 	})
@@ -97,6 +121,10 @@ func TestRecoveryMiddleware(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, 500, w.Code)
+	// panic 日志写入传入的日志实例
+	entries := logs.FilterMessage("panic recovered").All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "test panic", entries[0].ContextMap()["error"])
 }
 
 func TestResponseMiddleware(t *testing.T) {
@@ -114,6 +142,15 @@ func TestResponseMiddleware(t *testing.T) {
 
 	assert.Equal(t, 200, w.Code)
 	assert.Contains(t, w.Body.String(), "success")
+
+	// handler 已经写出响应时不追加标准响应
+	router.GET("/stream", func(c *gin.Context) {
+		c.String(200, "data: 1\n\n")
+	})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/stream", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "data: 1\n\n", w.Body.String())
 }
 
 func TestRegisterDefaultMiddlewares(t *testing.T) {
@@ -126,3 +163,37 @@ func TestRegisterDefaultMiddlewares(t *testing.T) {
 	assert.Equal(t, "recovery", middlewares[2].Identifier())
 	assert.Equal(t, "response", middlewares[3].Identifier())
 }
+
+type scopedCloser struct{ closed bool }
+
+func (s *scopedCloser) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestContainerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	container := hdi.New()
+	hdi.MustProvide(container, func(r hdi.Resolver) (*scopedCloser, error) {
+		return &scopedCloser{}, nil
+	}, hdi.WithLifetime(hdi.Scoped))
+
+	var resolved *scopedCloser
+	router := gin.New()
+	router.Use(NewContainerMiddleware(container, zap.NewNop()).HandlerFunc())
+	router.GET("/test", func(c *gin.Context) {
+		var err error
+		resolved, err = hdi.ResolveFromContext[*scopedCloser](c.Request.Context())
+		assert.NoError(t, err)
+		c.String(200, "OK")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	// 请求结束后释放作用域内的资源
+	assert.NotNil(t, resolved)
+	assert.True(t, resolved.closed)
+}
@@ -5,7 +5,6 @@ import (
 	"runtime"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vaynedu/hollow/internal/logger"
 	"go.uber.org/zap"
 )
 
@@ -15,9 +14,9 @@ type RecoveryMiddleware struct {
 }
 
 // NewRecoveryMiddleware 创建RecoveryMiddleware实例
-func NewRecoveryMiddleware() *RecoveryMiddleware {
+func NewRecoveryMiddleware(logger *zap.Logger) *RecoveryMiddleware {
 	return &RecoveryMiddleware{
-		logger: logger.GetLogger(),
+		logger: logger,
 	}
 }
 
@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/hollow/pkg/hecode"
 	"github.com/vaynedu/hollow/pkg/hidgenerator"
 )
 
@@ -49,12 +52,33 @@ func responseMiddleware(c *gin.Context) {
 		c.Writer.Header().Set("X-Request-ID", requestID)
 	}
 
+	// handler 已经自行写出了响应（例如 SSE 或 WebSocket 流），不再追加标准响应
+	if c.Writer.Written() {
+		return
+	}
+
 	// 处理业务错误
 	if err := c.Errors.Last(); err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:      http.StatusInternalServerError,
-			Msg:       err.Err.Error(),
+		code, msg := http.StatusInternalServerError, err.Err.Error()
+		// 业务错误使用 hecode 的错误码，消息按 Accept-Language 本地化
+		var ec *hecode.EcodeError
+		if errors.As(err.Err, &ec) {
+			code, msg = ec.Code(), hecode.LocalizedMessage(ec, c.GetHeader("Accept-Language"))
+		}
+		// 错误详情放到 data.details 中返回给客户端
+		var data interface{}
+		if details := hecode.DetailsData(err.Err); len(details) > 0 {
+			data = gin.H{"details": details}
+		}
+		if retry, ok := hecode.DetailOf[hecode.RetryAfter](err.Err); ok {
+			c.Header("Retry-After", strconv.FormatInt(retry.Seconds(), 10))
+		}
+		// HTTP 状态码由错误码注册表决定
+		c.JSON(hecode.HTTPStatus(err.Err), Response{
+			Code:      code,
+			Msg:       msg,
 			RequestID: requestID,
+			Data:      data,
 		})
 		return
 	}
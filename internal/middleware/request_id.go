@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vaynedu/hollow/pkg/hidgenerator"
@@ -9,6 +10,16 @@ import (
 
 const RequestIDKey = "request_id"
 
+// TraceHeadersKey 上游请求的链路追踪请求头在 context 中的 key，值为 http.Header，hclient 调用下游服务时透传
+const TraceHeadersKey = "trace_headers"
+
+// TraceHeaders 需要透传的链路追踪请求头，包括 W3C Trace Context、B3 和 Jaeger
+var TraceHeaders = []string{
+	"traceparent", "tracestate", "baggage",
+	"b3", "X-B3-TraceId", "X-B3-SpanId", "X-B3-ParentSpanId", "X-B3-Sampled", "X-B3-Flags",
+	"uber-trace-id",
+}
+
 // RequestIDMiddleware 实现Middleware接口的请求ID中间件
 type RequestIDMiddleware struct{}
 
@@ -43,7 +54,25 @@ func (m *RequestIDMiddleware) requestIDMiddleware(c *gin.Context) {
 
 	// 将 request_id 保存到 context 中
 	ctx := context.WithValue(c.Request.Context(), RequestIDKey, requestID)
+	// 将链路追踪请求头保存到 context 中，业务代码用同一个 context 调用下游服务时透传
+	if trace := traceHeaders(c.Request.Header); len(trace) > 0 {
+		ctx = context.WithValue(ctx, TraceHeadersKey, trace)
+	}
 	c.Request = c.Request.WithContext(ctx)
 
 	c.Next()
 }
+
+// traceHeaders 从请求头中取出链路追踪请求头
+func traceHeaders(h http.Header) http.Header {
+	var trace http.Header
+	for _, key := range TraceHeaders {
+		if values := h.Values(key); len(values) > 0 {
+			if trace == nil {
+				trace = make(http.Header)
+			}
+			trace[http.CanonicalHeaderKey(key)] = values
+		}
+	}
+	return trace
+}
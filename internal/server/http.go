@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/vaynedu/hollow/internal/config"
+	"go.uber.org/zap"
+)
+
+// HTTPServer 对 http.Server 的封装，负责超时、连接数限制和优雅关闭
+type HTTPServer struct {
+	cfg      config.HTTPServerConfig
+	server   *http.Server
+	logger   *zap.Logger
+	listener *LimitListener
+}
+
+// NewHTTPServer 创建 HTTPServer 实例，cfg 需要已经填充默认值
+func NewHTTPServer(cfg config.HTTPServerConfig, handler http.Handler, logger *zap.Logger) *HTTPServer {
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ErrorLog:          zap.NewStdLog(logger),
+	}
+	srv.SetKeepAlivesEnabled(!cfg.DisableKeepAlive)
+
+	return &HTTPServer{
+		cfg:    cfg,
+		server: srv,
+		logger: logger,
+	}
+}
+
+// Server 返回底层的 http.Server
+func (s *HTTPServer) Server() *http.Server {
+	return s.server
+}
+
+// Listen 监听配置的地址
+func (s *HTTPServer) Listen() (net.Listener, error) {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return s.wrapListener(ln), nil
+}
+
+// Serve 在指定 Listener 上提供服务，正常关闭时返回 nil
+func (s *HTTPServer) Serve(ln net.Listener) error {
+	if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ListenAndServe 监听并提供服务，正常关闭时返回 nil
+func (s *HTTPServer) ListenAndServe() error {
+	ln, err := s.Listen()
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Shutdown 优雅关闭服务，最长等待 ShutdownTimeout
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// Stats 返回连接统计信息，未开启连接数限制时返回零值
+func (s *HTTPServer) Stats() ConnStats {
+	if s.listener == nil {
+		return ConnStats{}
+	}
+	return s.listener.Stats()
+}
+
+func (s *HTTPServer) wrapListener(ln net.Listener) net.Listener {
+	if tcpLn, ok := ln.(*net.TCPListener); ok && !s.cfg.DisableKeepAlive {
+		ln = keepAliveListener{TCPListener: tcpLn, period: s.cfg.KeepAlivePeriod}
+	}
+	if s.cfg.MaxConns > 0 {
+		s.listener = NewLimitListener(ln, s.cfg.MaxConns, s.onLimit)
+		ln = s.listener
+	}
+	return ln
+}
+
+func (s *HTTPServer) onLimit(stats ConnStats) {
+	s.logger.Warn("http connection limit reached",
+		zap.Int("max_conns", s.cfg.MaxConns),
+		zap.Int64("active", stats.Active),
+		zap.Int64("limit_reached_total", stats.LimitReached),
+	)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaynedu/hollow/internal/config"
+	"go.uber.org/zap"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+	cfg := config.HTTPServerConfig{Addr: "127.0.0.1:0"}.WithDefaults("")
+	srv := NewHTTPServer(cfg, http.NotFoundHandler(), zap.NewNop())
+
+	s := srv.Server()
+	assert.Equal(t, config.DefaultReadTimeout, s.ReadTimeout)
+	assert.Equal(t, config.DefaultReadHeaderTimeout, s.ReadHeaderTimeout)
+	assert.Equal(t, config.DefaultWriteTimeout, s.WriteTimeout)
+	assert.Equal(t, config.DefaultIdleTimeout, s.IdleTimeout)
+	assert.Equal(t, config.DefaultMaxHeaderBytes, s.MaxHeaderBytes)
+}
+
+func TestHTTPServerServeAndShutdown(t *testing.T) {
+	cfg := config.HTTPServerConfig{Addr: "127.0.0.1:0"}.WithDefaults("")
+	srv := NewHTTPServer(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), zap.NewNop())
+
+	ln, err := srv.Listen()
+	assert.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String())
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	assert.NoError(t, srv.Shutdown(context.Background()))
+	// 正常关闭时 Serve 返回 nil
+	assert.NoError(t, <-done)
+}
+
+func TestLimitListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	limited := make(chan ConnStats, 1)
+	l := NewLimitListener(ln, 1, func(stats ConnStats) {
+		limited <- stats
+	})
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	c1, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoError(t, err)
+	defer c1.Close()
+	conn1 := <-accepted
+	assert.Equal(t, int64(1), l.Stats().Active)
+
+	// 第二个连接需要等待第一个连接关闭
+	c2, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoError(t, err)
+	defer c2.Close()
+
+	select {
+	case stats := <-limited:
+		assert.Equal(t, int64(1), stats.LimitReached)
+	case <-time.After(time.Second):
+		t.Fatal("limit callback not called")
+	}
+	select {
+	case <-accepted:
+		t.Fatal("connection accepted over limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.NoError(t, conn1.Close())
+	select {
+	case conn2 := <-accepted:
+		conn2.Close()
+	case <-time.After(time.Second):
+		t.Fatal("connection not accepted after release")
+	}
+	assert.Equal(t, int64(2), l.Stats().Accepted)
+}
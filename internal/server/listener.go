@@ -0,0 +1,103 @@
+package server
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConnStats 连接统计信息
+type ConnStats struct {
+	Active       int64 // 当前活跃连接数
+	Accepted     int64 // 累计接受的连接数
+	LimitReached int64 // 累计达到连接上限的次数
+}
+
+// LimitListener 限制最大并发连接数的 Listener
+// 达到上限后 Accept 会阻塞，直到有连接关闭
+type LimitListener struct {
+	net.Listener
+	sem     chan struct{}
+	onLimit func(ConnStats)
+
+	active       atomic.Int64
+	accepted     atomic.Int64
+	limitReached atomic.Int64
+}
+
+// NewLimitListener 创建 LimitListener
+// onLimit 在连接数达到上限时回调，可用于打点或告警，为 nil 时忽略
+func NewLimitListener(l net.Listener, maxConns int, onLimit func(ConnStats)) *LimitListener {
+	return &LimitListener{
+		Listener: l,
+		sem:      make(chan struct{}, maxConns),
+		onLimit:  onLimit,
+	}
+}
+
+// Accept 获取连接槽位后接受新连接
+func (l *LimitListener) Accept() (net.Conn, error) {
+	select {
+	case l.sem <- struct{}{}:
+	default:
+		// 连接已满，记录指标后阻塞等待
+		l.limitReached.Add(1)
+		if l.onLimit != nil {
+			l.onLimit(l.Stats())
+		}
+		l.sem <- struct{}{}
+	}
+
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		<-l.sem
+		return nil, err
+	}
+	l.active.Add(1)
+	l.accepted.Add(1)
+	return &limitConn{Conn: conn, release: l.release}, nil
+}
+
+// Stats 返回连接统计信息
+func (l *LimitListener) Stats() ConnStats {
+	return ConnStats{
+		Active:       l.active.Load(),
+		Accepted:     l.accepted.Load(),
+		LimitReached: l.limitReached.Load(),
+	}
+}
+
+func (l *LimitListener) release() {
+	l.active.Add(-1)
+	<-l.sem
+}
+
+// limitConn 关闭时释放连接槽位
+type limitConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
+
+// keepAliveListener 为 TCP 连接设置 keep-alive 探测周期
+type keepAliveListener struct {
+	*net.TCPListener
+	period time.Duration
+}
+
+func (l keepAliveListener) Accept() (net.Conn, error) {
+	conn, err := l.AcceptTCP()
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetKeepAlive(true)
+	_ = conn.SetKeepAlivePeriod(l.period)
+	return conn, nil
+}
@@ -0,0 +1,392 @@
+// Package hbind 按 google.api.http 注解把 HTTP 请求绑定到 Protobuf 请求消息
+//
+// 绑定顺序与 grpc-gateway 一致：先解析请求体，再用路径变量覆盖对应字段，
+// 最后从查询参数绑定既不在路径中也不在请求体中的字段。hollow-cli 生成的 handler 调用 Bind，
+// 所有参数错误汇总为带 hecode.FieldViolation 详情的 hecode.ErrInvalidParam。
+package hbind
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"github.com/vaynedu/hollow/pkg/hvalidate"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Option 绑定选项，由生成的 handler 根据注解传入
+type Option func(*binder)
+
+// Body 请求体对应的字段，* 表示整个请求，不指定时不读取请求体
+func Body(field string) Option {
+	return func(b *binder) {
+		b.body = field
+	}
+}
+
+// Path 路径变量，field 为字段路径（例如 book.name），value 为由 gin 路径参数拼接出的值
+func Path(field, value string) Option {
+	return func(b *binder) {
+		b.paths = append(b.paths, pathValue{field: field, value: value})
+	}
+}
+
+// Rule 返回记录 HTTP 绑定规则的中间件，注册在 handler 之前，Bind 和 ResponseBody 按规则处理请求和响应。
+// path 为 google.api.http 的路径模板，body、responseBody 与注解中的含义相同，路径模板无效时 panic
+func Rule(path, body, responseBody string) gin.HandlerFunc {
+	t, err := ParsePathTemplate(path)
+	if err != nil {
+		panic(err)
+	}
+	r := &rule{template: t, body: body, responseBody: responseBody}
+	return func(c *gin.Context) {
+		c.Set(ruleKey, r)
+		c.Next()
+	}
+}
+
+// CatchAll 返回 gin 通配参数 *name 的值，去掉开头的 /
+func CatchAll(c *gin.Context, name string) string {
+	return strings.TrimPrefix(c.Param(name), "/")
+}
+
+var unmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+const ruleKey = "hbind.rule"
+
+type rule struct {
+	template     *PathTemplate
+	body         string
+	responseBody string
+	names        sync.Map // gin 路由的路径 -> 参数名映射
+}
+
+// paramNames 缓存 PathTemplate.ParamNames 的结果，同一个 Rule 可能注册在多个路由上
+func (r *rule) paramNames(fullPath string) map[string]string {
+	if names, ok := r.names.Load(fullPath); ok {
+		return names.(map[string]string)
+	}
+	names := r.template.ParamNames(fullPath)
+	r.names.Store(fullPath, names)
+	return names
+}
+
+type pathValue struct {
+	field string
+	value string
+}
+
+type binder struct {
+	body       string
+	paths      []pathValue
+	violations []hecode.Detail
+}
+
+// Bind 绑定请求到 req，规则来自 Rule 中间件和 opts，失败时返回 hecode.ErrInvalidParam，
+// 详情中的字段为请求消息中的字段路径。绑定成功后调用 hollow-cli 根据 proto 字段规则生成的 Validate 方法
+func Bind(c *gin.Context, req proto.Message, opts ...Option) error {
+	b := &binder{}
+	if r, ok := c.Get(ruleKey); ok {
+		r := r.(*rule)
+		b.body = r.body
+		names := r.paramNames(c.FullPath())
+		for _, v := range r.template.Vars {
+			b.paths = append(b.paths, pathValue{field: v.Field, value: v.value(c, names)})
+		}
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	msg := req.ProtoReflect()
+
+	if b.body != "" && c.Request.Body != nil {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return hecode.WithDetails(hecode.ErrInvalidParam, hecode.FieldViolation{Field: "body", Description: err.Error()})
+		}
+		b.bindBody(msg, data)
+	}
+
+	bound := make(map[string]bool)
+	for _, p := range b.paths {
+		bound[p.field] = true
+		if err := setField(msg, p.field, []string{p.value}); err != nil {
+			b.violate(p.field, err)
+		}
+	}
+
+	if b.body != "*" {
+		query := c.Request.URL.Query()
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			values := query[key]
+			field, ok := resolve(msg.Descriptor(), key)
+			if !ok || b.covered(field, bound) {
+				// 未知的查询参数忽略，例如前端加的时间戳
+				continue
+			}
+			if err := setField(msg, field, values); err != nil {
+				b.violate(key, err)
+			}
+		}
+	}
+
+	if len(b.violations) > 0 {
+		return hecode.WithDetails(hecode.ErrInvalidParam, b.violations...)
+	}
+	return hvalidate.Validate(req)
+}
+
+// ResponseBody 返回作为响应体的数据，规则指定了 response_body 时返回该字段的值，否则返回 resp
+func ResponseBody(c *gin.Context, resp proto.Message) interface{} {
+	r, ok := c.Get(ruleKey)
+	if !ok || r.(*rule).responseBody == "" || resp == nil {
+		return resp
+	}
+	msg := resp.ProtoReflect()
+	fd := lookup(msg.Descriptor(), r.(*rule).responseBody)
+	if fd == nil {
+		return resp
+	}
+	return goValue(fd, msg.Get(fd))
+}
+
+// goValue 将字段值转换为可以 JSON 序列化的 Go 值
+func goValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		items := make([]interface{}, list.Len())
+		for i := range items {
+			items[i] = scalarValue(fd, list.Get(i))
+		}
+		return items
+	case fd.IsMap():
+		m := make(map[string]interface{})
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			m[k.String()] = scalarValue(fd.MapValue(), v)
+			return true
+		})
+		return m
+	default:
+		return scalarValue(fd, v)
+	}
+}
+
+func scalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return v.Message().Interface()
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	default:
+		return v.Interface()
+	}
+}
+
+func (b *binder) violate(field string, err error) {
+	b.violations = append(b.violations, hecode.FieldViolation{Field: field, Description: err.Error()})
+}
+
+// bindBody 解析请求体，body 为字段时请求体是该字段的 JSON 值
+func (b *binder) bindBody(msg protoreflect.Message, data []byte) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return
+	}
+	field := "body"
+	if b.body != "*" {
+		field = b.body
+		wrapped, err := json.Marshal(map[string]json.RawMessage{b.body: data})
+		if err != nil {
+			b.violate(field, err)
+			return
+		}
+		data = wrapped
+	}
+	if err := unmarshalOptions.Unmarshal(data, msg.Interface()); err != nil {
+		b.violate(field, fmt.Errorf("invalid JSON: %s", strings.TrimPrefix(err.Error(), "proto: ")))
+	}
+}
+
+// covered 查询参数对应的字段已经由路径或者请求体绑定
+func (b *binder) covered(field string, bound map[string]bool) bool {
+	top, _, _ := strings.Cut(field, ".")
+	if top == b.body {
+		return true
+	}
+	for f := range bound {
+		if f == field || strings.HasPrefix(field, f+".") || strings.HasPrefix(f, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve 将查询参数名转换为字段路径，每一段可以是 proto 字段名或者 JSON 名
+func resolve(md protoreflect.MessageDescriptor, key string) (string, bool) {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if md == nil {
+			return "", false
+		}
+		fd := lookup(md, part)
+		if fd == nil {
+			return "", false
+		}
+		parts[i] = string(fd.Name())
+		md = fd.Message()
+	}
+	return strings.Join(parts, "."), true
+}
+
+func lookup(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := md.Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return fields.ByJSONName(name)
+}
+
+// setField 按字段路径赋值，中间的消息字段不存在时自动创建
+func setField(msg protoreflect.Message, path string, values []string) error {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		fd := lookup(msg.Descriptor(), part)
+		if fd == nil {
+			return fmt.Errorf("unknown field %q", part)
+		}
+		if i < len(parts)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return fmt.Errorf("field %q is not a message", part)
+			}
+			msg = msg.Mutable(fd).Message()
+			continue
+		}
+
+		switch {
+		case fd.IsMap():
+			return fmt.Errorf("map field is not supported")
+		case fd.IsList():
+			list := msg.Mutable(fd).List()
+			for _, s := range values {
+				for _, item := range splitList(s) {
+					v, err := parseValue(fd, list.NewElement, item)
+					if err != nil {
+						return err
+					}
+					list.Append(v)
+				}
+			}
+		default:
+			if len(values) == 0 {
+				return nil
+			}
+			v, err := parseValue(fd, func() protoreflect.Value { return msg.NewField(fd) }, values[0])
+			if err != nil {
+				return err
+			}
+			msg.Set(fd, v)
+		}
+	}
+	return nil
+}
+
+// splitList repeated 字段支持 ?tag=a&tag=b 和 ?tag=a,b 两种写法
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// parseValue 将字符串转换为字段类型的值，消息类型（例如 Timestamp、Duration、包装类型）按 JSON 字符串解析，
+// newMessage 创建消息类型的空值
+func parseValue(fd protoreflect.FieldDescriptor, newMessage func() protoreflect.Value, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid bool %q", s)
+		}
+		return protoreflect.ValueOfBool(b), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid int32 %q", s)
+		}
+		return protoreflect.ValueOfInt32(int32(n)), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid int64 %q", s)
+		}
+		return protoreflect.ValueOfInt64(n), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid uint32 %q", s)
+		}
+		return protoreflect.ValueOfUint32(uint32(n)), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid uint64 %q", s)
+		}
+		return protoreflect.ValueOfUint64(n), nil
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid float %q", s)
+		}
+		return protoreflect.ValueOfFloat32(float32(f)), nil
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid double %q", s)
+		}
+		return protoreflect.ValueOfFloat64(f), nil
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			if b, err = base64.URLEncoding.DecodeString(s); err != nil {
+				return protoreflect.Value{}, fmt.Errorf("invalid base64 %q", s)
+			}
+		}
+		return protoreflect.ValueOfBytes(b), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil || fd.Enum().Values().ByNumber(protoreflect.EnumNumber(n)) == nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid %s %q", fd.Enum().Name(), s)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		v := newMessage()
+		if err := unmarshalOptions.Unmarshal([]byte(strconv.Quote(s)), v.Message().Interface()); err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid %s %q", fd.Message().FullName(), s)
+		}
+		return v, nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+	}
+}
@@ -0,0 +1,302 @@
+package hbind
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+)
+
+// bookFile 测试用的消息定义，相当于
+//
+//	enum Status { DRAFT = 0; PUBLISHED = 1; }
+//	message Author { string name = 1; uint32 age = 2; }
+//	message Book { string name = 1; int64 id = 2; Status status = 3; repeated string tags = 4;
+//	  Author author = 5; bool draft = 6; double price = 7; google.protobuf.Duration ttl = 8; }
+//	message UpdateBookRequest { Book book = 1; string name = 2; bool validate_only = 3; repeated int32 ids = 4; }
+func bookFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+			JsonName: proto.String(jsonName(name)),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	const (
+		str  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		msg  = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+		enum = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+	)
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("book.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/duration.proto"},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("DRAFT"), Number: proto.Int32(0)},
+				{Name: proto.String("PUBLISHED"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Author"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, str, "", false),
+				field("age", 2, descriptorpb.FieldDescriptorProto_TYPE_UINT32, "", false),
+			}},
+			{Name: proto.String("Book"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, str, "", false),
+				field("id", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, "", false),
+				field("status", 3, enum, ".test.Status", false),
+				field("tags", 4, str, "", true),
+				field("author", 5, msg, ".test.Author", false),
+				field("draft", 6, descriptorpb.FieldDescriptorProto_TYPE_BOOL, "", false),
+				field("price", 7, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, "", false),
+				field("ttl", 8, msg, ".google.protobuf.Duration", false),
+			}},
+			{Name: proto.String("UpdateBookRequest"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("book", 1, msg, ".test.Book", false),
+				field("name", 2, str, "", false),
+				field("validate_only", 3, descriptorpb.FieldDescriptorProto_TYPE_BOOL, "", false),
+				field("ids", 4, descriptorpb.FieldDescriptorProto_TYPE_INT32, "", true),
+			}},
+		},
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	return fd
+}
+
+func jsonName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// bind 用 gin 处理请求并返回绑定后的请求消息
+func bind(t *testing.T, route, method, target, body string, opts func(c *gin.Context) []Option) (*dynamicpb.Message, error) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	md := bookFile(t).Messages().ByName("UpdateBookRequest")
+	req := dynamicpb.NewMessage(md)
+
+	var bindErr error
+	r := gin.New()
+	r.Handle(method, route, func(c *gin.Context) {
+		bindErr = Bind(c, req, opts(c)...)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	return req, bindErr
+}
+
+// get 按字段路径读取值
+func get(m protoreflect.Message, path string) protoreflect.Value {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		m = m.Get(m.Descriptor().Fields().ByName(protoreflect.Name(part))).Message()
+	}
+	return m.Get(m.Descriptor().Fields().ByName(protoreflect.Name(parts[len(parts)-1])))
+}
+
+func TestBindPathAndBody(t *testing.T) {
+	// PATCH /v1/{book.name=shelves/*/books/*} body: "book"
+	req, err := bind(t, "/v1/shelves/:book_name/books/:book_name2", http.MethodPatch,
+		"/v1/shelves/s1/books/b1?validate_only=true&book.price=9&name=top",
+		`{"name": "x", "id": "42", "status": "PUBLISHED", "tags": ["a"], "author": {"name": "ann"}, "unknown": 1}`,
+		func(c *gin.Context) []Option {
+			return []Option{Body("book"), Path("book.name", "shelves/"+c.Param("book_name")+"/books/"+c.Param("book_name2"))}
+		})
+	require.NoError(t, err)
+	m := req.ProtoReflect()
+
+	// 路径变量覆盖请求体中的字段
+	assert.Equal(t, "shelves/s1/books/b1", get(m, "book.name").String())
+	assert.Equal(t, int64(42), get(m, "book.id").Int())
+	assert.Equal(t, protoreflect.EnumNumber(1), get(m, "book.status").Enum())
+	assert.Equal(t, "ann", get(m, "book.author.name").String())
+	assert.Equal(t, 1, get(m, "book.tags").List().Len())
+	// 请求体字段中的查询参数被忽略，其他字段从查询参数绑定
+	assert.Equal(t, float64(0), get(m, "book.price").Float())
+	assert.True(t, get(m, "validate_only").Bool())
+	assert.Equal(t, "top", get(m, "name").String())
+}
+
+func TestBindQuery(t *testing.T) {
+	// GET /v1/books/{name}
+	req, err := bind(t, "/v1/books/:name", http.MethodGet,
+		"/v1/books/b1?ids=1,2&ids=3&book.status=1&book.tags=x&book.author.age=30&validateOnly=1&book.ttl=1.5s&name=override&_t=123",
+		"", func(c *gin.Context) []Option {
+			return []Option{Path("name", c.Param("name"))}
+		})
+	require.NoError(t, err)
+	m := req.ProtoReflect()
+
+	assert.Equal(t, "b1", get(m, "name").String())
+	ids := get(m, "ids").List()
+	require.Equal(t, 3, ids.Len())
+	assert.Equal(t, int32(3), int32(ids.Get(2).Int()))
+	assert.Equal(t, protoreflect.EnumNumber(1), get(m, "book.status").Enum())
+	assert.Equal(t, "x", get(m, "book.tags").List().Get(0).String())
+	assert.Equal(t, uint64(30), get(m, "book.author.age").Uint())
+	assert.True(t, get(m, "validate_only").Bool())
+	assert.Equal(t, int64(1), get(m, "book.ttl.seconds").Int())
+}
+
+func TestBindWholeBody(t *testing.T) {
+	// POST /v1/books body: "*"，查询参数不参与绑定
+	req, err := bind(t, "/v1/books", http.MethodPost, "/v1/books?name=q",
+		`{"name": "b", "book": {"price": 1.5}}`, func(c *gin.Context) []Option {
+			return []Option{Body("*")}
+		})
+	require.NoError(t, err)
+	m := req.ProtoReflect()
+	assert.Equal(t, "b", get(m, "name").String())
+	assert.Equal(t, 1.5, get(m, "book.price").Float())
+
+	// 空请求体
+	_, err = bind(t, "/v1/books", http.MethodPost, "/v1/books", "", func(c *gin.Context) []Option {
+		return []Option{Body("*")}
+	})
+	assert.NoError(t, err)
+}
+
+func TestBindInvalid(t *testing.T) {
+	_, err := bind(t, "/v1/books/:id", http.MethodPut, "/v1/books/abc?ids=x&book.status=DELETED&book.draft=maybe&book.author=1",
+		`{"name": 1}`, func(c *gin.Context) []Option {
+			return []Option{Body("*"), Path("book.id", c.Param("id"))}
+		})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, hecode.ErrInvalidParam)
+
+	var fields []string
+	for _, d := range hecode.Details(err) {
+		fields = append(fields, d.(hecode.FieldViolation).Field)
+	}
+	// body 为 * 时不读取查询参数
+	assert.Equal(t, []string{"body", "book.id"}, fields)
+
+	_, err = bind(t, "/v1/books", http.MethodGet, "/v1/books?ids=x&book.status=DELETED&book.draft=maybe&book.ttl=abc",
+		"", func(c *gin.Context) []Option { return nil })
+	fields = nil
+	for _, d := range hecode.Details(err) {
+		fields = append(fields, d.(hecode.FieldViolation).Field)
+	}
+	assert.Equal(t, []string{"book.draft", "book.status", "book.ttl", "ids"}, fields)
+	v, _ := hecode.DetailOf[hecode.FieldViolation](err)
+	assert.Equal(t, `invalid bool "maybe"`, v.Description)
+}
+
+// validatedRequest 模拟 hollow-cli 为带字段规则的消息生成的 Validate 方法
+type validatedRequest struct {
+	*dynamicpb.Message
+}
+
+func (r validatedRequest) Validate() error {
+	if r.Get(r.Descriptor().Fields().ByName("name")).String() == "" {
+		return hecode.WithDetails(hecode.ErrInvalidParam, hecode.FieldViolation{Field: "name", Description: "value is required"})
+	}
+	return nil
+}
+
+func TestBindValidate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	md := bookFile(t).Messages().ByName("UpdateBookRequest")
+	var errs []error
+	r := gin.New()
+	r.GET("/v1/books", func(c *gin.Context) {
+		errs = append(errs, Bind(c, validatedRequest{dynamicpb.NewMessage(md)}))
+	})
+	for _, target := range []string{"/v1/books?name=b1", "/v1/books", "/v1/books?ids=x"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	require.Len(t, errs, 3)
+
+	assert.NoError(t, errs[0])
+	// 绑定成功后校验
+	assert.ErrorIs(t, errs[1], hecode.ErrInvalidParam)
+	v, _ := hecode.DetailOf[hecode.FieldViolation](errs[1])
+	assert.Equal(t, "name", v.Field)
+	// 绑定失败时不再校验
+	v, _ = hecode.DetailOf[hecode.FieldViolation](errs[2])
+	assert.Equal(t, "ids", v.Field)
+}
+
+func TestCatchAll(t *testing.T) {
+	req, err := bind(t, "/v1/files/*name", http.MethodGet, "/v1/files/a/b/c.txt", "", func(c *gin.Context) []Option {
+		return []Option{Path("name", CatchAll(c, "name"))}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a/b/c.txt", get(req.ProtoReflect(), "name").String())
+}
+
+func TestRule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	md := bookFile(t).Messages().ByName("UpdateBookRequest")
+
+	var req *dynamicpb.Message
+	var data interface{}
+	r := gin.New()
+	r.PATCH("/v1/shelves/:book_name/books/:book_name2", Rule("/v1/{book.name=shelves/*/books/*}", "book", "book"), func(c *gin.Context) {
+		req = dynamicpb.NewMessage(md)
+		if err := Bind(c, req); err != nil {
+			c.Error(err)
+			return
+		}
+		data = ResponseBody(c, req)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/v1/shelves/s1/books/b1?name=top", strings.NewReader(`{"id": 7}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	m := req.ProtoReflect()
+	assert.Equal(t, "shelves/s1/books/b1", get(m, "book.name").String())
+	assert.Equal(t, int64(7), get(m, "book.id").Int())
+	assert.Equal(t, "top", get(m, "name").String())
+
+	// response_body 为 book 时只返回 book 字段
+	book, ok := data.(proto.Message)
+	require.True(t, ok)
+	assert.Equal(t, "Book", string(book.ProtoReflect().Descriptor().Name()))
+
+	// 与其他路由共用 gin 参数名时按路由的路径对应回字段
+	r.GET("/v1/shelves/:book_name/info", Rule("/v1/shelves/{name}/info", "", ""), func(c *gin.Context) {
+		req = dynamicpb.NewMessage(md)
+		if err := Bind(c, req); err != nil {
+			c.Error(err)
+		}
+	})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/shelves/s2/info", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "s2", get(req.ProtoReflect(), "name").String())
+	assert.False(t, req.ProtoReflect().Has(md.Fields().ByName("book")))
+
+	assert.Panics(t, func() { Rule("users", "", "") })
+}
@@ -0,0 +1,336 @@
+package hbind
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PathTemplate 解析后的路径模板，语法为
+//
+//	Template = "/" Segments [ Verb ]
+//	Segment  = "*" | "**" | LITERAL | Variable
+//	Variable = "{" FieldPath [ "=" Segments ] "}"
+//	Verb     = ":" LITERAL
+type PathTemplate struct {
+	Segments []PathSegment
+	Vars     []PathVar
+	Verb     string
+}
+
+// PathSegment 路径段，Param 不为空时为通配符，对应 gin 的路径参数
+type PathSegment struct {
+	Literal  string
+	Param    string
+	CatchAll bool // ** 匹配剩余的所有路径段
+}
+
+// PathVar 路径变量，值由 Segments 拼接而成，例如 {name=users/*} 的值为 users/ 加上路径参数
+type PathVar struct {
+	Field    string // 字段路径，例如 book.name
+	Segments []PathSegment
+}
+
+// ParsePathTemplate 解析路径模板
+func ParsePathTemplate(path string) (*PathTemplate, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("路径模板 %q 必须以 / 开头", path)
+	}
+	t := &PathTemplate{}
+	rest := path[1:]
+	// 动词在最后一个路径段的 : 之后，变量中的 : 不是动词
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.Contains(rest[i:], "}") && !strings.Contains(rest[i:], "/") {
+		rest, t.Verb = rest[:i], rest[i+1:]
+		if t.Verb == "" {
+			return nil, fmt.Errorf("路径模板 %q 的动词为空", path)
+		}
+	}
+
+	wildcards := 0
+	for rest != "" {
+		var segment string
+		if strings.HasPrefix(rest, "{") {
+			end := strings.Index(rest, "}")
+			if end < 0 {
+				return nil, fmt.Errorf("路径模板 %q 的变量没有闭合", path)
+			}
+			segment, rest = rest[:end+1], rest[end+1:]
+			if err := t.addVar(segment[1:end]); err != nil {
+				return nil, fmt.Errorf("路径模板 %q: %w", path, err)
+			}
+		} else {
+			end := strings.Index(rest, "/")
+			if end < 0 {
+				end = len(rest)
+			}
+			segment, rest = rest[:end], rest[end:]
+			switch {
+			case segment == "":
+				return nil, fmt.Errorf("路径模板 %q 包含空的路径段", path)
+			case strings.ContainsAny(segment, "{}=:"):
+				return nil, fmt.Errorf("路径模板 %q 包含无效的路径段 %q", path, segment)
+			case segment == "*" || segment == "**":
+				// 匿名通配符匹配的值会被忽略
+				wildcards++
+				t.Segments = append(t.Segments, PathSegment{Param: "_" + strconv.Itoa(wildcards), CatchAll: segment == "**"})
+			default:
+				t.Segments = append(t.Segments, PathSegment{Literal: segment})
+			}
+		}
+		if rest != "" {
+			if rest[0] != '/' || len(rest) == 1 {
+				return nil, fmt.Errorf("路径模板 %q 的路径段 %q 之后缺少 /", path, segment)
+			}
+			rest = rest[1:]
+		}
+	}
+
+	for i, s := range t.Segments {
+		if s.CatchAll && i != len(t.Segments)-1 {
+			return nil, fmt.Errorf("路径模板 %q 中 ** 只能出现在最后", path)
+		}
+	}
+	return t, nil
+}
+
+// addVar 解析 FieldPath [ "=" Segments ]，变量中的每个通配符对应一个路径参数，
+// 参数名为字段路径中的 . 替换为 _，有多个通配符时从第二个开始加序号
+func (t *PathTemplate) addVar(s string) error {
+	field, pattern, ok := strings.Cut(s, "=")
+	if !ok {
+		pattern = "*"
+	}
+	for _, part := range strings.Split(field, ".") {
+		if !isIdent(part) {
+			return fmt.Errorf("无效的字段路径 %q", field)
+		}
+	}
+	for _, v := range t.Vars {
+		if v.Field == field {
+			return fmt.Errorf("字段 %s 重复绑定", field)
+		}
+	}
+
+	v := PathVar{Field: field}
+	param := strings.ReplaceAll(field, ".", "_")
+	n := 0
+	for _, segment := range strings.Split(pattern, "/") {
+		switch segment {
+		case "":
+			return fmt.Errorf("变量 %s 包含空的路径段", field)
+		case "*", "**":
+			n++
+			name := param
+			if n > 1 {
+				name += strconv.Itoa(n)
+			}
+			v.Segments = append(v.Segments, PathSegment{Param: name, CatchAll: segment == "**"})
+		default:
+			if strings.ContainsAny(segment, "{}=:") {
+				return fmt.Errorf("变量 %s 包含无效的路径段 %q", field, segment)
+			}
+			v.Segments = append(v.Segments, PathSegment{Literal: segment})
+		}
+	}
+	if n == 0 {
+		return fmt.Errorf("变量 %s 没有通配符", field)
+	}
+	t.Vars = append(t.Vars, v)
+	t.Segments = append(t.Segments, v.Segments...)
+	return nil
+}
+
+// GinPath 转换为 gin 的路由，例如 /v1/{name=users/*}/books/{id} 转换为 /v1/users/:name/books/:id，
+// ** 转换为 *name；gin 不支持路径段中的字面量冒号，带动词的模板返回错误
+func (t *PathTemplate) GinPath() (string, error) {
+	if t.Verb != "" {
+		return "", fmt.Errorf("gin 路由不支持自定义动词 :%s", t.Verb)
+	}
+	var b strings.Builder
+	for _, s := range t.Segments {
+		b.WriteByte('/')
+		b.WriteString(s.gin())
+	}
+	if b.Len() == 0 {
+		return "/", nil
+	}
+	return b.String(), nil
+}
+
+func (s PathSegment) gin() string {
+	switch {
+	case s.Param == "":
+		return s.Literal
+	case s.CatchAll:
+		return "*" + s.Param
+	default:
+		return ":" + s.Param
+	}
+}
+
+// Fields 路径中绑定的字段
+func (t *PathTemplate) Fields() []string {
+	fields := make([]string, len(t.Vars))
+	for i, v := range t.Vars {
+		fields[i] = v.Field
+	}
+	return fields
+}
+
+// Value 由 gin 的路径参数拼接出变量的值
+func (v PathVar) Value(c *gin.Context) string {
+	return v.value(c, nil)
+}
+
+// value 与 Value 相同，names 为模板中的参数名到 gin 路由中参数名的映射，不在映射中的参数使用原名
+func (v PathVar) value(c *gin.Context, names map[string]string) string {
+	parts := make([]string, len(v.Segments))
+	for i, s := range v.Segments {
+		param := s.Param
+		if name, ok := names[param]; ok {
+			param = name
+		}
+		switch {
+		case s.Param == "":
+			parts[i] = s.Literal
+		case s.CatchAll:
+			parts[i] = CatchAll(c, param)
+		default:
+			parts[i] = c.Param(param)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// ParamNames 按 gin 路由的路径逐段对应出模板中的参数名在路由中的参数名，
+// 同一位置的参数在 gin 中必须同名，生成的路由可能使用其他路由的参数名。
+// 路径段数不同或者参数名与模板相同时返回 nil
+func (t *PathTemplate) ParamNames(ginPath string) map[string]string {
+	segments := strings.Split(strings.TrimPrefix(ginPath, "/"), "/")
+	if len(segments) != len(t.Segments) {
+		return nil
+	}
+	var names map[string]string
+	for i, s := range t.Segments {
+		if s.Param == "" || len(segments[i]) < 2 || (segments[i][0] != ':' && segments[i][0] != '*') {
+			continue
+		}
+		if name := segments[i][1:]; name != s.Param {
+			if names == nil {
+				names = make(map[string]string)
+			}
+			names[s.Param] = name
+		}
+	}
+	return names
+}
+
+// Expand 用字段的值替换路径模板中的变量生成请求路径，与 Value 相反，客户端按注解调用接口时使用。
+// 只有一个 * 的变量整体转义为一个路径段，其他变量的值按模式分段后逐段转义；
+// 值与模式不匹配、模板中有匿名通配符时返回错误
+func (t *PathTemplate) Expand(value func(field string) string) (string, error) {
+	var b strings.Builder
+	vars := t.Vars
+	for i := 0; i < len(t.Segments); {
+		s := t.Segments[i]
+		if len(vars) > 0 && hasPrefix(t.Segments[i:], vars[0].Segments) {
+			v := vars[0]
+			parts, err := v.split(value(v.Field))
+			if err != nil {
+				return "", err
+			}
+			for _, part := range parts {
+				b.WriteByte('/')
+				b.WriteString(part)
+			}
+			i += len(v.Segments)
+			vars = vars[1:]
+			continue
+		}
+		if s.Param != "" {
+			return "", fmt.Errorf("路径模板中的匿名通配符没有对应的字段")
+		}
+		b.WriteByte('/')
+		b.WriteString(s.Literal)
+		i++
+	}
+	if b.Len() == 0 {
+		b.WriteByte('/')
+	}
+	if t.Verb != "" {
+		b.WriteString(":" + t.Verb)
+	}
+	return b.String(), nil
+}
+
+// split 按变量的模式拆分并转义字段的值
+func (v PathVar) split(value string) ([]string, error) {
+	if len(v.Segments) == 1 && !v.Segments[0].CatchAll {
+		if value == "" {
+			return nil, fmt.Errorf("路径变量 %s 的值为空", v.Field)
+		}
+		return []string{url.PathEscape(value)}, nil
+	}
+
+	values := strings.Split(value, "/")
+	parts := make([]string, 0, len(values))
+	for i, s := range v.Segments {
+		if s.CatchAll {
+			// ** 匹配剩余的所有路径段
+			for _, rest := range values[min(i, len(values)):] {
+				parts = append(parts, url.PathEscape(rest))
+			}
+			return parts, nil
+		}
+		if i >= len(values) || values[i] == "" || (s.Param == "" && values[i] != s.Literal) {
+			return nil, fmt.Errorf("路径变量 %s 的值 %q 与模式 %s 不匹配", v.Field, value, v.pattern())
+		}
+		parts = append(parts, url.PathEscape(values[i]))
+	}
+	if len(values) != len(v.Segments) {
+		return nil, fmt.Errorf("路径变量 %s 的值 %q 与模式 %s 不匹配", v.Field, value, v.pattern())
+	}
+	return parts, nil
+}
+
+func (v PathVar) pattern() string {
+	parts := make([]string, len(v.Segments))
+	for i, s := range v.Segments {
+		switch {
+		case s.Param == "":
+			parts[i] = s.Literal
+		case s.CatchAll:
+			parts[i] = "**"
+		default:
+			parts[i] = "*"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func hasPrefix(segments, prefix []PathSegment) bool {
+	if len(segments) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if segments[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && (i == 0 || !('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package hbind
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathTemplate(t *testing.T) {
+	tests := []struct {
+		path   string
+		gin    string
+		fields []string
+	}{
+		{"/", "/", []string{}},
+		{"/v1/users", "/v1/users", []string{}},
+		{"/v1/users/{id}", "/v1/users/:id", []string{"id"}},
+		{"/v1/{name=users/*}", "/v1/users/:name", []string{"name"}},
+		{"/v1/{book.name=shelves/*/books/*}", "/v1/shelves/:book_name/books/:book_name2", []string{"book.name"}},
+		{"/v1/users/{user_id}/orders/{order_id}", "/v1/users/:user_id/orders/:order_id", []string{"user_id", "order_id"}},
+		{"/v1/files/{path=**}", "/v1/files/*path", []string{"path"}},
+		{"/v1/*/items", "/v1/:_1/items", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tmpl, err := ParsePathTemplate(tt.path)
+			require.NoError(t, err)
+			gin, err := tmpl.GinPath()
+			require.NoError(t, err)
+			assert.Equal(t, tt.gin, gin)
+			assert.Equal(t, tt.fields, tmpl.Fields())
+		})
+	}
+}
+
+func TestParsePathTemplateVerb(t *testing.T) {
+	tmpl, err := ParsePathTemplate("/v1/{name=operations/*}:cancel")
+	require.NoError(t, err)
+	assert.Equal(t, "cancel", tmpl.Verb)
+	assert.Equal(t, []string{"name"}, tmpl.Fields())
+	_, err = tmpl.GinPath()
+	assert.Error(t, err)
+}
+
+func TestParsePathTemplateInvalid(t *testing.T) {
+	for _, path := range []string{
+		"v1/users",
+		"/v1//users",
+		"/v1/users/",
+		"/v1/{id",
+		"/v1/{id}/{id}",
+		"/v1/{1d}",
+		"/v1/{name=users}",
+		"/v1/{name=users//*}",
+		"/v1/**/items",
+		"/v1/a:b/c",
+		"/v1/users:",
+		"/v1/{id}x",
+	} {
+		_, err := ParsePathTemplate(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestPathTemplateExpand(t *testing.T) {
+	values := map[string]string{
+		"id":        "a/b c",
+		"name":      "shelves/1/books/2",
+		"book.name": "shelves/1/books/2",
+		"path":      "docs/a b/readme.md",
+	}
+	value := func(field string) string { return values[field] }
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "/"},
+		{"/v1/users/{id}", "/v1/users/a%2Fb%20c"},
+		{"/v1/{name=shelves/*/books/*}", "/v1/shelves/1/books/2"},
+		{"/v1/{book.name=shelves/*/books/*}:publish", "/v1/shelves/1/books/2:publish"},
+		{"/v1/files/{path=**}", "/v1/files/docs/a%20b/readme.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tmpl, err := ParsePathTemplate(tt.path)
+			require.NoError(t, err)
+			got, err := tmpl.Expand(value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, path := range []string{"/v1/{name=users/*}", "/v1/{name=shelves/*}", "/v1/*/items", "/v1/users/{missing}"} {
+		tmpl, err := ParsePathTemplate(path)
+		require.NoError(t, err)
+		_, err = tmpl.Expand(value)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestPathTemplateParamNames(t *testing.T) {
+	tmpl, err := ParsePathTemplate("/v1/{name=users/*}/books/{id=**}")
+	require.NoError(t, err)
+	assert.Nil(t, tmpl.ParamNames("/v1/users/:name/books/*id"))
+	assert.Equal(t, map[string]string{"name": "id", "id": "path"}, tmpl.ParamNames("/v1/users/:id/books/*path"))
+	// 不是由模板生成的路由按原名取参数
+	assert.Nil(t, tmpl.ParamNames("/v1/users/:id"))
+}
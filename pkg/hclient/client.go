@@ -0,0 +1,542 @@
+// Package hclient 调用 hollow 服务的 HTTP 客户端，hollow-cli proto --client 生成的类型化客户端基于它发送请求
+//
+// 请求按 google.api.http 注解构造：路径变量由请求字段替换，未绑定到路径和请求体的字段作为查询参数，
+// 请求体使用 protojson 编码。响应解开 {code, msg, request_id, data} 格式，code 不是成功时还原为 hecode 错误，
+// 错误码和 data.details 与服务端一致，可以直接使用 hecode.IsError 和生成的 IsXxx 函数判断。
+// 幂等方法（GET、HEAD、OPTIONS、PUT、DELETE）在网络错误、网关错误和可重试的错误码时按指数退避自动重试。
+// context 中的 request_id 和链路追踪请求头透传给下游服务，业务代码把 handler 的 context 传入即可。
+package hclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/go-resty/resty/v2"
+	"github.com/gorilla/websocket"
+	"github.com/vaynedu/hollow/internal/middleware"
+	"github.com/vaynedu/hollow/pkg/hbind"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"github.com/vaynedu/hollow/pkg/hidgenerator"
+	"github.com/vaynedu/hollow/pkg/hresty"
+	"github.com/vaynedu/hollow/pkg/hvalidate"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Client 调用一个 hollow 服务的 HTTP 客户端，可以在多个生成的服务客户端之间共享
+type Client struct {
+	resty    *resty.Client
+	dialer   *websocket.Dialer
+	header   http.Header
+	attempts uint
+	delay    time.Duration
+	maxDelay time.Duration
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithRestyClient 使用自定义的 resty 客户端，默认为 hresty.NewRestyClient
+func WithRestyClient(rc *resty.Client) Option {
+	return func(c *Client) {
+		c.resty = rc
+	}
+}
+
+// WithRetry 设置幂等方法的最大尝试次数和首次重试的等待时间，之后按指数退避，attempts 为 1 时不重试，默认尝试 3 次
+func WithRetry(attempts uint, delay time.Duration) Option {
+	return func(c *Client) {
+		c.attempts = attempts
+		c.delay = delay
+	}
+}
+
+// WithHeader 设置每个请求都附带的请求头，例如鉴权信息
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Set(key, value)
+	}
+}
+
+// WithDialer 设置流式方法建立 WebSocket 连接使用的 Dialer
+func WithDialer(d *websocket.Dialer) Option {
+	return func(c *Client) {
+		c.dialer = d
+	}
+}
+
+// New 创建客户端，baseURL 为服务地址，例如 http://user-service:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		dialer:   websocket.DefaultDialer,
+		header:   make(http.Header),
+		attempts: 3,
+		delay:    100 * time.Millisecond,
+		maxDelay: 2 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.resty == nil {
+		c.resty = hresty.NewRestyClient()
+	}
+	c.resty.SetBaseURL(strings.TrimRight(baseURL, "/"))
+	return c
+}
+
+// Rule rpc 的 HTTP 绑定，字段与 google.api.http 注解相同，由生成的客户端传入
+type Rule struct {
+	Method       string
+	Path         string // 路径模板，例如 /v1/{name=users/*}
+	Body         string
+	ResponseBody string
+}
+
+// idempotent HTTP 语义上幂等的方法
+func (r Rule) idempotent() bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// CallOption 单次调用的选项
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	header     http.Header
+	idempotent bool
+	attempts   uint
+}
+
+// Header 设置本次调用的请求头
+func Header(key, value string) CallOption {
+	return func(o *callOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+// Idempotent 标记本次调用可以重试，用于请求中带有幂等键的 POST 等非幂等方法
+func Idempotent() CallOption {
+	return func(o *callOptions) {
+		o.idempotent = true
+	}
+}
+
+// Attempts 设置本次调用的最大尝试次数，1 表示不重试
+func Attempts(n uint) CallOption {
+	return func(o *callOptions) {
+		o.attempts = n
+	}
+}
+
+// Invoke 调用普通的 rpc，req 编码为请求，响应的 data 解码到 resp。
+// 请求在发送前调用生成的 Validate 方法，服务端返回的错误码还原为 hecode 错误
+func (c *Client) Invoke(ctx context.Context, rule Rule, req, resp proto.Message, opts ...CallOption) error {
+	o := c.callOptions(rule, opts)
+	r, err := c.newRequest(ctx, rule, req, o)
+	if err != nil {
+		return err
+	}
+
+	call := func() error {
+		httpResp, err := r.build().Execute(rule.Method, r.path)
+		if err != nil {
+			return transportError(ctx, err)
+		}
+		data, err := decodeEnvelope(httpResp.StatusCode(), httpResp.Header(), httpResp.Body())
+		if err != nil {
+			return err
+		}
+		return decodeData(data, rule.ResponseBody, resp)
+	}
+	if o.attempts <= 1 || !o.idempotent {
+		return call()
+	}
+	return retry.Do(call,
+		retry.Context(ctx),
+		retry.Attempts(o.attempts),
+		retry.Delay(c.delay),
+		retry.DelayType(c.retryDelay),
+		retry.RetryIf(retryable),
+		retry.LastErrorOnly(true),
+	)
+}
+
+func (c *Client) callOptions(rule Rule, opts []CallOption) *callOptions {
+	o := &callOptions{header: make(http.Header), idempotent: rule.idempotent(), attempts: c.attempts}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// request 构造好的请求，每次重试使用 build 创建新的 resty 请求
+type request struct {
+	client *Client
+	ctx    context.Context
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+func (r *request) build() *resty.Request {
+	req := r.client.resty.R().SetContext(r.ctx).SetHeaderMultiValues(r.header).SetHeader("Accept", "application/json")
+	if len(r.query) > 0 {
+		req.SetQueryParamsFromValues(r.query)
+	}
+	if r.body != nil {
+		req.SetHeader("Content-Type", "application/json").SetBody(r.body)
+	}
+	return req
+}
+
+// newRequest 按规则生成路径、查询参数、请求头和请求体，req 为 nil 时只生成请求头（WebSocket 握手）
+func (c *Client) newRequest(ctx context.Context, rule Rule, req proto.Message, o *callOptions) (*request, error) {
+	t, err := parseTemplate(rule.Path)
+	if err != nil {
+		return nil, err
+	}
+	r := &request{client: c, ctx: ctx, header: c.headers(ctx, o)}
+
+	var msg protoreflect.Message
+	if req != nil {
+		if err := hvalidate.Validate(req); err != nil {
+			return nil, err
+		}
+		msg = req.ProtoReflect()
+	}
+	r.path, err = t.Expand(func(field string) string {
+		if msg == nil {
+			return ""
+		}
+		return fieldString(msg, field)
+	})
+	if err != nil {
+		return nil, hecode.WithDetails(hecode.ErrInvalidParam, hecode.FieldViolation{Field: "path", Description: err.Error()})
+	}
+	if msg == nil {
+		return r, nil
+	}
+
+	if rule.Body != "*" {
+		r.query = make(url.Values)
+		addQuery(r.query, "", msg, func(field string) bool {
+			top, _, _ := strings.Cut(field, ".")
+			if top == rule.Body {
+				return true
+			}
+			// 与 hbind 一样，路径中绑定的字段及其子字段不作为查询参数
+			for _, f := range t.Fields() {
+				if f == field || strings.HasPrefix(field, f+".") || strings.HasPrefix(f, field+".") {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if rule.Body != "" {
+		if r.body, err = encodeBody(msg, rule.Body); err != nil {
+			return nil, hecode.WithDetails(hecode.ErrInvalidParam, hecode.FieldViolation{Field: "body", Description: err.Error()})
+		}
+	}
+	return r, nil
+}
+
+// headers 合并客户端和本次调用的请求头，并透传 context 中的 request_id 和链路追踪请求头，
+// context 中没有 request_id 时生成一个，重试时使用同一个 request_id
+func (c *Client) headers(ctx context.Context, o *callOptions) http.Header {
+	h := c.header.Clone()
+	if trace, ok := ctx.Value(middleware.TraceHeadersKey).(http.Header); ok {
+		for k, v := range trace {
+			h[k] = v
+		}
+	}
+	for k, v := range o.header {
+		h[k] = v
+	}
+	if h.Get("X-Request-ID") == "" {
+		requestID, _ := ctx.Value(middleware.RequestIDKey).(string)
+		if requestID == "" {
+			requestID = hidgenerator.NewUuid().GenerateRequestID()
+		}
+		h.Set("X-Request-ID", requestID)
+	}
+	return h
+}
+
+var templates sync.Map
+
+// parseTemplate 解析并缓存路径模板
+func parseTemplate(path string) (*hbind.PathTemplate, error) {
+	if t, ok := templates.Load(path); ok {
+		return t.(*hbind.PathTemplate), nil
+	}
+	t, err := hbind.ParsePathTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+	templates.Store(path, t)
+	return t, nil
+}
+
+var marshalOptions = protojson.MarshalOptions{UseProtoNames: true}
+
+// encodeBody 编码请求体，body 为字段时请求体是该字段的 JSON 值，字段未设置时没有请求体
+func encodeBody(msg protoreflect.Message, body string) ([]byte, error) {
+	if body == "*" {
+		return marshalOptions.Marshal(msg.Interface())
+	}
+	fd := lookup(msg.Descriptor(), body)
+	if fd == nil {
+		return nil, fmt.Errorf("unknown field %q", body)
+	}
+	if !msg.Has(fd) {
+		return nil, nil
+	}
+	only := msg.New()
+	only.Set(fd, msg.Get(fd))
+	data, err := marshalOptions.Marshal(only.Interface())
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields[string(fd.Name())], nil
+}
+
+// addQuery 将已设置的字段编码为查询参数，嵌套消息的字段名为 a.b，skip 返回 true 的字段不编码。
+// 格式与 hbind 解析查询参数的方式一致，map 字段不支持作为查询参数
+func addQuery(q url.Values, prefix string, msg protoreflect.Message, skip func(field string) bool) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		key := prefix + string(fd.Name())
+		switch {
+		case skip(key), fd.IsMap():
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				if s, ok := formatValue(fd, list.Get(i)); ok {
+					q.Add(key, s)
+				}
+			}
+		case fd.Message() != nil && !wellKnown(fd.Message()):
+			addQuery(q, key+".", v.Message(), skip)
+		default:
+			if s, ok := formatValue(fd, v); ok {
+				q.Add(key, s)
+			}
+		}
+		return true
+	})
+}
+
+// fieldString 返回字段路径对应的值，用于替换路径变量，字段未设置时返回空字符串
+func fieldString(msg protoreflect.Message, path string) string {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		fd := lookup(msg.Descriptor(), part)
+		if fd == nil || fd.IsList() || fd.IsMap() {
+			return ""
+		}
+		if i < len(parts)-1 {
+			if fd.Message() == nil || !msg.Has(fd) {
+				return ""
+			}
+			msg = msg.Get(fd).Message()
+			continue
+		}
+		s, _ := formatValue(fd, msg.Get(fd))
+		return s
+	}
+	return ""
+}
+
+// formatValue 将字段值转换为字符串，与 hbind 中 parseValue 相反，普通消息类型不能转换
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (string, bool) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return v.String(), true
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes()), true
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), true
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name()), true
+		}
+		return strconv.Itoa(int(v.Enum())), true
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if !wellKnown(fd.Message()) {
+			return "", false
+		}
+		// Timestamp、Duration 等按 JSON 字符串编码
+		data, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return "", false
+		}
+		if s, err := strconv.Unquote(string(data)); err == nil {
+			return s, true
+		}
+		return string(data), true
+	default:
+		return v.String(), true
+	}
+}
+
+func wellKnown(md protoreflect.MessageDescriptor) bool {
+	return md.ParentFile() != nil && md.ParentFile().Package() == "google.protobuf"
+}
+
+func lookup(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := md.Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return fields.ByJSONName(name)
+}
+
+// envelope 服务端的标准响应
+type envelope struct {
+	Code      int             `json:"code"`
+	Msg       string          `json:"msg"`
+	RequestID string          `json:"request_id"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// succeeded response 中间件成功时为 200，hecode.Success 为 0
+func (e *envelope) succeeded() bool {
+	return e.Code == http.StatusOK || e.Code == 0
+}
+
+// err 将错误响应还原为 hecode 错误，data.details 还原为错误详情
+func (e *envelope) err() error {
+	err := hecode.FromCode(e.Code, e.Msg, nil)
+	var data struct {
+		Details []map[string]interface{} `json:"details"`
+	}
+	if len(e.Data) > 0 && json.Unmarshal(e.Data, &data) == nil && len(data.Details) > 0 {
+		err = hecode.WithDetails(err, hecode.DetailsFromData(data.Details)...)
+	}
+	return err
+}
+
+// decodeEnvelope 解开标准响应，返回 data；响应不是标准格式时（例如网关返回的 502）按 HTTP 状态码转换为错误
+func decodeEnvelope(status int, header http.Header, body []byte) (json.RawMessage, error) {
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil || env.Msg == "" {
+		return nil, statusError(status, header, body)
+	}
+	if !env.succeeded() {
+		return nil, env.err()
+	}
+	return env.Data, nil
+}
+
+// statusError 将非标准格式的响应按 HTTP 状态码转换为预定义的 hecode 错误，Retry-After 响应头转换为 RetryAfter 详情
+func statusError(status int, header http.Header, body []byte) error {
+	var base error
+	switch {
+	case status == http.StatusBadRequest:
+		base = hecode.ErrInvalidParam
+	case status == http.StatusUnauthorized:
+		base = hecode.ErrUnauthorized
+	case status == http.StatusForbidden:
+		base = hecode.ErrForbidden
+	case status == http.StatusNotFound:
+		base = hecode.ErrNotFound
+	case status == http.StatusConflict:
+		base = hecode.ErrAlreadyExists
+	case status == http.StatusTooManyRequests:
+		base = hecode.ErrResource
+	case status == http.StatusGatewayTimeout:
+		base = hecode.ErrTimeout
+	case status == http.StatusBadGateway || status == http.StatusServiceUnavailable:
+		base = hecode.ErrService
+	default:
+		base = hecode.ErrInternal
+	}
+	msg := fmt.Sprintf("unexpected response: HTTP %d", status)
+	if snippet := strings.TrimSpace(string(body)); snippet != "" {
+		if len(snippet) > 200 {
+			snippet = snippet[:200] + "..."
+		}
+		msg += ": " + snippet
+	}
+	err := hecode.FromCode(hecode.Code(base), msg, nil)
+	if seconds, perr := strconv.Atoi(header.Get("Retry-After")); perr == nil && seconds > 0 {
+		err = hecode.WithDetails(err, hecode.RetryAfter{Delay: time.Duration(seconds) * time.Second})
+	}
+	return err
+}
+
+// transportError 网络错误转换为 hecode.ErrNetwork，context 超时转换为 hecode.ErrTimeout，原始错误作为 cause
+func transportError(ctx context.Context, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return hecode.FromCode(hecode.Code(hecode.ErrTimeout), err.Error(), err)
+	}
+	return hecode.FromCode(hecode.Code(hecode.ErrNetwork), err.Error(), err)
+}
+
+// decodeData 将 data 解码到 resp，与 response 中间件一样使用 encoding/json；
+// responseBody 不为空时 data 是该字段的值，非消息类型的字段（例如枚举名称）使用 protojson 解码。
+// 解码失败时返回 hecode.ErrDataFormat
+func decodeData(data json.RawMessage, responseBody string, resp proto.Message) error {
+	if len(data) == 0 || string(data) == "null" || resp == nil {
+		return nil
+	}
+	var err error
+	fd := lookup(resp.ProtoReflect().Descriptor(), responseBody)
+	switch {
+	case responseBody == "" || fd == nil:
+		err = json.Unmarshal(data, resp)
+	case fd.Message() != nil && !wellKnown(fd.Message()):
+		err = json.Unmarshal(wrapField(fd, data), resp)
+	default:
+		err = unmarshalOptions.Unmarshal(wrapField(fd, data), resp)
+	}
+	if err != nil {
+		return hecode.FromCode(hecode.Code(hecode.ErrDataFormat), err.Error(), err)
+	}
+	return nil
+}
+
+// wrapField 将字段的值包装为只有该字段的消息
+func wrapField(fd protoreflect.FieldDescriptor, data json.RawMessage) []byte {
+	wrapped, _ := json.Marshal(map[string]json.RawMessage{string(fd.Name()): data})
+	return wrapped
+}
+
+var unmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// retryable context 取消或超时不重试，其他按错误码判断，网络和网关错误对应的错误码都是可重试的
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return hecode.IsRetryable(err)
+}
+
+// retryDelay 服务端指定了 Retry-After 时按指定的时间等待，否则指数退避，最长等待 maxDelay
+func (c *Client) retryDelay(n uint, err error, config *retry.Config) time.Duration {
+	if d, ok := hecode.DetailOf[hecode.RetryAfter](err); ok && d.Delay > 0 {
+		return d.Delay
+	}
+	return min(retry.BackOffDelay(n, err, config), c.maxDelay)
+}
@@ -0,0 +1,205 @@
+package hclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/hollow/internal/middleware"
+	"github.com/vaynedu/hollow/pkg/hbind"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+// newServer 启动与 App 相同中间件的服务，route 注册测试路由
+func newServer(t *testing.T, route func(r *gin.Engine)) *Client {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.NewRequestIDMiddleware().HandlerFunc(), middleware.NewResponseMiddleware().HandlerFunc())
+	route(r)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return New(srv.URL, WithRetry(3, time.Millisecond))
+}
+
+// handle 注册与生成的 handler 相同的路由：hbind 绑定请求，响应由 response 中间件封装
+func handle(r *gin.Engine, method, ginPath string, rule Rule, fn func(c *gin.Context, req *typepb.Field) (*typepb.Field, error)) {
+	r.Handle(method, ginPath, hbind.Rule(rule.Path, rule.Body, rule.ResponseBody), func(c *gin.Context) {
+		var req typepb.Field
+		if err := hbind.Bind(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+		resp, err := fn(c, &req)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Set("data", hbind.ResponseBody(c, resp))
+	})
+}
+
+func TestInvoke(t *testing.T) {
+	getRule := Rule{Method: http.MethodGet, Path: "/v1/{name=types/*/fields/*}"}
+	var header http.Header
+	var query url.Values
+	cc := newServer(t, func(r *gin.Engine) {
+		handle(r, http.MethodGet, "/v1/types/:name/fields/:name2", getRule, func(c *gin.Context, req *typepb.Field) (*typepb.Field, error) {
+			header, query = c.Request.Header.Clone(), c.Request.URL.Query()
+			return req, nil
+		})
+	})
+
+	// handler 的 context 中的 request_id 和链路追踪请求头透传给下游服务
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+	ctx = context.WithValue(ctx, middleware.TraceHeadersKey, http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}})
+	req := &typepb.Field{Name: "types/user/fields/a b", Number: 3, Kind: typepb.Field_TYPE_STRING, Packed: true}
+	resp := &typepb.Field{}
+	require.NoError(t, cc.Invoke(ctx, getRule, req, resp, Header("X-Tenant", "t1")))
+
+	assert.True(t, proto.Equal(req, resp), "resp = %v", resp)
+	assert.Equal(t, url.Values{"number": {"3"}, "kind": {"TYPE_STRING"}, "packed": {"true"}}, query)
+	assert.Equal(t, "req-1", header.Get("X-Request-ID"))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", header.Get("Traceparent"))
+	assert.Equal(t, "t1", header.Get("X-Tenant"))
+
+	// 路径变量与模板不匹配时不发送请求
+	err := cc.Invoke(ctx, getRule, &typepb.Field{Name: "fields/a"}, resp)
+	assert.True(t, hecode.IsError(err, hecode.ErrInvalidParam))
+}
+
+func TestInvokeBody(t *testing.T) {
+	patchRule := Rule{Method: http.MethodPatch, Path: "/v1/fields/{name}", Body: "options"}
+	postRule := Rule{Method: http.MethodPost, Path: "/v1/fields", Body: "*", ResponseBody: "kind"}
+	cc := newServer(t, func(r *gin.Engine) {
+		handle(r, http.MethodPatch, "/v1/fields/:name", patchRule, func(c *gin.Context, req *typepb.Field) (*typepb.Field, error) {
+			return req, nil
+		})
+		handle(r, http.MethodPost, "/v1/fields", postRule, func(c *gin.Context, req *typepb.Field) (*typepb.Field, error) {
+			return req, nil
+		})
+	})
+
+	// body 为字段时其他字段作为查询参数
+	req := &typepb.Field{Name: "a", JsonName: "A", Options: []*typepb.Option{{Name: "deprecated"}}}
+	resp := &typepb.Field{}
+	require.NoError(t, cc.Invoke(context.Background(), patchRule, req, resp))
+	assert.Equal(t, "a", resp.Name)
+	assert.Equal(t, "A", resp.JsonName)
+	require.Len(t, resp.Options, 1)
+	assert.Equal(t, "deprecated", resp.Options[0].Name)
+
+	// response_body 为枚举时服务端返回枚举名称
+	req = &typepb.Field{Name: "b", Kind: typepb.Field_TYPE_INT64}
+	resp = &typepb.Field{}
+	require.NoError(t, cc.Invoke(context.Background(), postRule, req, resp))
+	assert.Equal(t, typepb.Field_TYPE_INT64, resp.Kind)
+	assert.Empty(t, resp.Name)
+}
+
+func TestEncodeBodyField(t *testing.T) {
+	req := &typepb.Type{Name: "a", SourceContext: &sourcecontextpb.SourceContext{FileName: "a.proto"}}
+	data, err := encodeBody(req.ProtoReflect(), "source_context")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"file_name":"a.proto"}`, string(data))
+
+	data, err = encodeBody((&typepb.Type{}).ProtoReflect(), "source_context")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestInvokeError(t *testing.T) {
+	rule := Rule{Method: http.MethodGet, Path: "/v1/fields/{name}"}
+	cc := newServer(t, func(r *gin.Engine) {
+		handle(r, http.MethodGet, "/v1/fields/:name", rule, func(c *gin.Context, req *typepb.Field) (*typepb.Field, error) {
+			return nil, hecode.WithDetails(hecode.WithMessage(hecode.ErrNotFound, "field not found"),
+				hecode.ResourceInfo{ResourceType: "field", ResourceID: req.Name})
+		})
+	})
+
+	err := cc.Invoke(context.Background(), rule, &typepb.Field{Name: "a"}, &typepb.Field{})
+	require.Error(t, err)
+	// 错误码还原为 hecode 错误，HTTP 状态码等元信息来自注册表
+	assert.True(t, hecode.IsError(err, hecode.ErrNotFound))
+	assert.Equal(t, http.StatusNotFound, hecode.HTTPStatus(err))
+	info, ok := hecode.DetailOf[hecode.ResourceInfo](err)
+	require.True(t, ok)
+	assert.Equal(t, "a", info.ResourceID)
+}
+
+func TestInvokeRetry(t *testing.T) {
+	getRule := Rule{Method: http.MethodGet, Path: "/v1/fields/{name}"}
+	postRule := Rule{Method: http.MethodPost, Path: "/v1/fields", Body: "*"}
+	var calls atomic.Int32
+	flaky := func(c *gin.Context, req *typepb.Field) (*typepb.Field, error) {
+		// 每个请求的前两次尝试返回可重试的错误
+		if calls.Add(1)%3 != 0 {
+			return nil, hecode.ErrService
+		}
+		return req, nil
+	}
+	var requestIDs []string
+	cc := newServer(t, func(r *gin.Engine) {
+		r.Use(func(c *gin.Context) {
+			requestIDs = append(requestIDs, c.GetHeader("X-Request-ID"))
+		})
+		handle(r, http.MethodGet, "/v1/fields/:name", getRule, flaky)
+		handle(r, http.MethodPost, "/v1/fields", postRule, flaky)
+	})
+
+	// 幂等方法自动重试，重试使用同一个 request_id
+	resp := &typepb.Field{}
+	require.NoError(t, cc.Invoke(context.Background(), getRule, &typepb.Field{Name: "a"}, resp))
+	assert.Equal(t, "a", resp.Name)
+	assert.Equal(t, int32(3), calls.Load())
+	require.Len(t, requestIDs, 3)
+	assert.NotEmpty(t, requestIDs[0])
+	assert.Equal(t, requestIDs[0], requestIDs[2])
+
+	// 非幂等方法不重试，除非标记为幂等
+	calls.Store(0)
+	err := cc.Invoke(context.Background(), postRule, &typepb.Field{Name: "b"}, resp)
+	assert.True(t, hecode.IsError(err, hecode.ErrService))
+	assert.Equal(t, int32(1), calls.Load())
+	calls.Store(0)
+	require.NoError(t, cc.Invoke(context.Background(), postRule, &typepb.Field{Name: "b"}, resp, Idempotent()))
+	assert.Equal(t, int32(3), calls.Load())
+
+	// Attempts(1) 关闭本次调用的重试
+	calls.Store(0)
+	err = cc.Invoke(context.Background(), getRule, &typepb.Field{Name: "a"}, resp, Attempts(1))
+	assert.True(t, hecode.IsError(err, hecode.ErrService))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestInvokeStatusError(t *testing.T) {
+	rule := Rule{Method: http.MethodGet, Path: "/v1/fields"}
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+
+	// 网关返回的非标准响应按 HTTP 状态码转换
+	err := New(srv.URL, WithRetry(2, time.Millisecond)).Invoke(context.Background(), rule, &typepb.Field{}, &typepb.Field{})
+	assert.True(t, hecode.IsError(err, hecode.ErrService))
+	assert.Contains(t, err.Error(), "bad gateway")
+	assert.Equal(t, int32(2), calls.Load())
+
+	// 连接失败转换为网络错误
+	srv.Close()
+	err = New(srv.URL, WithRetry(1, 0)).Invoke(context.Background(), rule, &typepb.Field{}, &typepb.Field{})
+	assert.True(t, hecode.IsError(err, hecode.ErrNetwork))
+}
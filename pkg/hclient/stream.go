@@ -0,0 +1,275 @@
+package hclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/protobuf/proto"
+)
+
+// ServerStream 服务端流的客户端，通过 SSE 接收服务端推送的消息。
+// 实现了 hstream.ClientStream，可以使用 hstream.All 以迭代器接收
+type ServerStream[T proto.Message] struct {
+	ctx    context.Context
+	body   io.ReadCloser
+	reader *bufio.Reader
+	err    error // 流结束后 Recv 返回的错误
+}
+
+// Stream 调用服务端流方法，连接建立后返回，服务端在推送第一条消息之前返回的错误由 Stream 返回。
+// 流不会自动重试，接收完或不再需要时调用 Close
+func Stream[T proto.Message](ctx context.Context, c *Client, rule Rule, req proto.Message, opts ...CallOption) (*ServerStream[T], error) {
+	r, err := c.newRequest(ctx, rule, req, c.callOptions(rule, opts))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.build().
+		SetHeader("Accept", "text/event-stream").
+		SetDoNotParseResponse(true).
+		Execute(rule.Method, r.path)
+	if err != nil {
+		return nil, transportError(ctx, err)
+	}
+
+	body := resp.RawBody()
+	if !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/event-stream") {
+		defer body.Close()
+		data, err := io.ReadAll(io.LimitReader(body, 1<<20))
+		if err != nil {
+			return nil, transportError(ctx, err)
+		}
+		if _, err := decodeEnvelope(resp.StatusCode(), resp.Header(), data); err != nil {
+			return nil, err
+		}
+		return nil, hecode.FromCode(hecode.Code(hecode.ErrDataFormat), "unexpected response: not an event stream", nil)
+	}
+	return &ServerStream[T]{ctx: ctx, body: body, reader: bufio.NewReader(body)}, nil
+}
+
+// Context 返回调用时传入的 context
+func (s *ServerStream[T]) Context() context.Context {
+	return s.ctx
+}
+
+// Recv 接收一条消息，流正常结束时返回 io.EOF，服务端返回错误时返回还原的 hecode 错误
+func (s *ServerStream[T]) Recv() (T, error) {
+	var zero T
+	for s.err == nil {
+		event, data, err := s.next()
+		if err != nil {
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				err = ctxErr
+			} else if errors.Is(err, io.EOF) {
+				// 没有收到 end 事件连接就断开了
+				err = io.ErrUnexpectedEOF
+			}
+			s.finish(transportError(s.ctx, err))
+			break
+		}
+
+		var env envelope
+		if err := json.Unmarshal(data, &env); err != nil {
+			s.finish(hecode.FromCode(hecode.Code(hecode.ErrDataFormat), err.Error(), err))
+			break
+		}
+		switch event {
+		case "end":
+			s.finish(io.EOF)
+		case "error":
+			s.finish(env.err())
+		default:
+			if !env.succeeded() {
+				s.finish(env.err())
+				break
+			}
+			msg := newMessage[T]()
+			if err := decodeData(env.Data, "", msg); err != nil {
+				return zero, err
+			}
+			return msg, nil
+		}
+	}
+	return zero, s.err
+}
+
+// Close 关闭连接，未接收完时服务端的流 context 会被取消
+func (s *ServerStream[T]) Close() error {
+	s.finish(io.EOF)
+	return s.body.Close()
+}
+
+func (s *ServerStream[T]) finish(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// next 读取一个事件，跳过心跳等没有数据的事件
+func (s *ServerStream[T]) next() (event string, data []byte, err error) {
+	var lines [][]byte
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		if err != nil {
+			return "", nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			if lines == nil {
+				event = ""
+				continue
+			}
+			return event, bytes.Join(lines, []byte("\n")), nil
+		}
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "event":
+			event = string(value)
+		case "data":
+			lines = append(lines, value)
+		}
+		// 以 : 开头的注释行（心跳）和 id 等其他字段忽略
+	}
+}
+
+// BidiStream 客户端流和双向流的客户端，通过 WebSocket 收发消息。
+// 实现了 hstream.ClientStream，可以使用 hstream.All 以迭代器接收
+type BidiStream[Req, Resp proto.Message] struct {
+	ctx     context.Context
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	stop    func() bool
+	err     error // 流结束后 Recv 返回的错误
+}
+
+// Dial 建立客户端流或双向流的 WebSocket 连接，握手失败时服务端返回的错误还原为 hecode 错误。
+// ctx 取消后连接被关闭，不再需要时调用 Close
+func Dial[Req, Resp proto.Message](ctx context.Context, c *Client, rule Rule, opts ...CallOption) (*BidiStream[Req, Resp], error) {
+	r, err := c.newRequest(ctx, rule, nil, c.callOptions(rule, opts))
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(c.resty.BaseURL + r.path)
+	if err != nil {
+		return nil, hecode.FromCode(hecode.Code(hecode.ErrConfig), err.Error(), err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+
+	conn, resp, err := c.dialer.DialContext(ctx, u.String(), r.header)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+			if _, derr := decodeEnvelope(resp.StatusCode, resp.Header, body); derr != nil {
+				return nil, derr
+			}
+		}
+		return nil, transportError(ctx, err)
+	}
+	s := &BidiStream[Req, Resp]{ctx: ctx, conn: conn}
+	s.stop = context.AfterFunc(ctx, func() { conn.Close() })
+	return s, nil
+}
+
+// Context 返回建立连接时传入的 context
+func (s *BidiStream[Req, Resp]) Context() context.Context {
+	return s.ctx
+}
+
+// Send 发送一条请求消息，与 hbind 一样使用 protojson 编码
+func (s *BidiStream[Req, Resp]) Send(msg Req) error {
+	data, err := marshalOptions.Marshal(msg)
+	if err != nil {
+		return hecode.WithDetails(hecode.ErrInvalidParam, hecode.FieldViolation{Field: "message", Description: err.Error()})
+	}
+	return s.write(data)
+}
+
+// CloseSend 结束请求流（半关闭），之后仍可以接收响应
+func (s *BidiStream[Req, Resp]) CloseSend() error {
+	return s.write(nil)
+}
+
+// CloseAndRecv 结束请求流并接收客户端流方法唯一的响应
+func (s *BidiStream[Req, Resp]) CloseAndRecv() (Resp, error) {
+	if err := s.CloseSend(); err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return s.Recv()
+}
+
+// Recv 接收一条响应消息，服务端正常结束流时返回 io.EOF，服务端返回错误时返回还原的 hecode 错误
+func (s *BidiStream[Req, Resp]) Recv() (Resp, error) {
+	var zero Resp
+	if s.err != nil {
+		return zero, s.err
+	}
+	_, data, err := s.conn.ReadMessage()
+	if err != nil {
+		switch {
+		case websocket.IsCloseError(err, websocket.CloseNormalClosure):
+			s.err = io.EOF
+		case s.ctx.Err() != nil:
+			s.err = transportError(s.ctx, s.ctx.Err())
+		default:
+			s.err = transportError(s.ctx, err)
+		}
+		return zero, s.err
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return zero, hecode.FromCode(hecode.Code(hecode.ErrDataFormat), err.Error(), err)
+	}
+	if !env.succeeded() {
+		// 服务端发送错误后关闭连接
+		s.err = env.err()
+		return zero, s.err
+	}
+	msg := newMessage[Resp]()
+	if err := decodeData(env.Data, "", msg); err != nil {
+		return zero, err
+	}
+	return msg, nil
+}
+
+// Close 以 1000 关闭连接
+func (s *BidiStream[Req, Resp]) Close() error {
+	s.stop()
+	s.writeMu.Lock()
+	s.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	s.writeMu.Unlock()
+	return s.conn.Close()
+}
+
+func (s *BidiStream[Req, Resp]) write(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return transportError(s.ctx, err)
+	}
+	return nil
+}
+
+// newMessage 创建 T 指向的消息
+func newMessage[T proto.Message]() T {
+	var msg T
+	return msg.ProtoReflect().Type().New().Interface().(T)
+}
@@ -0,0 +1,143 @@
+package hclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/hollow/pkg/hbind"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"github.com/vaynedu/hollow/pkg/hstream"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestStream(t *testing.T) {
+	rule := Rule{Method: http.MethodGet, Path: "/v1/watch/{value}"}
+	cc := newServer(t, func(r *gin.Engine) {
+		r.GET("/v1/watch/:value", hbind.Rule(rule.Path, "", ""), func(c *gin.Context) {
+			var req wrapperspb.StringValue
+			if err := hbind.Bind(c, &req); err != nil {
+				c.Error(err)
+				return
+			}
+			hstream.SSE(c, func(s hstream.ServerStream[*wrapperspb.StringValue]) error {
+				if req.Value == "empty" {
+					return hecode.ErrNotFound
+				}
+				for _, v := range []string{"a", "b"} {
+					if err := s.Send(wrapperspb.String(req.Value + v)); err != nil {
+						return err
+					}
+				}
+				if req.Value == "fail" {
+					return hecode.ErrService
+				}
+				return nil
+			})
+		})
+	})
+
+	stream, err := Stream[*wrapperspb.StringValue](context.Background(), cc, rule, wrapperspb.String("x"))
+	require.NoError(t, err)
+	defer stream.Close()
+	var values []string
+	for msg, err := range hstream.All(stream) {
+		require.NoError(t, err)
+		values = append(values, msg.Value)
+	}
+	assert.Equal(t, []string{"xa", "xb"}, values)
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// 推送消息之后的错误从 Recv 返回
+	stream, err = Stream[*wrapperspb.StringValue](context.Background(), cc, rule, wrapperspb.String("fail"))
+	require.NoError(t, err)
+	defer stream.Close()
+	for range 2 {
+		_, err = stream.Recv()
+		require.NoError(t, err)
+	}
+	_, err = stream.Recv()
+	assert.True(t, hecode.IsError(err, hecode.ErrService))
+
+	// 推送第一条消息之前的错误由 Stream 返回
+	_, err = Stream[*wrapperspb.StringValue](context.Background(), cc, rule, wrapperspb.String("empty"))
+	assert.True(t, hecode.IsError(err, hecode.ErrNotFound))
+}
+
+func TestDial(t *testing.T) {
+	bidi := Rule{Method: http.MethodGet, Path: "/v1/chat"}
+	client := Rule{Method: http.MethodGet, Path: "/v1/upload"}
+	cc := newServer(t, func(r *gin.Engine) {
+		r.GET(bidi.Path, func(c *gin.Context) {
+			hstream.WebSocket(c, func(s hstream.BidiStream[*wrapperspb.StringValue, *wrapperspb.StringValue]) error {
+				for msg, err := range hstream.All(s) {
+					if err != nil {
+						return err
+					}
+					if msg.Value == "boom" {
+						return hecode.ErrBusinessRule
+					}
+					if err := s.Send(wrapperspb.String(strings.ToUpper(msg.Value))); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+		r.GET(client.Path, func(c *gin.Context) {
+			hstream.WebSocket(c, func(s hstream.BidiStream[*wrapperspb.StringValue, *wrapperspb.Int32Value]) error {
+				var n int32
+				for _, err := range hstream.All(s) {
+					if err != nil {
+						return err
+					}
+					n++
+				}
+				return s.Send(wrapperspb.Int32(n))
+			})
+		})
+	})
+
+	// 双向流
+	stream, err := Dial[*wrapperspb.StringValue, *wrapperspb.StringValue](context.Background(), cc, bidi)
+	require.NoError(t, err)
+	defer stream.Close()
+	for _, v := range []string{"a", "b"} {
+		require.NoError(t, stream.Send(wrapperspb.String(v)))
+		msg, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, strings.ToUpper(v), msg.Value)
+	}
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// 服务端返回的错误还原为 hecode 错误
+	stream, err = Dial[*wrapperspb.StringValue, *wrapperspb.StringValue](context.Background(), cc, bidi)
+	require.NoError(t, err)
+	defer stream.Close()
+	require.NoError(t, stream.Send(wrapperspb.String("boom")))
+	_, err = stream.Recv()
+	assert.True(t, hecode.IsError(err, hecode.ErrBusinessRule))
+
+	// 客户端流
+	upload, err := Dial[*wrapperspb.StringValue, *wrapperspb.Int32Value](context.Background(), cc, client)
+	require.NoError(t, err)
+	defer upload.Close()
+	for _, v := range []string{"a", "b", "c"} {
+		require.NoError(t, upload.Send(wrapperspb.String(v)))
+	}
+	count, err := upload.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), count.Value)
+
+	// 握手失败时返回服务端的错误
+	_, err = Dial[*wrapperspb.StringValue, *wrapperspb.StringValue](context.Background(), cc, Rule{Method: http.MethodGet, Path: "/v1/missing"})
+	assert.Error(t, err)
+}
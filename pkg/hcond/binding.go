@@ -0,0 +1,403 @@
+package hcond
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vaynedu/hollow/pkg/hecode"
+)
+
+var (
+	simpleOpRe    = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*)\s*(>=|<=|!=|=|>|<|~)\s*(.*)$`)
+	namedOpRe     = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*):([A-Za-z]+)(?::(.*))?$`)
+	namedOperator = map[string]Op{
+		"eq": OpEq, "ne": OpNotEq, "gt": OpGt, "gte": OpGte, "lt": OpLt, "lte": OpLte,
+		"in": OpIn, "nin": OpNotIn, "like": OpLike, "nlike": OpNotLike, "ilike": OpILike,
+		"between": OpBetween, "null": OpIsNull, "notnull": OpIsNotNull, "regexp": OpRegexp, "contains": OpContains,
+	}
+	symbolOperator = map[string]Op{
+		"=": OpEq, "!=": OpNotEq, ">": OpGt, ">=": OpGte, "<": OpLt, "<=": OpLte, "~": OpLike,
+	}
+)
+
+// Bind 绑定列表接口的查询参数，GET、DELETE 请求以及没有请求体的请求从 URL 查询参数绑定，其他请求从 JSON 请求体绑定，例如
+//
+//		GET /v1/users?filter=age>=18&filter=city:in:sz,bj&sort=-created_at&page=2&size=20&fields=id,name
+//
+//	  - filter：可以重复，也可以用分号（可以不编码）分隔多个条件，按 AND 组合，每个条件为 field<op>value 或者 field:op:value，
+//	    op 为 = != > >= < <= ~（LIKE）或者 eq ne gt gte lt lte in nin like nlike ilike between null notnull regexp contains，
+//	    in、nin、between、contains 的值用逗号分隔，null、notnull 没有值
+//	  - q：hcond 表达式，例如 age >= 18 && (city IN ["sz"] || vip == true)，与 filter 按 AND 组合
+//	  - sort：逗号分隔的排序字段，字段前加 - 表示降序
+//	  - page、size（也可以写作 page_size）：分页，默认第 1 页，每页 DefaultPageSize 条
+//	  - fields：逗号分隔的返回字段
+//
+// 请求体为 JSON 时格式与 Query 相同，filter 可以是 Condition 对象或者 hcond 表达式，sort 可以是数组或者字符串。
+// schema 为字段白名单，参数错误时返回带 hecode.FieldViolation 详情的 hecode.ErrInvalidParam
+func Bind(c *gin.Context, schema *Schema, opts ...Option) (*Query, error) {
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodDelete || c.Request.ContentLength == 0 {
+		return BindQuery(c, schema, opts...)
+	}
+	return BindJSON(c, schema, opts...)
+}
+
+// BindQuery 从 URL 查询参数绑定列表查询
+func BindQuery(c *gin.Context, schema *Schema, opts ...Option) (*Query, error) {
+	return ParseRawQuery(c.Request.URL.RawQuery, schema, opts...)
+}
+
+// ParseRawQuery 解析未解码的 URL 查询字符串，参数只按 & 分隔，值中未编码的分号保留给 filter 作为条件分隔符。
+// url.ParseQuery（以及 URL.Query）会丢弃含有分号的参数，绑定 filter 时应使用 ParseRawQuery
+func ParseRawQuery(rawQuery string, schema *Schema, opts ...Option) (*Query, error) {
+	values, err := parseRawQuery(rawQuery)
+	if err != nil {
+		return nil, invalidParam(hecode.FieldViolation{Field: "query", Description: err.Error()})
+	}
+	return ParseQuery(values, schema, opts...)
+}
+
+// parseRawQuery 与 url.ParseQuery 相同，但分号不作为参数分隔符，解码失败时返回错误而不是丢弃参数
+func parseRawQuery(rawQuery string) (url.Values, error) {
+	values := make(url.Values)
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = url.QueryUnescape(value); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = append(values[key], value)
+	}
+	return values, nil
+}
+
+// BindJSON 从 JSON 请求体绑定列表查询
+func BindJSON(c *gin.Context, schema *Schema, opts ...Option) (*Query, error) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, invalidParam(hecode.FieldViolation{Field: "body", Description: err.Error()})
+	}
+	return ParseQueryJSON(data, schema, opts...)
+}
+
+// ParseQuery 解析 URL 查询参数，不依赖 gin，方便在其他框架中使用。
+// values 来自 url.ParseQuery 时含有未编码分号的参数已被丢弃，原始查询字符串使用 ParseRawQuery 解析
+func ParseQuery(values url.Values, schema *Schema, opts ...Option) (*Query, error) {
+	b := newQueryBinder(schema, opts)
+	q := &Query{}
+
+	var conds []*Condition
+	for _, filter := range values["filter"] {
+		conds = append(conds, b.parseFilter(filter)...)
+	}
+	for _, expr := range values["q"] {
+		conds = append(conds, b.parseExpr("q", expr))
+	}
+	q.Filter = And(conds...)
+
+	for _, sort := range values["sort"] {
+		q.Sort = append(q.Sort, ParseSort(sort)...)
+	}
+	for _, fields := range values["fields"] {
+		q.Fields = append(q.Fields, splitList(fields)...)
+	}
+	q.Page = b.parseInt("page", values.Get("page"))
+	size := values.Get("size")
+	if size == "" {
+		size = values.Get("page_size")
+	}
+	q.PageSize = b.parseInt("size", size)
+	return b.finish(q)
+}
+
+// queryBody JSON 请求体
+type queryBody struct {
+	Filter   json.RawMessage `json:"filter"`
+	Sort     json.RawMessage `json:"sort"`
+	Page     *int            `json:"page"`
+	PageSize *int            `json:"page_size"`
+	Size     *int            `json:"size"`
+	Fields   []string        `json:"fields"`
+}
+
+// ParseQueryJSON 解析 JSON 格式的列表查询
+func ParseQueryJSON(data []byte, schema *Schema, opts ...Option) (*Query, error) {
+	b := newQueryBinder(schema, opts)
+	q := &Query{}
+
+	var body queryBody
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, invalidParam(hecode.FieldViolation{Field: "body", Description: err.Error()})
+		}
+	}
+
+	switch filter := bytes.TrimSpace(body.Filter); {
+	case len(filter) == 0 || string(filter) == "null":
+	case filter[0] == '"':
+		var expr string
+		if err := json.Unmarshal(filter, &expr); err != nil {
+			b.violate("filter", err.Error())
+		} else if expr != "" {
+			q.Filter = b.parseExpr("filter", expr)
+		}
+	default:
+		var cond Condition
+		if err := json.Unmarshal(filter, &cond); err != nil {
+			b.violate("filter", err.Error())
+		} else {
+			q.Filter = b.validate("filter", &cond)
+		}
+	}
+
+	switch sort := bytes.TrimSpace(body.Sort); {
+	case len(sort) == 0 || string(sort) == "null":
+	case sort[0] == '"':
+		var s string
+		if err := json.Unmarshal(sort, &s); err != nil {
+			b.violate("sort", err.Error())
+		}
+		q.Sort = ParseSort(s)
+	default:
+		if err := json.Unmarshal(sort, &q.Sort); err != nil {
+			b.violate("sort", err.Error())
+		}
+	}
+
+	q.Fields = body.Fields
+	if body.Page != nil {
+		q.Page = b.checkPositive("page", *body.Page)
+	}
+	if body.PageSize != nil {
+		q.PageSize = b.checkPositive("size", *body.PageSize)
+	} else if body.Size != nil {
+		q.PageSize = b.checkPositive("size", *body.Size)
+	}
+	return b.finish(q)
+}
+
+// queryBinder 解析过程中收集所有的参数错误
+type queryBinder struct {
+	schema     *Schema
+	opts       []Option
+	violations []hecode.Detail
+}
+
+func newQueryBinder(schema *Schema, opts []Option) *queryBinder {
+	if schema != nil {
+		opts = append([]Option{WithSchema(schema)}, opts...)
+	}
+	return &queryBinder{schema: schema, opts: opts}
+}
+
+func (b *queryBinder) violate(field, description string) {
+	b.violations = append(b.violations, hecode.FieldViolation{Field: field, Description: description})
+}
+
+// parseFilter 解析 filter 参数，每个条件单独校验，错误详情中的字段为 filter.<字段名>
+func (b *queryBinder) parseFilter(filter string) []*Condition {
+	var conds []*Condition
+	for _, clause := range strings.Split(filter, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		cond, err := b.parseClause(clause)
+		if err != nil {
+			b.violate("filter", fmt.Sprintf("%q: %s", clause, err))
+			continue
+		}
+		if cond = b.validate("filter."+cond.LHS, cond); cond != nil {
+			conds = append(conds, cond)
+		}
+	}
+	return conds
+}
+
+// parseClause 解析 field<op>value 或者 field:op:value
+func (b *queryBinder) parseClause(clause string) (*Condition, error) {
+	var field, value string
+	var op Op
+	if m := namedOpRe.FindStringSubmatch(clause); m != nil {
+		var ok bool
+		if op, ok = namedOperator[strings.ToLower(m[2])]; !ok {
+			return nil, fmt.Errorf("unknown operator %q", m[2])
+		}
+		field, value = m[1], m[3]
+	} else if m := simpleOpRe.FindStringSubmatch(clause); m != nil {
+		field, op, value = m[1], symbolOperator[m[2]], m[3]
+	} else {
+		return nil, fmt.Errorf("expected field<op>value or field:op:value")
+	}
+
+	f := b.field(field)
+	switch op {
+	case OpIsNull, OpIsNotNull:
+		if value != "" {
+			return nil, fmt.Errorf("%s does not take a value", op)
+		}
+		return &Condition{Operator: string(op), LHS: field}, nil
+	case OpIn, OpNotIn, OpBetween, OpContains:
+		items := splitList(value)
+		if op == OpContains && len(items) == 1 {
+			return &Condition{Operator: string(op), LHS: field, RHS: convertValue(f, items[0])}, nil
+		}
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			values[i] = convertValue(f, item)
+		}
+		return &Condition{Operator: string(op), LHS: field, RHS: values}, nil
+	case OpLike, OpNotLike, OpILike, OpRegexp:
+		return &Condition{Operator: string(op), LHS: field, RHS: value}, nil
+	default:
+		return &Condition{Operator: string(op), LHS: field, RHS: convertValue(f, value)}, nil
+	}
+}
+
+// parseExpr 解析 hcond 表达式
+func (b *queryBinder) parseExpr(name, expr string) *Condition {
+	cond, err := Parse(expr)
+	if err != nil {
+		b.violate(name, strings.TrimPrefix(err.Error(), "hcond: "))
+		return nil
+	}
+	return b.validate(name, cond)
+}
+
+// validate 校验条件，失败时记录错误并返回 nil
+func (b *queryBinder) validate(name string, cond *Condition) *Condition {
+	if err := cond.Validate(b.opts...); err != nil {
+		b.violate(name, strings.TrimPrefix(err.Error(), "hcond: validate: "))
+		return nil
+	}
+	return cond
+}
+
+func (b *queryBinder) parseInt(name, value string) int {
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		b.violate(name, fmt.Sprintf("%q is not an integer", value))
+		return 0
+	}
+	return b.checkPositive(name, n)
+}
+
+func (b *queryBinder) checkPositive(name string, n int) int {
+	if n < 1 {
+		b.violate(name, "must be greater than 0")
+		return 0
+	}
+	return n
+}
+
+// finish 校验排序、返回字段和分页，补全分页的默认值
+func (b *queryBinder) finish(q *Query) (*Query, error) {
+	o := newOptions(b.opts)
+	for _, s := range q.Sort {
+		if _, err := o.columnOf(s.Field); err != nil {
+			b.violate("sort", err.Error())
+		}
+	}
+	for _, f := range q.Fields {
+		if _, err := o.columnOf(f); err != nil {
+			b.violate("fields", err.Error())
+		}
+	}
+	if q.PageSize > MaxPageSize {
+		b.violate("size", fmt.Sprintf("must not be greater than %d", MaxPageSize))
+	}
+	if len(b.violations) > 0 {
+		return nil, invalidParam(b.violations...)
+	}
+
+	// 列表接口默认分页，避免一次返回所有数据
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.PageSize == 0 {
+		q.PageSize = DefaultPageSize
+	}
+	return q, nil
+}
+
+// field 返回 Schema 中的字段，没有 Schema 或者字段不存在时返回 TypeAny 字段，由后续的校验报告错误
+func (b *queryBinder) field(name string) Field {
+	if b.schema != nil {
+		if f, ok := b.schema.Field(name); ok {
+			return f
+		}
+	}
+	return Field{Name: name, Column: name, Type: TypeAny}
+}
+
+// convertValue 将查询参数中的字符串转换为字段类型的值，无法转换时保留字符串，由 Schema 校验报告类型错误
+func convertValue(f Field, s string) interface{} {
+	switch f.Type {
+	case TypeString, TypeTime:
+		return s
+	case TypeInt:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case TypeFloat:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	case TypeBool:
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v
+		}
+	default:
+		// 没有类型信息时推断：null、布尔值、整数、小数，其余按字符串处理
+		if s == "null" {
+			return nil
+		}
+		if v, err := strconv.ParseBool(s); err == nil && (s == "true" || s == "false") {
+			return v
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseFloat(s, 64); err == nil && isNumeric(s) {
+			return n
+		}
+	}
+	return s
+}
+
+// isNumeric 排除 ParseFloat 能够解析的 inf、nan 等非数字字符串
+func isNumeric(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+' || r == 'e' || r == 'E')
+	}) < 0
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func invalidParam(violations ...hecode.Detail) error {
+	return hecode.WithDetails(hecode.ErrInvalidParam, violations...)
+}
@@ -0,0 +1,199 @@
+package hcond
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/hollow/pkg/hecode"
+)
+
+func TestParseQuery(t *testing.T) {
+	s := MustSchemaOf(schemaUser{})
+	values, err := url.ParseQuery("filter=age>=18%3Bname:in:alice,bob&filter=score:null%3Bvip=true&sort=-created_at,name&page=2&size=20&fields=id,name")
+	require.NoError(t, err)
+
+	q, err := ParseQuery(values, s)
+	require.NoError(t, err)
+	assert.Equal(t, `age >= 18 && name IN ["alice", "bob"] && score IS NULL && vip == true`, q.Filter.String())
+	assert.Equal(t, []Sort{{Field: "created_at", Desc: true}, {Field: "name"}}, q.Sort)
+	assert.Equal(t, 2, q.Page)
+	assert.Equal(t, 20, q.PageSize)
+	assert.Equal(t, []string{"id", "name"}, q.Fields)
+
+	// 字段按 Schema 的类型转换
+	sql, args, err := q.Filter.ToSQL(WithSchema(s))
+	require.NoError(t, err)
+	assert.Equal(t, "(`u`.`age` >= ? AND `user_name` IN (?, ?) AND `score` IS NULL AND `vip` = ?)", sql)
+	assert.Equal(t, []interface{}{int64(18), "alice", "bob", true}, args)
+}
+
+func TestParseQueryOperators(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"filter=name~a%25", `name LIKE "a%"`},
+		{"filter=name:ilike:A%25%3Bname:nlike:%25b", `name ILIKE "A%" && name NOT LIKE "%b"`},
+		{"filter=age:between:18,30%3Bid:nin:1,2", `age BETWEEN 18 AND 30 && id NOT IN [1, 2]`},
+		{"filter=created_at:gte:2024-01-01T10:00:00", `created_at >= "2024-01-01T10:00:00"`},
+		{"filter=tags:contains:vip%3Bemail:notnull", `tags CONTAINS "vip" && email IS NOT NULL`},
+		{"filter=tags:contains:vip,new&filter=score!=1.5", `tags CONTAINS ["vip", "new"] && score != 1.5`},
+		{"filter=id=1&q=" + url.QueryEscape(`age > 18 || vip == true`), `id == 1 && (age > 18 || vip == true)`},
+		{"filter=x=abc%3By=null%3Bz=1e3%3Bw=inf", `x == "abc" && y == null && z == 1000.0 && w == "inf"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			q, err := ParseQuery(values, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, q.Filter.String())
+			// 默认分页
+			assert.Equal(t, 1, q.Page)
+			assert.Equal(t, DefaultPageSize, q.PageSize)
+		})
+	}
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	s := MustSchemaOf(schemaUser{})
+	tests := []struct {
+		query  string
+		fields []string
+	}{
+		{"filter=password=1", []string{"filter.password"}},
+		{"filter=age=18", []string{"filter.age"}},
+		{"filter=id>abc%3Bage", []string{"filter.id", "filter"}},
+		{"filter=age:foo:1", []string{"filter"}},
+		{"filter=score:null:1", []string{"filter"}},
+		{"q=" + url.QueryEscape("age >="), []string{"q"}},
+		{"sort=password&fields=id,password", []string{"sort", "fields"}},
+		{"page=0&size=abc", []string{"page", "size"}},
+		{"size=100000", []string{"size"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			_, err = ParseQuery(values, s)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, hecode.ErrInvalidParam))
+
+			var fields []string
+			for _, d := range hecode.Details(err) {
+				fields = append(fields, d.(hecode.FieldViolation).Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestParseQueryJSON(t *testing.T) {
+	s := MustSchemaOf(schemaUser{})
+
+	q, err := ParseQueryJSON([]byte(`{
+		"filter": {"operator": "&&", "conditions": [
+			{"operator": ">=", "lhs": "age", "rhs": 18},
+			{"operator": "IN", "lhs": "name", "rhs": ["alice"]}
+		]},
+		"sort": [{"field": "created_at", "desc": true}],
+		"page": 3,
+		"page_size": 10
+	}`), s)
+	require.NoError(t, err)
+	assert.Equal(t, `age >= 18.0 && name IN ["alice"]`, q.Filter.String())
+	assert.Equal(t, []Sort{{Field: "created_at", Desc: true}}, q.Sort)
+	assert.Equal(t, 3, q.Page)
+	assert.Equal(t, 10, q.PageSize)
+
+	q, err = ParseQueryJSON([]byte(`{"filter": "vip == true && age BETWEEN 18 AND 30", "sort": "-id", "size": 5}`), s)
+	require.NoError(t, err)
+	assert.Equal(t, `vip == true && age BETWEEN 18 AND 30`, q.Filter.String())
+	assert.Equal(t, []Sort{{Field: "id", Desc: true}}, q.Sort)
+	assert.Equal(t, 5, q.PageSize)
+
+	q, err = ParseQueryJSON(nil, s)
+	require.NoError(t, err)
+	assert.Nil(t, q.Filter)
+
+	_, err = ParseQueryJSON([]byte(`{"filter": {"operator": "==", "lhs": "password", "rhs": "x"}, "page": -1}`), s)
+	assert.True(t, errors.Is(err, hecode.ErrInvalidParam))
+	assert.Len(t, hecode.Details(err), 2)
+
+	_, err = ParseQueryJSON([]byte(`{`), s)
+	assert.True(t, errors.Is(err, hecode.ErrInvalidParam))
+}
+
+func TestBind(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := MustSchemaOf(schemaUser{})
+
+	var got *Query
+	r := gin.New()
+	handler := func(c *gin.Context) {
+		q, err := Bind(c, s)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		got = q
+	}
+	r.GET("/users", handler)
+	r.POST("/users/search", handler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?filter=age>=18&sort=-id", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `age >= 18`, got.Filter.String())
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/search", strings.NewReader(`{"filter": "name == \"bob\"", "page": 2}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `name == "bob"`, got.Filter.String())
+	assert.Equal(t, 2, got.Page)
+
+	// filter 中未编码的分号作为条件分隔符，不会导致整个参数被丢弃
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?filter=age>=18;name:in:alice,bob&page=2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `age >= 18 && name IN ["alice", "bob"]`, got.Filter.String())
+	assert.Equal(t, 2, got.Page)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?filter=password=1", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 无法解码的查询参数返回参数错误
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?filter=age>=18%zz;id=1", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestParseRawQuery(t *testing.T) {
+	q, err := ParseRawQuery("filter=age>=18;name:in:alice,bob&filter=vip%3Dtrue&sort=-id&size=5", MustSchemaOf(schemaUser{}))
+	require.NoError(t, err)
+	assert.Equal(t, `age >= 18 && name IN ["alice", "bob"] && vip == true`, q.Filter.String())
+	assert.Equal(t, []Sort{{Field: "id", Desc: true}}, q.Sort)
+	assert.Equal(t, 5, q.PageSize)
+
+	_, err = ParseRawQuery("filter=%zz", nil)
+	assert.True(t, errors.Is(err, hecode.ErrInvalidParam))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, MustParse(`a == 1 && b IN [1] && c LIKE "x%"`).Validate())
+	assert.ErrorIs(t, (&Condition{Operator: "IN", LHS: "a", RHS: 1}).Validate(), ErrRHSNotSlice)
+	assert.ErrorIs(t, (&Condition{Operator: "LIKE", LHS: "a", RHS: 1}).Validate(), ErrTypeMismatch)
+	assert.ErrorIs(t, (&Condition{Operator: "~~", LHS: "a", RHS: 1}).Validate(), ErrUnsupportedOperator)
+	assert.Error(t, (&Condition{Operator: "REGEXP", LHS: "a", RHS: "("}).Validate())
+	assert.ErrorIs(t, MustParse(`password == 1`).Validate(WithSchema(MustSchemaOf(schemaUser{}))), ErrFieldNotAllowed)
+}
@@ -0,0 +1,146 @@
+package hcond
+
+// 链式构造条件，例如
+//
+//	cond := hcond.And(
+//		hcond.Gte("age", 18),
+//		hcond.In("city", cities...),
+//		hcond.Or(hcond.Eq("vip", true), hcond.Gt("score", 90)),
+//	)
+//
+// And、Or 会忽略 nil 条件，方便按需拼接可选的过滤条件
+
+// Eq 等于，value 为 nil 时生成 IS NULL
+func Eq(field string, value interface{}) *Condition {
+	return atom(OpEq, field, value)
+}
+
+// NotEq 不等于，value 为 nil 时生成 IS NOT NULL
+func NotEq(field string, value interface{}) *Condition {
+	return atom(OpNotEq, field, value)
+}
+
+// Gt 大于
+func Gt(field string, value interface{}) *Condition {
+	return atom(OpGt, field, value)
+}
+
+// Lt 小于
+func Lt(field string, value interface{}) *Condition {
+	return atom(OpLt, field, value)
+}
+
+// Gte 大于等于
+func Gte(field string, value interface{}) *Condition {
+	return atom(OpGte, field, value)
+}
+
+// Lte 小于等于
+func Lte(field string, value interface{}) *Condition {
+	return atom(OpLte, field, value)
+}
+
+// In 属于集合，没有值时恒为假
+func In[T any](field string, values ...T) *Condition {
+	return atom(OpIn, field, values)
+}
+
+// NotIn 不属于集合，没有值时恒为真
+func NotIn[T any](field string, values ...T) *Condition {
+	return atom(OpNotIn, field, values)
+}
+
+// Like 模式匹配，用户输入需要先经过 EscapeLike 转义
+func Like(field, pattern string) *Condition {
+	return atom(OpLike, field, pattern)
+}
+
+// NotLike 模式不匹配
+func NotLike(field, pattern string) *Condition {
+	return atom(OpNotLike, field, pattern)
+}
+
+// ILike 不区分大小写的模式匹配
+func ILike(field, pattern string) *Condition {
+	return atom(OpILike, field, pattern)
+}
+
+// Between 闭区间 [low, high]
+func Between(field string, low, high interface{}) *Condition {
+	return atom(OpBetween, field, []interface{}{low, high})
+}
+
+// IsNull 为空
+func IsNull(field string) *Condition {
+	return atom(OpIsNull, field, nil)
+}
+
+// IsNotNull 不为空
+func IsNotNull(field string) *Condition {
+	return atom(OpIsNotNull, field, nil)
+}
+
+// Regexp 正则匹配
+func Regexp(field, pattern string) *Condition {
+	return atom(OpRegexp, field, pattern)
+}
+
+// Contains JSON 包含
+func Contains(field string, value interface{}) *Condition {
+	return atom(OpContains, field, value)
+}
+
+// And 所有条件都满足，忽略 nil 条件，只有一个条件时直接返回该条件，没有条件时返回 nil
+func And(conds ...*Condition) *Condition {
+	return logical(OpAnd, conds)
+}
+
+// Or 任意条件满足，忽略 nil 条件，只有一个条件时直接返回该条件，没有条件时返回 nil
+func Or(conds ...*Condition) *Condition {
+	return logical(OpOr, conds)
+}
+
+// Not 条件取反，cond 为 nil 时返回 nil
+func Not(cond *Condition) *Condition {
+	if cond == nil {
+		return nil
+	}
+	return &Condition{Operator: string(OpNot), Conditions: []Condition{*cond}}
+}
+
+// And 与其他条件组合为 AND 条件
+func (c *Condition) And(conds ...*Condition) *Condition {
+	return And(append([]*Condition{c}, conds...)...)
+}
+
+// Or 与其他条件组合为 OR 条件
+func (c *Condition) Or(conds ...*Condition) *Condition {
+	return Or(append([]*Condition{c}, conds...)...)
+}
+
+func atom(op Op, field string, value interface{}) *Condition {
+	return &Condition{Operator: string(op), LHS: field, RHS: value}
+}
+
+func logical(op Op, conds []*Condition) *Condition {
+	subs := make([]Condition, 0, len(conds))
+	for _, c := range conds {
+		if c == nil {
+			continue
+		}
+		// 相同的逻辑运算合并为一层，与 Parse 的结果保持一致
+		if Op(c.Operator) == op && len(c.Conditions) > 0 {
+			subs = append(subs, c.Conditions...)
+			continue
+		}
+		subs = append(subs, *c)
+	}
+	switch len(subs) {
+	case 0:
+		return nil
+	case 1:
+		return &subs[0]
+	default:
+		return &Condition{Operator: string(op), Conditions: subs}
+	}
+}
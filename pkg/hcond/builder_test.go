@@ -0,0 +1,58 @@
+package hcond
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	ids := []int64{1, 2, 3}
+	tests := []struct {
+		cond     *Condition
+		expected string
+	}{
+		{And(Eq("a", 1), In("b", ids...)), `a == 1 && b IN [1, 2, 3]`},
+		{Or(Gt("age", 60), Lt("age", 18), Gte("score", 90), Lte("score", 10)), `age > 60 || age < 18 || score >= 90 || score <= 10`},
+		{And(NotEq("city", "sz"), NotIn("id", 4, 5), Not(Eq("vip", true))), `city != "sz" && id NOT IN [4, 5] && !(vip == true)`},
+		{And(Like("name", "a%"), NotLike("name", "%b"), ILike("email", "%@QQ.COM")), `name LIKE "a%" && name NOT LIKE "%b" && email ILIKE "%@QQ.COM"`},
+		{And(Between("age", 18, 30), IsNull("deleted_at"), IsNotNull("email")), `age BETWEEN 18 AND 30 && deleted_at IS NULL && email IS NOT NULL`},
+		{Or(Regexp("email", "^a"), Contains("tags", []string{"vip"})), `email REGEXP "^a" || tags CONTAINS ["vip"]`},
+		// 忽略 nil 条件，同类逻辑条件合并为一层
+		{And(nil, Eq("a", 1), nil), `a == 1`},
+		{And(Eq("a", 1), And(Eq("b", 2), Eq("c", 3))), `a == 1 && b == 2 && c == 3`},
+		{Eq("a", 1).And(Eq("b", 2)).Or(Eq("c", 3)), `(a == 1 && b == 2) || c == 3`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			require.NotNil(t, tt.cond)
+			assert.Equal(t, tt.expected, tt.cond.String())
+
+			// 与解析结果一致
+			parsed, err := Parse(tt.expected)
+			require.NoError(t, err)
+			parsedSQL, parsedArgs, err := parsed.ToSQL()
+			require.NoError(t, err)
+			sql, args, err := tt.cond.ToSQL()
+			require.NoError(t, err)
+			assert.Equal(t, parsedSQL, sql)
+			assert.Equal(t, len(parsedArgs), len(args))
+		})
+	}
+
+	assert.Nil(t, And())
+	assert.Nil(t, Or(nil))
+	assert.Nil(t, Not(nil))
+
+	sql, args, err := And().ToSQL()
+	assert.NoError(t, err)
+	assert.Empty(t, sql)
+	assert.Nil(t, args)
+
+	// 空集合
+	sql, _, err = In[int]("id").ToSQL()
+	require.NoError(t, err)
+	assert.Equal(t, "1 = 0", sql)
+}
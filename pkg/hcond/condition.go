@@ -1,28 +1,105 @@
 package hcond
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
 // 包级错误变量定义
 var (
-	ErrRHSNotSlice = fmt.Errorf("RHS must be a slice for IN operator")
+	ErrRHSNotSlice         = fmt.Errorf("RHS must be a slice for IN operator")
+	ErrInvalidBetween      = fmt.Errorf("RHS must be a slice of two values for BETWEEN operator")
 	ErrUnsupportedOperator = fmt.Errorf("unsupported operator")
 )
 
 // Condition 表示一个条件节点：可以是原子条件，也可以是一个子条件组
- type Condition struct {
-	Operator   string      `json:"operator"`   // 支持 =, !=, >, <, IN, &&, || 等
+type Condition struct {
+	Operator   string      `json:"operator"`   // 支持 ==, !=, >, <, IN, LIKE, BETWEEN, IS NULL, &&, ||, ! 等，见 op.go
 	LHS        string      `json:"lhs"`        // 左值字段名（仅在原子条件中使用）
 	RHS        interface{} `json:"rhs"`        // 右值（仅在原子条件中使用）
 	Conditions []Condition `json:"conditions"` // 子条件（仅在逻辑条件中使用）
 }
 
-func (c *Condition) ToSQL() (string, []interface{}, error) {
+// Option ToSQL 的可选参数
+type Option func(*options)
+
+type options struct {
+	schema  *Schema
+	dialect Dialect
+	// keepBindVars 保留 ? 占位符，由调用方（GORM）按数据库替换
+	keepBindVars bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{dialect: DefaultDialect()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.dialect == nil {
+		o.dialect = legacyDialect{}
+	}
+	return o
+}
+
+// columnOf 返回字段在 SQL 中的列名，有 Schema 时只允许声明过的字段
+func (o *options) columnOf(name string) (string, error) {
+	if o.schema != nil {
+		f, ok := o.schema.fields[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %q", ErrFieldNotAllowed, name)
+		}
+		return o.dialect.QuoteIdent(f.Column), nil
+	}
+	if !IsIdentifier(name) {
+		return "", fmt.Errorf("%w: %q", ErrFieldNotAllowed, name)
+	}
+	if _, ok := o.dialect.(legacyDialect); ok {
+		return name, nil
+	}
+	return o.dialect.QuoteIdent(name), nil
+}
+
+// WithSchema 只允许使用 Schema 中声明的字段和运算符，字段名转换为加引号的列名。
+// 条件来自客户端时必须指定 Schema，否则只校验字段名是否为合法标识符
+func WithSchema(s *Schema) Option {
+	return func(o *options) {
+		o.schema = s
+	}
+}
+
+// ToSQL 将条件转换为 SQL 片段和参数，占位符和引号由方言决定，
+// 依次使用 WithDialect、SetDefaultDialect 指定的方言，都没有指定时按 MySQL 语法生成并且字段名不加引号；
+// nil 条件返回空字符串
+func (c *Condition) ToSQL(opts ...Option) (string, []interface{}, error) {
+	if c == nil {
+		return "", nil, nil
+	}
+	o := newOptions(opts)
+	sql, args, err := c.toSQL(o)
+	if err != nil {
+		return "", nil, err
+	}
+	if o.keepBindVars {
+		return sql, args, nil
+	}
+	return rebind(o.dialect, sql), args, nil
+}
+
+func (c *Condition) toSQL(o *options) (string, []interface{}, error) {
+	if Op(c.Operator) == OpNot {
+		return c.toNotSQL(o)
+	}
 	if len(c.Conditions) == 0 {
 		// 原子条件
-		sql, args, err := c.toAtomicSQL()
+		column, err := c.column(o)
+		if err != nil {
+			return "", nil, err
+		}
+		atomic := *c
+		atomic.LHS = column
+		sql, args, err := atomic.toAtomicSQL(o.dialect)
 		if err != nil {
 			return "", nil, err
 		}
@@ -34,7 +111,7 @@ func (c *Condition) ToSQL() (string, []interface{}, error) {
 	var args []interface{}
 
 	for _, sub := range c.Conditions {
-		sql, subArgs, err := sub.ToSQL()
+		sql, subArgs, err := sub.toSQL(o)
 		if err != nil {
 			return "", nil, err
 		}
@@ -50,7 +127,35 @@ func (c *Condition) ToSQL() (string, []interface{}, error) {
 	return fmt.Sprintf("(%s)", strings.Join(clauses, " "+op+" ")), args, nil
 }
 
-func (c *Condition) toAtomicSQL() (string, []interface{}, error) {
+// column 返回原子条件在 SQL 中的列名
+func (c *Condition) column(o *options) (string, error) {
+	if o.schema != nil {
+		f, err := o.schema.resolve(c)
+		if err != nil {
+			return "", err
+		}
+		return o.dialect.QuoteIdent(f.Column), nil
+	}
+	return o.columnOf(c.LHS)
+}
+
+// toNotSQL 取反条件只有一个子条件
+func (c *Condition) toNotSQL(o *options) (string, []interface{}, error) {
+	if len(c.Conditions) != 1 {
+		return "", nil, fmt.Errorf("%w: %s requires exactly one sub condition", ErrUnsupportedOperator, c.Operator)
+	}
+	sql, args, err := c.Conditions[0].toSQL(o)
+	if err != nil {
+		return "", nil, err
+	}
+	if !strings.HasPrefix(sql, "(") {
+		sql = "(" + sql + ")"
+	}
+	return "NOT " + sql, args, nil
+}
+
+// toAtomicSQL 生成原子条件的 SQL，占位符统一使用 ?，由 ToSQL 替换为方言的占位符
+func (c *Condition) toAtomicSQL(d Dialect) (string, []interface{}, error) {
 	const (
 		Equal        = "="
 		NotEqual     = "!="
@@ -62,20 +167,90 @@ func (c *Condition) toAtomicSQL() (string, []interface{}, error) {
 	)
 
 	switch c.Operator {
-	case Equal, NotEqual, GreaterThan, LessThan, GreaterEqual, LessEqual:
+	case string(OpEq), Equal, NotEqual:
+		sqlOp := Equal
+		if c.Operator == NotEqual {
+			sqlOp = NotEqual
+		}
+		// 与 null 比较转换为 IS NULL / IS NOT NULL
+		if c.RHS == nil {
+			if sqlOp == Equal {
+				return fmt.Sprintf("%s IS NULL", c.LHS), nil, nil
+			}
+			return fmt.Sprintf("%s IS NOT NULL", c.LHS), nil, nil
+		}
+		return fmt.Sprintf("%s %s ?", c.LHS, sqlOp), []interface{}{c.RHS}, nil
+	case GreaterThan, LessThan, GreaterEqual, LessEqual:
 		return fmt.Sprintf("%s %s ?", c.LHS, c.Operator), []interface{}{c.RHS}, nil
-	case In:
-		values, ok := c.RHS.([]interface{})
+	case In, string(OpNotIn):
+		values, ok := toSlice(c.RHS)
 		if !ok {
 			return "", nil, ErrRHSNotSlice
 		}
+		// 空列表：IN 恒为假，NOT IN 恒为真
+		if len(values) == 0 {
+			return d.Bool(c.Operator != In), nil, nil
+		}
 		placeholders := make([]string, len(values))
 		for i := range values {
 			placeholders[i] = "?"
 		}
-		return fmt.Sprintf("%s IN (%s)", c.LHS, strings.Join(placeholders, ", ")), values, nil
+		return fmt.Sprintf("%s %s (%s)", c.LHS, c.Operator, strings.Join(placeholders, ", ")), values, nil
+	case string(OpLike), string(OpNotLike):
+		return fmt.Sprintf("%s %s ?%s", c.LHS, c.Operator, d.LikeEscape()), []interface{}{c.RHS}, nil
+	case string(OpILike):
+		return d.ILike(c.LHS), []interface{}{c.RHS}, nil
+	case string(OpBetween):
+		values, ok := toSlice(c.RHS)
+		if !ok || len(values) != 2 {
+			return "", nil, ErrInvalidBetween
+		}
+		return fmt.Sprintf("%s BETWEEN ? AND ?", c.LHS), values, nil
+	case string(OpIsNull), string(OpIsNotNull):
+		return fmt.Sprintf("%s %s", c.LHS, c.Operator), nil, nil
+	case string(OpRegexp):
+		return d.Regexp(c.LHS), []interface{}{c.RHS}, nil
+	case string(OpContains):
+		return d.JSONContains(c.LHS, c.RHS)
 	default:
 		return "", nil, fmt.Errorf("%w: %s", ErrUnsupportedOperator, c.Operator)
 	}
 }
 
+// toSlice 将任意切片或数组转换为 []interface{}，[]byte 不视为切片
+func toSlice(v interface{}) ([]interface{}, bool) {
+	if values, ok := v.([]interface{}); ok {
+		return values, true
+	}
+	rv := reflect.ValueOf(v)
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, true
+}
+
+// jsonMarshal 将值序列化为 JSON 字符串，用于 JSON 包含的参数
+func jsonMarshal(v interface{}) (string, error) {
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+func isMap(v interface{}) bool {
+	return v != nil && reflect.ValueOf(v).Kind() == reflect.Map
+}
+
+// EscapeLike 转义 LIKE 模式中的 %、_ 和 \，用于将用户输入作为普通文本匹配，例如
+//
+//	Condition{Operator: "LIKE", LHS: "name", RHS: "%" + EscapeLike(keyword) + "%"}
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -102,7 +102,7 @@ func TestCondition_toAtomicSQL(t *testing.T) {
 	}
 	expectedErrInvalidOp := fmt.Errorf("unsupported operator: INVALID")
 
-	actualSQL, actualArgs, actualErr := condInvalidOp.toAtomicSQL()
+	actualSQL, actualArgs, actualErr := condInvalidOp.toAtomicSQL(legacyDialect{})
 	if actualErr == nil || errors.Is(actualErr, expectedErrInvalidOp) {
 		t.Errorf("期望错误: %v, 实际错误: %v", expectedErrInvalidOp, actualErr)
 	}
@@ -121,7 +121,7 @@ func TestCondition_toAtomicSQL(t *testing.T) {
 	}
 
 	// 使用包级错误变量进行比较
-	actualSQL, actualArgs, actualErr = condInvalidIn.toAtomicSQL()
+	actualSQL, actualArgs, actualErr = condInvalidIn.toAtomicSQL(legacyDialect{})
 	assert.ErrorIs(t, actualErr, ErrRHSNotSlice)
 	assert.Empty(t, actualSQL)
 	assert.Nil(t, actualArgs)
@@ -0,0 +1,223 @@
+package hcond
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Dialect SQL 方言，负责占位符、标识符引号、布尔字面量以及各数据库语法不同的运算符
+type Dialect interface {
+	// Name 方言名称，与 config.DbConfig.Dialect 对应
+	Name() string
+	// Placeholder 第 n 个参数的占位符，n 从 1 开始
+	Placeholder(n int) string
+	// QuoteIdent 给标识符加引号，表名和列名用点号分隔时分别加引号
+	QuoteIdent(name string) string
+	// Bool 布尔字面量
+	Bool(b bool) string
+	// LikeEscape LIKE 的转义子句，数据库默认以 \ 作为转义字符时返回空字符串
+	LikeEscape() string
+	// ILike 不区分大小写的 LIKE，占位符使用 ?
+	ILike(column string) string
+	// Regexp 正则匹配，占位符使用 ?
+	Regexp(column string) string
+	// JSONContains JSON 包含，占位符使用 ?
+	JSONContains(column string, value interface{}) (string, []interface{}, error)
+}
+
+var (
+	MySQL    Dialect = mysqlDialect{}
+	Postgres Dialect = postgresDialect{}
+	SQLite   Dialect = sqliteDialect{}
+)
+
+// DialectOf 根据名称返回方言，名称不区分大小写，例如 mysql、postgres、sqlite
+func DialectOf(name string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "mysql", "tidb":
+		return MySQL, nil
+	case "postgres", "postgresql", "pg", "pgx":
+		return Postgres, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	default:
+		return nil, fmt.Errorf("hcond: unknown dialect %q", name)
+	}
+}
+
+var defaultDialect atomic.Pointer[Dialect]
+
+// SetDefaultDialect 设置 ToSQL 未指定 WithDialect 时使用的方言，对整个进程生效，
+// 传入 nil 恢复为兼容模式：MySQL 语法，字段名不加引号。
+// App 不会修改默认方言，db.dialect 配置的方言注册在 App 的容器中，通过 hdi.Resolve[hcond.Dialect] 获取后传给 WithDialect
+func SetDefaultDialect(d Dialect) {
+	if d == nil {
+		defaultDialect.Store(nil)
+		return
+	}
+	defaultDialect.Store(&d)
+}
+
+// DefaultDialect 返回默认方言，未设置时返回 nil
+func DefaultDialect() Dialect {
+	if d := defaultDialect.Load(); d != nil {
+		return *d
+	}
+	return nil
+}
+
+// WithDialect 指定 SQL 方言
+func WithDialect(d Dialect) Option {
+	return func(o *options) {
+		o.dialect = d
+	}
+}
+
+// rebind 将 ? 占位符替换为方言的占位符，hcond 生成的 SQL 中 ? 只会作为占位符出现
+func rebind(d Dialect, sql string) string {
+	if d.Placeholder(1) == "?" || !strings.Contains(sql, "?") {
+		return sql
+	}
+	var b strings.Builder
+	n := 0
+	for i := 0; i < len(sql); i++ {
+		if sql[i] == '?' {
+			n++
+			b.WriteString(d.Placeholder(n))
+			continue
+		}
+		b.WriteByte(sql[i])
+	}
+	return b.String()
+}
+
+func quoteParts(name string, quote byte) string {
+	parts := strings.Split(name, ".")
+	q := string(quote)
+	for i, p := range parts {
+		parts[i] = q + strings.ReplaceAll(p, q, q+q) + q
+	}
+	return strings.Join(parts, ".")
+}
+
+func jsonArg(value interface{}) (string, []interface{}, error) {
+	doc, err := jsonMarshal(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return "?", []interface{}{doc}, nil
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string                  { return "mysql" }
+func (mysqlDialect) Placeholder(int) string        { return "?" }
+func (mysqlDialect) QuoteIdent(name string) string { return quoteParts(name, '`') }
+func (mysqlDialect) LikeEscape() string            { return "" }
+func (mysqlDialect) Regexp(column string) string   { return column + " REGEXP ?" }
+
+func (mysqlDialect) ILike(column string) string {
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", column)
+}
+
+func (mysqlDialect) Bool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (mysqlDialect) JSONContains(column string, value interface{}) (string, []interface{}, error) {
+	ph, args, err := jsonArg(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("JSON_CONTAINS(%s, %s)", column, ph), args, nil
+}
+
+// legacyDialect 未指定方言时的渲染方式，与之前的版本保持一致：
+// MySQL 语法，没有 Schema 时字段名不加引号，空 IN 渲染为 1 = 0
+type legacyDialect struct {
+	mysqlDialect
+}
+
+func (legacyDialect) Name() string { return "" }
+
+func (legacyDialect) Bool(b bool) string {
+	if b {
+		return "1 = 1"
+	}
+	return "1 = 0"
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string                  { return "postgres" }
+func (postgresDialect) Placeholder(n int) string      { return "$" + strconv.Itoa(n) }
+func (postgresDialect) QuoteIdent(name string) string { return quoteParts(name, '"') }
+func (postgresDialect) LikeEscape() string            { return "" }
+func (postgresDialect) ILike(column string) string    { return column + " ILIKE ?" }
+func (postgresDialect) Regexp(column string) string   { return column + " ~ ?" }
+
+func (postgresDialect) Bool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// JSONContains 使用 jsonb 的 @> 运算符，列需要是 jsonb 类型
+func (postgresDialect) JSONContains(column string, value interface{}) (string, []interface{}, error) {
+	ph, args, err := jsonArg(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s @> %s::jsonb", column, ph), args, nil
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string                  { return "sqlite" }
+func (sqliteDialect) Placeholder(int) string        { return "?" }
+func (sqliteDialect) QuoteIdent(name string) string { return quoteParts(name, '"') }
+
+// LikeEscape SQLite 的 LIKE 没有默认的转义字符
+func (sqliteDialect) LikeEscape() string { return ` ESCAPE '\'` }
+
+// ILike SQLite 的 LIKE 对 ASCII 字符本身不区分大小写
+func (d sqliteDialect) ILike(column string) string { return column + " LIKE ?" + d.LikeEscape() }
+
+// Regexp 需要在连接上注册 regexp 函数
+func (sqliteDialect) Regexp(column string) string { return column + " REGEXP ?" }
+
+func (sqliteDialect) Bool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// JSONContains 通过 json_each 判断 JSON 数组是否包含候选值，候选值为数组时需要包含每个元素，
+// 不支持对象之间的包含
+func (sqliteDialect) JSONContains(column string, value interface{}) (string, []interface{}, error) {
+	values, ok := toSlice(value)
+	if !ok {
+		values = []interface{}{value}
+	}
+	if len(values) == 0 {
+		return "1", nil, nil
+	}
+	clauses := make([]string, len(values))
+	for i, v := range values {
+		if _, isSlice := toSlice(v); isSlice || isMap(v) {
+			return "", nil, fmt.Errorf("%w: sqlite CONTAINS only supports scalar values", ErrUnsupportedOperator)
+		}
+		clauses[i] = fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) WHERE json_each.value = ?)", column)
+	}
+	if len(clauses) == 1 {
+		return clauses[0], values, nil
+	}
+	return "(" + strings.Join(clauses, " AND ") + ")", values, nil
+}
@@ -0,0 +1,107 @@
+package hcond
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// dialectCases 每个方言的 golden 文件都包含这些表达式的渲染结果
+var dialectCases = []string{
+	`age >= 18 && vip == true`,
+	`city IN ["sz", "bj"] || city NOT IN []`,
+	`id IN []`,
+	`name LIKE "a\\%%" && name NOT LIKE "%b"`,
+	`name ILIKE "alice%"`,
+	`age BETWEEN 18 AND 30`,
+	`deleted_at IS NULL && profile.city IS NOT NULL`,
+	`deleted_at == null || deleted_at != null`,
+	`email =~ "@qq\\.com$"`,
+	`tags CONTAINS "vip"`,
+	`tags CONTAINS ["vip", "new"]`,
+	`!(u.age < 18 || score > 99.5)`,
+}
+
+func TestDialectGolden(t *testing.T) {
+	for _, d := range []Dialect{MySQL, Postgres, SQLite} {
+		t.Run(d.Name(), func(t *testing.T) {
+			var b strings.Builder
+			for _, expr := range dialectCases {
+				sql, args, err := MustParse(expr).ToSQL(WithDialect(d))
+				require.NoError(t, err, expr)
+				fmt.Fprintf(&b, "-- %s\n%s\n%#v\n\n", expr, sql, args)
+			}
+
+			assertGolden(t, "dialect_"+d.Name()+".golden", b.String())
+		})
+	}
+}
+
+// assertGolden 与 testdata 中的 golden 文件比较，go test -update 时重新生成 golden 文件
+func assertGolden(t *testing.T, name, actual string) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		require.NoError(t, os.WriteFile(golden, []byte(actual), 0o644))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), actual)
+}
+
+func TestDialectOf(t *testing.T) {
+	for name, expected := range map[string]Dialect{
+		"mysql":      MySQL,
+		"MySQL":      MySQL,
+		"postgres":   Postgres,
+		"postgresql": Postgres,
+		"sqlite3":    SQLite,
+	} {
+		d, err := DialectOf(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, d, name)
+	}
+
+	_, err := DialectOf("oracle")
+	assert.Error(t, err)
+}
+
+func TestDefaultDialect(t *testing.T) {
+	cond := MustParse(`age > 18 && name == "a"`)
+
+	sql, _, err := cond.ToSQL()
+	require.NoError(t, err)
+	assert.Equal(t, "(age > ? AND name = ?)", sql)
+
+	SetDefaultDialect(Postgres)
+	defer SetDefaultDialect(nil)
+	sql, _, err = cond.ToSQL()
+	require.NoError(t, err)
+	assert.Equal(t, `("age" > $1 AND "name" = $2)`, sql)
+
+	// WithDialect 优先于默认方言
+	sql, _, err = cond.ToSQL(WithDialect(MySQL))
+	require.NoError(t, err)
+	assert.Equal(t, "(`age` > ? AND `name` = ?)", sql)
+}
+
+func TestDialectWithSchema(t *testing.T) {
+	s := MustSchemaOf(schemaUser{})
+	sql, args, err := MustParse(`age BETWEEN 18 AND 30 && name ILIKE "a%"`).ToSQL(WithSchema(s), WithDialect(Postgres))
+	require.NoError(t, err)
+	assert.Equal(t, `("u"."age" BETWEEN $1 AND $2 AND "user_name" ILIKE $3)`, sql)
+	assert.Equal(t, []interface{}{int64(18), int64(30), "a%"}, args)
+
+	contains := Condition{Operator: "CONTAINS", LHS: "attrs", RHS: map[string]interface{}{"a": 1}}
+	_, _, err = contains.ToSQL(WithDialect(SQLite))
+	assert.ErrorIs(t, err, ErrUnsupportedOperator)
+}
@@ -0,0 +1,158 @@
+package hcond
+
+import (
+	"strings"
+)
+
+// ESQuery Elasticsearch 的查询 DSL，可以直接序列化为 JSON 作为请求体中的 query
+type ESQuery = map[string]interface{}
+
+// ES Elasticsearch 后端，逻辑条件转换为 bool 查询（AND 使用 filter，不计算相关性），
+// 原子条件转换为 term、terms、range、exists、wildcard、regexp 查询
+var ES Backend[ESQuery] = esBackend{}
+
+// ToES 将条件转换为 Elasticsearch 查询 DSL，支持 WithSchema 校验字段并映射为 ES 字段名
+func (c *Condition) ToES(opts ...Option) (ESQuery, error) {
+	return Translate(c, ES, opts...)
+}
+
+type esBackend struct{}
+
+func (esBackend) Name() string { return "elasticsearch" }
+
+func (esBackend) Atom(f Field, op Op, value interface{}) (ESQuery, error) {
+	field := f.Column
+	switch op {
+	case OpEq:
+		if value == nil {
+			return esNot(esExists(field)), nil
+		}
+		return ESQuery{"term": ESQuery{field: value}}, nil
+	case OpNotEq:
+		if value == nil {
+			return esExists(field), nil
+		}
+		return esNot(ESQuery{"term": ESQuery{field: value}}), nil
+	case OpGt, OpLt, OpGte, OpLte:
+		key := map[Op]string{OpGt: "gt", OpLt: "lt", OpGte: "gte", OpLte: "lte"}[op]
+		return ESQuery{"range": ESQuery{field: ESQuery{key: value}}}, nil
+	case OpIn, OpNotIn:
+		values, ok := toSlice(value)
+		if !ok {
+			return nil, ErrRHSNotSlice
+		}
+		q := ESQuery{"terms": ESQuery{field: values}}
+		// 空列表：IN 恒为假，NOT IN 恒为真
+		if len(values) == 0 {
+			q = ESQuery{"match_none": ESQuery{}}
+		}
+		if op == OpNotIn {
+			return esNot(q), nil
+		}
+		return q, nil
+	case OpLike, OpNotLike, OpILike:
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, unsupported(op, "pattern must be a string")
+		}
+		wildcard := ESQuery{"value": esWildcard(pattern)}
+		if op == OpILike {
+			wildcard["case_insensitive"] = true
+		}
+		q := ESQuery{"wildcard": ESQuery{field: wildcard}}
+		if op == OpNotLike {
+			return esNot(q), nil
+		}
+		return q, nil
+	case OpBetween:
+		values, ok := toSlice(value)
+		if !ok || len(values) != 2 {
+			return nil, ErrInvalidBetween
+		}
+		return ESQuery{"range": ESQuery{field: ESQuery{"gte": values[0], "lte": values[1]}}}, nil
+	case OpIsNull:
+		return esNot(esExists(field)), nil
+	case OpIsNotNull:
+		return esExists(field), nil
+	case OpRegexp:
+		// ES 的正则总是匹配整个词项，语法是 Lucene 正则的子集
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, unsupported(op, "pattern must be a string")
+		}
+		return ESQuery{"regexp": ESQuery{field: ESQuery{"value": pattern}}}, nil
+	case OpContains:
+		// 数组字段包含候选值的每个元素
+		values, ok := toSlice(value)
+		if !ok {
+			values = []interface{}{value}
+		}
+		subs := make([]ESQuery, 0, len(values))
+		for _, v := range values {
+			if _, nested := toSlice(v); nested || isMap(v) || v == nil {
+				return nil, unsupported(op, "only scalar values are supported")
+			}
+			subs = append(subs, ESQuery{"term": ESQuery{field: v}})
+		}
+		if len(subs) == 1 {
+			return subs[0], nil
+		}
+		return esBool("filter", subs), nil
+	default:
+		return nil, unsupported(op, "")
+	}
+}
+
+func (esBackend) And(subs []ESQuery) (ESQuery, error) {
+	return esBool("filter", subs), nil
+}
+
+func (esBackend) Or(subs []ESQuery) (ESQuery, error) {
+	q := esBool("should", subs)
+	q["bool"].(ESQuery)["minimum_should_match"] = 1
+	return q, nil
+}
+
+func (esBackend) Not(sub ESQuery) (ESQuery, error) {
+	return esNot(sub), nil
+}
+
+func esBool(occur string, subs []ESQuery) ESQuery {
+	return ESQuery{"bool": ESQuery{occur: subs}}
+}
+
+func esNot(q ESQuery) ESQuery {
+	return esBool("must_not", []ESQuery{q})
+}
+
+func esExists(field string) ESQuery {
+	return ESQuery{"exists": ESQuery{"field": field}}
+}
+
+// esWildcard 将 LIKE 模式转换为 ES 的 wildcard 模式：% 转换为 *，_ 转换为 ?，
+// 原本的 * ? 以及 LIKE 中转义的 % _ 按普通字符匹配
+func esWildcard(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '%':
+			b.WriteByte('*')
+		case '_':
+			b.WriteByte('?')
+		case '*', '?':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			if next := pattern[i]; next == '*' || next == '?' || next == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(pattern[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
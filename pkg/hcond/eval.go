@@ -0,0 +1,451 @@
+package hcond
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// ErrTypeMismatch 字段值无法转换为比较值的类型
+var ErrTypeMismatch = errors.New("type mismatch")
+
+// Eval 在内存中对 target 求值，target 可以是 map[string]any、结构体或它们的指针
+// 字段路径用点号分隔，例如 user.profile.city，切片可以使用下标，例如 tags.0；
+// 结构体字段按 hcond 标签、json 标签、字段名（不区分大小写）的顺序匹配。
+// 不存在的字段视为 null，字段值按比较值的类型通过 cast 转换后再比较
+func (c *Condition) Eval(target interface{}) (bool, error) {
+	switch op := Op(c.Operator); {
+	case op == OpNot:
+		if len(c.Conditions) != 1 {
+			return false, fmt.Errorf("%w: %s requires exactly one sub condition", ErrUnsupportedOperator, c.Operator)
+		}
+		ok, err := c.Conditions[0].Eval(target)
+		return !ok, err
+	case len(c.Conditions) > 0:
+		// 与 ToSQL 保持一致，除 || 以外的逻辑条件都按 AND 处理
+		isOr := op == OpOr
+		for i := range c.Conditions {
+			ok, err := c.Conditions[i].Eval(target)
+			if err != nil {
+				return false, err
+			}
+			if ok == isOr {
+				return isOr, nil
+			}
+		}
+		return !isOr, nil
+	default:
+		value, _ := Lookup(target, c.LHS)
+		return c.evalAtomic(value)
+	}
+}
+
+func (c *Condition) evalAtomic(value interface{}) (bool, error) {
+	switch c.Operator {
+	case string(OpEq), "=":
+		return equal(value, c.RHS), nil
+	case string(OpNotEq):
+		return !equal(value, c.RHS), nil
+	case string(OpGt), string(OpLt), string(OpGte), string(OpLte):
+		if value == nil || c.RHS == nil {
+			return false, nil
+		}
+		cmp, err := compare(value, c.RHS)
+		if err != nil {
+			return false, fmt.Errorf("hcond: field %q: %w", c.LHS, err)
+		}
+		switch Op(c.Operator) {
+		case OpGt:
+			return cmp > 0, nil
+		case OpLt:
+			return cmp < 0, nil
+		case OpGte:
+			return cmp >= 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+	case string(OpIn), string(OpNotIn):
+		values, ok := toSlice(c.RHS)
+		if !ok {
+			return false, ErrRHSNotSlice
+		}
+		found := false
+		for _, v := range values {
+			if equal(value, v) {
+				found = true
+				break
+			}
+		}
+		return found == (c.Operator == string(OpIn)), nil
+	case string(OpLike), string(OpNotLike), string(OpILike):
+		if value == nil {
+			return false, nil
+		}
+		re, err := likePattern(cast.ToString(c.RHS), c.Operator == string(OpILike))
+		if err != nil {
+			return false, fmt.Errorf("hcond: field %q: %w", c.LHS, err)
+		}
+		matched := re.MatchString(cast.ToString(value))
+		return matched == (c.Operator != string(OpNotLike)), nil
+	case string(OpRegexp):
+		if value == nil {
+			return false, nil
+		}
+		re, err := compileRegexp(cast.ToString(c.RHS))
+		if err != nil {
+			return false, fmt.Errorf("hcond: field %q: %w", c.LHS, err)
+		}
+		return re.MatchString(cast.ToString(value)), nil
+	case string(OpBetween):
+		values, ok := toSlice(c.RHS)
+		if !ok || len(values) != 2 {
+			return false, ErrInvalidBetween
+		}
+		if value == nil || values[0] == nil || values[1] == nil {
+			return false, nil
+		}
+		low, err := compare(value, values[0])
+		if err != nil {
+			return false, fmt.Errorf("hcond: field %q: %w", c.LHS, err)
+		}
+		high, err := compare(value, values[1])
+		if err != nil {
+			return false, fmt.Errorf("hcond: field %q: %w", c.LHS, err)
+		}
+		return low >= 0 && high <= 0, nil
+	case string(OpIsNull):
+		return value == nil, nil
+	case string(OpIsNotNull):
+		return value != nil, nil
+	case string(OpContains):
+		if value == nil {
+			return false, nil
+		}
+		doc, err := jsonValue(value)
+		if err != nil {
+			return false, fmt.Errorf("hcond: field %q: %w", c.LHS, err)
+		}
+		candidate, err := jsonValue(c.RHS)
+		if err != nil {
+			return false, fmt.Errorf("hcond: field %q: %w", c.LHS, err)
+		}
+		return jsonContains(doc, candidate), nil
+	default:
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedOperator, c.Operator)
+	}
+}
+
+// likePattern 将 LIKE 模式转换为正则表达式，% 匹配任意字符串，_ 匹配单个字符，\ 转义下一个字符
+func likePattern(pattern string, fold bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	if fold {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("(?s)^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return compileRegexp(b.String())
+}
+
+// regexpCache 缓存编译后的正则表达式，同一个条件通常会对大量数据求值
+var regexpCache sync.Map
+
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexpCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexpCache.Store(expr, re)
+	return re, nil
+}
+
+// jsonValue 将值转换为 JSON 解码后的通用形式，字符串和 []byte 按 JSON 文档解析，
+// 无法解析的字符串视为 JSON 字符串
+func jsonValue(v interface{}) (interface{}, error) {
+	var data []byte
+	switch val := v.(type) {
+	case string:
+		if !json.Valid([]byte(val)) {
+			return val, nil
+		}
+		data = []byte(val)
+	case []byte:
+		data = val
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// jsonContains 与 MySQL JSON_CONTAINS 语义一致：数组包含候选数组的每个元素，
+// 或者包含候选标量；对象包含候选对象的每个键值；标量之间比较是否相等
+func jsonContains(doc, candidate interface{}) bool {
+	switch d := doc.(type) {
+	case []interface{}:
+		if c, ok := candidate.([]interface{}); ok {
+			for _, item := range c {
+				if !jsonContains(d, item) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, item := range d {
+			if jsonContains(item, candidate) {
+				return true
+			}
+		}
+		return false
+	case map[string]interface{}:
+		c, ok := candidate.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range c {
+			dv, ok := d[k]
+			if !ok || !jsonContains(dv, v) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(doc, candidate)
+	}
+}
+
+// equal 将 value 转换为 rhs 的类型后比较是否相等，无法转换时视为不相等
+func equal(value, rhs interface{}) bool {
+	if value == nil || rhs == nil {
+		return value == nil && rhs == nil
+	}
+	cmp, err := compare(value, rhs)
+	return err == nil && cmp == 0
+}
+
+// compare 将 value 转换为 rhs 的类型后比较大小，返回 -1、0、1
+func compare(value, rhs interface{}) (int, error) {
+	// 时间字段与字符串比较时按时间解析字符串，例如 created_at > "2024-01-01"
+	if v, ok := value.(time.Time); ok {
+		if s, ok := rhs.(string); ok {
+			r, err := cast.ToTimeE(s)
+			if err != nil {
+				return 0, mismatch(value, rhs)
+			}
+			return v.Compare(r), nil
+		}
+	}
+
+	switch r := rhs.(type) {
+	case string:
+		v, err := cast.ToStringE(value)
+		if err != nil {
+			return 0, mismatch(value, rhs)
+		}
+		return strings.Compare(v, r), nil
+	case bool:
+		v, err := cast.ToBoolE(value)
+		if err != nil {
+			return 0, mismatch(value, rhs)
+		}
+		switch {
+		case v == r:
+			return 0, nil
+		case !v:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	case time.Time:
+		v, err := cast.ToTimeE(value)
+		if err != nil {
+			return 0, mismatch(value, rhs)
+		}
+		return v.Compare(r), nil
+	}
+
+	rk := reflect.ValueOf(rhs).Kind()
+	switch {
+	case rk >= reflect.Int && rk <= reflect.Uint64 && isInteger(value):
+		// 整数之间按 int64 比较，避免大整数转换为浮点数时丢失精度
+		v, errV := toInt(value)
+		r, errR := cast.ToInt64E(rhs)
+		if errV == nil && errR == nil {
+			return cmpOrdered(v, r), nil
+		}
+		fallthrough
+	case rk >= reflect.Int && rk <= reflect.Float64:
+		v, err := toFloat(value)
+		if err != nil {
+			return 0, mismatch(value, rhs)
+		}
+		r, err := cast.ToFloat64E(rhs)
+		if err != nil {
+			return 0, mismatch(value, rhs)
+		}
+		return cmpOrdered(v, r), nil
+	}
+	return 0, mismatch(value, rhs)
+}
+
+// toInt 转换为整数，字符串按十进制解析
+func toInt(value interface{}) (int64, error) {
+	if s, ok := value.(string); ok {
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	}
+	return cast.ToInt64E(value)
+}
+
+// toFloat 转换为浮点数，字符串按十进制解析（cast 会把 "08" 之类的字符串当作八进制）
+func toFloat(value interface{}) (float64, error) {
+	if s, ok := value.(string); ok {
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	}
+	return cast.ToFloat64E(value)
+}
+
+// isInteger value 是否为整数或者整数字符串
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		_, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return err == nil
+	default:
+		k := reflect.ValueOf(value).Kind()
+		return k >= reflect.Int && k <= reflect.Uint64
+	}
+}
+
+func cmpOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func mismatch(value, rhs interface{}) error {
+	return fmt.Errorf("%w: cannot compare %T with %T", ErrTypeMismatch, value, rhs)
+}
+
+// Lookup 按点号分隔的路径从 map、结构体或切片中取值，路径不存在时返回 false
+func Lookup(target interface{}, path string) (interface{}, bool) {
+	v := reflect.ValueOf(target)
+	for _, key := range strings.Split(path, ".") {
+		v = indirect(v)
+		if !v.IsValid() {
+			return nil, false
+		}
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			v = v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+		case reflect.Struct:
+			v = structField(v, key)
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= v.Len() {
+				return nil, false
+			}
+			v = v.Index(i)
+		default:
+			return nil, false
+		}
+		if !v.IsValid() {
+			return nil, false
+		}
+	}
+
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, true
+	}
+	return v.Interface(), true
+}
+
+// indirect 解开指针和接口，nil 指针返回无效值
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// structField 按 hcond 标签、json 标签、字段名的顺序查找导出字段
+func structField(v reflect.Value, name string) reflect.Value {
+	t := v.Type()
+	var byName reflect.Value
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if tagName(f.Tag.Get("hcond")) == name || tagName(f.Tag.Get("json")) == name {
+			return v.Field(i)
+		}
+		if !byName.IsValid() && strings.EqualFold(f.Name, name) {
+			byName = v.Field(i)
+		}
+	}
+	if byName.IsValid() {
+		return byName
+	}
+
+	// 查找嵌入结构体的字段
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).Anonymous {
+			continue
+		}
+		if embedded := indirect(v.Field(i)); embedded.Kind() == reflect.Struct {
+			if field := structField(embedded, name); field.IsValid() {
+				return field
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	return name
+}
@@ -0,0 +1,116 @@
+package hcond
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type evalProfile struct {
+	City string `json:"city"`
+}
+
+type evalBase struct {
+	ID int64
+}
+
+type evalUser struct {
+	evalBase
+	Age       int          `json:"age"`
+	VIP       bool         `hcond:"vip" json:"is_vip"`
+	Score     float64      `json:"score,omitempty"`
+	Tags      []string     `json:"tags"`
+	Profile   *evalProfile `json:"profile"`
+	CreatedAt time.Time    `json:"created_at"`
+	secret    string
+}
+
+func TestEval(t *testing.T) {
+	user := &evalUser{
+		evalBase:  evalBase{ID: 9007199254740993},
+		Age:       20,
+		VIP:       true,
+		Score:     88.5,
+		Tags:      []string{"new", "sz"},
+		Profile:   &evalProfile{City: "sz"},
+		CreatedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		secret:    "x",
+	}
+	m := map[string]interface{}{
+		"age":     "20", // 字符串按数值比较
+		"vip":     "true",
+		"score":   88.5,
+		"tags":    []interface{}{"new", "sz"},
+		"profile": map[string]interface{}{"city": "sz"},
+		"id":      int64(9007199254740993),
+	}
+
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{`age >= 18 && (profile.city IN ["sz","bj"] || vip == true)`, true},
+		{`age > 20`, false},
+		{`age == 20 && age != 21 && age <= 20 && age < 21`, true},
+		{`score > 88 && score < 89.0`, true},
+		{`vip == true`, true},
+		{`!(vip == false)`, true},
+		{`vip == false || age < 18`, false},
+		{`profile.city == "sz"`, true},
+		{`tags.1 == "sz"`, true},
+		{`tags.5 == null`, true},
+		{`missing == null && missing != 1`, true},
+		{`missing > 1`, false},
+		{`age IN [18, 19, 20]`, true},
+		{`age IN []`, false},
+		{`id == 9007199254740993 && id != 9007199254740992`, true},
+		{`city == "sz"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond := MustParse(tt.expr)
+			for name, target := range map[string]interface{}{"struct": user, "map": m} {
+				got, err := cond.Eval(target)
+				require.NoError(t, err, name)
+				assert.Equal(t, tt.expected, got, name)
+			}
+		})
+	}
+
+	// 结构体专有的字段
+	ok, err := MustParse(`created_at >= "2024-01-01" && is_vip == true && ID > 0 && secret == null`).Eval(user)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestEvalError(t *testing.T) {
+	_, err := MustParse(`age > 18`).Eval(map[string]interface{}{"age": "abc"})
+	assert.True(t, errors.Is(err, ErrTypeMismatch))
+	assert.EqualError(t, err, `hcond: field "age": type mismatch: cannot compare string with int64`)
+
+	// 无法转换时视为不相等
+	ok, err := MustParse(`age == 18`).Eval(map[string]interface{}{"age": "abc"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = (&Condition{Operator: "IN", LHS: "a", RHS: 1}).Eval(nil)
+	assert.ErrorIs(t, err, ErrRHSNotSlice)
+	_, err = (&Condition{Operator: "~", LHS: "a", RHS: 1}).Eval(nil)
+	assert.ErrorIs(t, err, ErrUnsupportedOperator)
+}
+
+func TestLookup(t *testing.T) {
+	v, ok := Lookup(map[string]interface{}{"a": map[string]int{"b": 1}}, "a.b")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	_, ok = Lookup(map[string]interface{}{"a": 1}, "a.b")
+	assert.False(t, ok)
+
+	v, ok = Lookup(&evalUser{}, "profile")
+	assert.True(t, ok)
+	assert.Nil(t, v)
+}
@@ -0,0 +1,113 @@
+package hcond
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// String 将条件转换为表达式字符串，结果可以再次被 Parse 解析为相同的条件
+func (c Condition) String() string {
+	var b strings.Builder
+	c.writeTo(&b)
+	return b.String()
+}
+
+func (c Condition) writeTo(b *strings.Builder) {
+	switch op := Op(c.Operator); {
+	case op == OpNot:
+		b.WriteString("!")
+		if len(c.Conditions) == 1 {
+			sub := c.Conditions[0]
+			// 原子条件和逻辑条件都加括号，取反条件可以直接嵌套
+			if Op(sub.Operator) == OpNot {
+				sub.writeTo(b)
+			} else {
+				b.WriteString("(")
+				sub.writeTo(b)
+				b.WriteString(")")
+			}
+		}
+	case len(c.Conditions) > 0:
+		sep := " && "
+		if op == OpOr {
+			sep = " || "
+		}
+		for i, sub := range c.Conditions {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			// 子条件是逻辑条件时加括号，保证优先级和结构不变
+			if len(sub.Conditions) > 0 && Op(sub.Operator) != OpNot {
+				b.WriteString("(")
+				sub.writeTo(b)
+				b.WriteString(")")
+			} else {
+				sub.writeTo(b)
+			}
+		}
+	case op == OpIsNull || op == OpIsNotNull:
+		b.WriteString(c.LHS)
+		b.WriteString(" ")
+		b.WriteString(c.Operator)
+	case op == OpBetween:
+		b.WriteString(c.LHS)
+		b.WriteString(" BETWEEN ")
+		if values, ok := toSlice(c.RHS); ok && len(values) == 2 {
+			writeValue(b, values[0])
+			b.WriteString(" AND ")
+			writeValue(b, values[1])
+		} else {
+			writeValue(b, c.RHS)
+		}
+	default:
+		b.WriteString(c.LHS)
+		b.WriteString(" ")
+		b.WriteString(string(normalizeOp(c.Operator)))
+		b.WriteString(" ")
+		writeValue(b, c.RHS)
+	}
+}
+
+// writeValue 输出表达式中的值，字符串使用双引号
+func writeValue(b *strings.Builder, v interface{}) {
+	switch val := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case string:
+		b.WriteString(strconv.Quote(val))
+	case bool:
+		b.WriteString(strconv.FormatBool(val))
+	case float32:
+		b.WriteString(formatFloat(float64(val)))
+	case float64:
+		b.WriteString(formatFloat(val))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		fmt.Fprintf(b, "%d", val)
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			b.WriteString("[")
+			for i := 0; i < rv.Len(); i++ {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				writeValue(b, rv.Index(i).Interface())
+			}
+			b.WriteString("]")
+			return
+		}
+		// 其他类型按字符串输出
+		b.WriteString(strconv.Quote(fmt.Sprint(v)))
+	}
+}
+
+// formatFloat 小数保证带小数点，避免再次解析时变成整数
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
@@ -4,16 +4,91 @@ import (
 	"gorm.io/gorm"
 )
 
-// Conditioner 定义一个接口，确保传入的条件对象实现了 ToSQL 方法
+// Conditioner 定义一个接口，确保传入的条件对象实现了 ToSQL 方法，*Condition 实现了该接口
 type Conditioner interface {
-	ToSQL() (string, []interface{})
+	ToSQL(opts ...Option) (string, []interface{}, error)
 }
 
-// BuildWhereClause 根据条件对象构建 GORM 的 Where 子句
-func BuildWhereClause(db *gorm.DB, cond Conditioner) *gorm.DB {
-	sql, args := cond.ToSQL()
+// BuildWhereClause 根据条件对象构建 GORM 的 Where 子句，生成 SQL 失败时错误记录到 db.Error。
+// 字段名按方言加引号，占位符始终为 ?，由 GORM 按数据库替换并与其他条件的参数一起编号
+func BuildWhereClause(db *gorm.DB, cond Conditioner, opts ...Option) *gorm.DB {
+	if cond == nil {
+		return db
+	}
+	sql, args, err := cond.ToSQL(append(opts[:len(opts):len(opts)], keepBindVars())...)
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
 	if sql == "" {
 		return db
 	}
 	return db.Where(sql, args...)
 }
+
+// keepBindVars 生成 ? 占位符，交给 GORM 绑定参数
+func keepBindVars() Option {
+	return func(o *options) {
+		o.keepBindVars = true
+	}
+}
+
+// Scope 返回过滤条件的 GORM scope，例如 db.Scopes(hcond.Scope(cond, hcond.WithSchema(s))).Find(&users)
+func Scope(cond Conditioner, opts ...Option) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return BuildWhereClause(db, cond, opts...)
+	}
+}
+
+// OrderBy 返回排序的 GORM scope，有 Schema 时只允许按声明过的字段排序
+func OrderBy(sorts []Sort, opts ...Option) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(sorts) == 0 {
+			return db
+		}
+		order, err := orderSQL(sorts, newOptions(opts))
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Order(order)
+	}
+}
+
+// Paginate 返回分页的 GORM scope，page 从 1 开始
+func Paginate(page, pageSize int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		offset, limit := Offset(page, pageSize)
+		return db.Offset(offset).Limit(limit)
+	}
+}
+
+// Select 返回指定查询字段的 GORM scope，有 Schema 时只允许查询声明过的字段
+func Select(fields []string, opts ...Option) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(fields) == 0 {
+			return db
+		}
+		columns, err := selectSQL(fields, newOptions(opts))
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Select(columns)
+	}
+}
+
+// Scopes 将 Query 转换为 GORM scope，例如
+//
+//	db.Model(&User{}).Scopes(query.Scopes(hcond.WithSchema(schema))...).Find(&users)
+func (q *Query) Scopes(opts ...Option) []func(*gorm.DB) *gorm.DB {
+	scopes := []func(*gorm.DB) *gorm.DB{
+		Scope(q.Filter, opts...),
+		OrderBy(q.Sort, opts...),
+		Select(q.Fields, opts...),
+	}
+	if q.Page > 0 {
+		scopes = append(scopes, Paginate(q.Page, q.PageSize))
+	}
+	return scopes
+}
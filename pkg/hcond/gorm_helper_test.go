@@ -0,0 +1,102 @@
+package hcond
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+type gormUser struct {
+	ID       int64  `json:"id"`
+	UserName string `json:"name" gorm:"column:user_name"`
+	Age      int    `json:"age"`
+	City     string `json:"city"`
+	Password string `hcond:"-"`
+}
+
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	return db
+}
+
+func TestBuildWhereClause(t *testing.T) {
+	db := dryRunDB(t)
+
+	var conds Conditioner = And(Gte("age", 18), In("city", "sz", "bj"))
+	stmt := BuildWhereClause(db.Model(&gormUser{}), conds).Find(&[]gormUser{}).Statement
+	assert.Equal(t, "SELECT * FROM `gorm_users` WHERE (age >= ? AND city IN (?, ?))", stmt.SQL.String())
+	assert.Equal(t, []interface{}{18, "sz", "bj"}, stmt.Vars)
+
+	// nil 条件不添加 WHERE
+	stmt = BuildWhereClause(db.Model(&gormUser{}), And()).Find(&[]gormUser{}).Statement
+	assert.Equal(t, "SELECT * FROM `gorm_users`", stmt.SQL.String())
+
+	// Postgres 方言只影响引号，占位符交给 GORM 与其他条件一起编号
+	stmt = db.Model(&gormUser{}).Scopes(Scope(And(Gte("age", 18), Eq("id", 3)), WithDialect(Postgres))).
+		Where("id > ?", 1).Find(&[]gormUser{}).Statement
+	assert.Equal(t, "SELECT * FROM `gorm_users` WHERE id > ? AND ((\"age\" >= ? AND \"id\" = ?))", stmt.SQL.String())
+	assert.Equal(t, []interface{}{1, 18, 3}, stmt.Vars)
+
+	// 生成 SQL 失败时错误记录到 db.Error
+	tx := BuildWhereClause(db.Model(&gormUser{}), Eq("1=1 OR id", 1)).Find(&[]gormUser{})
+	assert.ErrorIs(t, tx.Error, ErrFieldNotAllowed)
+}
+
+func TestQueryScopes(t *testing.T) {
+	db := dryRunDB(t)
+	schema := MustSchemaOf(gormUser{})
+
+	query := Query{
+		Filter:   And(Gte("age", 18), Like("name", "a%")),
+		Sort:     ParseSort("-age,name"),
+		Page:     3,
+		PageSize: 10,
+		Fields:   []string{"id", "name"},
+	}
+	stmt := db.Model(&gormUser{}).Scopes(query.Scopes(WithSchema(schema), WithDialect(MySQL))...).Find(&[]gormUser{}).Statement
+	assert.NoError(t, stmt.Error)
+	assert.Equal(t, "SELECT `id`, `user_name` FROM `gorm_users` WHERE (`age` >= ? AND `user_name` LIKE ?) ORDER BY `age` DESC, `user_name` LIMIT ? OFFSET ?", stmt.SQL.String())
+	assert.Equal(t, []interface{}{18, "a%", 10, 20}, stmt.Vars)
+
+	// 不在 Schema 中的字段不能用于排序和查询
+	for _, q := range []Query{
+		{Sort: []Sort{{Field: "password"}}},
+		{Fields: []string{"password"}},
+		{Filter: Eq("password", "x")},
+	} {
+		tx := db.Model(&gormUser{}).Scopes(q.Scopes(WithSchema(schema))...).Find(&[]gormUser{})
+		assert.ErrorIs(t, tx.Error, ErrFieldNotAllowed)
+	}
+
+	// 没有指定页码时不分页
+	stmt = db.Model(&gormUser{}).Scopes((&Query{Sort: ParseSort("id")}).Scopes()...).Find(&[]gormUser{}).Statement
+	assert.Equal(t, "SELECT * FROM `gorm_users` ORDER BY id", stmt.SQL.String())
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		page, pageSize int
+		offset, limit  int
+	}{
+		{1, 10, 0, 10},
+		{3, 10, 20, 10},
+		{0, 0, 0, DefaultPageSize},
+		{2, MaxPageSize + 1, MaxPageSize, MaxPageSize},
+	}
+	for _, tt := range tests {
+		offset, limit := Offset(tt.page, tt.pageSize)
+		assert.Equal(t, tt.offset, offset)
+		assert.Equal(t, tt.limit, limit)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	sorts := ParseSort(" -created_at, +name,,age ")
+	assert.Equal(t, []Sort{{Field: "created_at", Desc: true}, {Field: "name"}, {Field: "age"}}, sorts)
+	assert.Equal(t, "-created_at", sorts[0].String())
+	assert.Nil(t, ParseSort(""))
+}
@@ -0,0 +1,240 @@
+package hcond
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenTrue
+	tokenFalse
+	tokenNull
+	tokenOp       // 比较运算符 == = != <> > < >= <=
+	tokenIn       // IN
+	tokenAnd      // && AND
+	tokenOr       // || OR
+	tokenNot      // ! NOT
+	tokenLike     // LIKE
+	tokenILike    // ILIKE
+	tokenBetween  // BETWEEN
+	tokenIs       // IS
+	tokenRegexp   // REGEXP =~
+	tokenContains // CONTAINS
+	tokenLParen   // (
+	tokenRParen   // )
+	tokenLBrack   // [
+	tokenRBrack   // ]
+	tokenComma    // ,
+)
+
+// token 词法单元，pos 为在表达式中的字节偏移
+type token struct {
+	kind tokenKind
+	text string // 原始文本，字符串类型为去掉引号和转义后的值
+	pos  int
+}
+
+// String 返回用于错误提示的描述
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// keywords 关键字，不区分大小写
+var keywords = map[string]tokenKind{
+	"in":       tokenIn,
+	"and":      tokenAnd,
+	"or":       tokenOr,
+	"not":      tokenNot,
+	"like":     tokenLike,
+	"ilike":    tokenILike,
+	"between":  tokenBetween,
+	"is":       tokenIs,
+	"regexp":   tokenRegexp,
+	"contains": tokenContains,
+	"true":     tokenTrue,
+	"false":    tokenFalse,
+	"null":     tokenNull,
+}
+
+// punctuations 单字符的分隔符
+var punctuations = map[byte]tokenKind{
+	'(': tokenLParen,
+	')': tokenRParen,
+	'[': tokenLBrack,
+	']': tokenRBrack,
+	',': tokenComma,
+}
+
+// lexer 将表达式拆分为词法单元
+type lexer struct {
+	expr string
+	pos  int
+}
+
+// next 返回下一个词法单元
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.expr) && isSpace(l.expr[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.expr) {
+		return token{kind: tokenEOF, pos: start}, nil
+	}
+
+	c := l.expr[l.pos]
+	switch {
+	case c == '(' || c == ')' || c == '[' || c == ']' || c == ',':
+		l.pos++
+		return token{kind: punctuations[c], text: string(c), pos: start}, nil
+	case c == '"' || c == '\'':
+		return l.lexString(c)
+	case c == '-' || c == '.' || isDigit(c):
+		return l.lexNumber()
+	case isIdentStart(c):
+		for l.pos < len(l.expr) && isIdentPart(l.expr[l.pos]) {
+			l.pos++
+		}
+		text := l.expr[start:l.pos]
+		if kind, ok := keywords[strings.ToLower(text)]; ok {
+			return token{kind: kind, text: text, pos: start}, nil
+		}
+		return token{kind: tokenIdent, text: text, pos: start}, nil
+	}
+
+	// 运算符，优先匹配两个字符的运算符
+	for _, op := range []string{"=~", "==", "!=", "<>", ">=", "<=", "&&", "||", "=", ">", "<", "!"} {
+		if strings.HasPrefix(l.expr[l.pos:], op) {
+			l.pos += len(op)
+			switch op {
+			case "&&":
+				return token{kind: tokenAnd, text: op, pos: start}, nil
+			case "||":
+				return token{kind: tokenOr, text: op, pos: start}, nil
+			case "!":
+				return token{kind: tokenNot, text: op, pos: start}, nil
+			case "=~":
+				return token{kind: tokenRegexp, text: op, pos: start}, nil
+			default:
+				return token{kind: tokenOp, text: op, pos: start}, nil
+			}
+		}
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.expr[l.pos:])
+	return token{}, l.errorf(start, "unexpected character %q", r)
+}
+
+// lexString 解析单引号或双引号字符串，支持 \\ \" \' \n \t 转义
+func (l *lexer) lexString(quote byte) (token, error) {
+	start := l.pos
+	l.pos++ // 跳过开头的引号
+	var b strings.Builder
+	for l.pos < len(l.expr) {
+		c := l.expr[l.pos]
+		switch {
+		case c == quote:
+			l.pos++
+			return token{kind: tokenString, text: b.String(), pos: start}, nil
+		case c == '\\':
+			if l.pos+1 >= len(l.expr) {
+				return token{}, l.errorf(l.pos, "unterminated escape sequence")
+			}
+			l.pos++
+			switch e := l.expr[l.pos]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '\\', '"', '\'':
+				b.WriteByte(e)
+			default:
+				return token{}, l.errorf(l.pos-1, "invalid escape sequence \\%c", e)
+			}
+			l.pos++
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, l.errorf(start, "unterminated string")
+}
+
+// lexNumber 解析整数或小数，允许负号
+func (l *lexer) lexNumber() (token, error) {
+	start := l.pos
+	if l.expr[l.pos] == '-' {
+		l.pos++
+	}
+	digits, dots := 0, 0
+	for l.pos < len(l.expr) {
+		c := l.expr[l.pos]
+		if isDigit(c) {
+			digits++
+		} else if c == '.' {
+			dots++
+		} else {
+			break
+		}
+		l.pos++
+	}
+	// 支持科学计数法，例如 1e3、2.5E-2
+	if digits > 0 && l.pos < len(l.expr) && (l.expr[l.pos] == 'e' || l.expr[l.pos] == 'E') {
+		l.pos++
+		if l.pos < len(l.expr) && (l.expr[l.pos] == '+' || l.expr[l.pos] == '-') {
+			l.pos++
+		}
+		expDigits := 0
+		for l.pos < len(l.expr) && isDigit(l.expr[l.pos]) {
+			l.pos++
+			expDigits++
+		}
+		if expDigits == 0 {
+			return token{}, l.errorf(start, "invalid number %q", l.expr[start:l.pos])
+		}
+	}
+	if digits == 0 || dots > 1 || (l.pos < len(l.expr) && isIdentPart(l.expr[l.pos])) {
+		for l.pos < len(l.expr) && isIdentPart(l.expr[l.pos]) {
+			l.pos++
+		}
+		return token{}, l.errorf(start, "invalid number %q", l.expr[start:l.pos])
+	}
+	return token{kind: tokenNumber, text: l.expr[start:l.pos], pos: start}, nil
+}
+
+func (l *lexer) errorf(pos int, format string, args ...interface{}) error {
+	return newSyntaxError(l.expr, pos, fmt.Sprintf(format, args...))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentPart 字段名可以包含点号，例如 user.age、profile.city
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '.'
+}
@@ -10,7 +10,16 @@ const (
 	OpGte   Op = ">="
 	OpLte   Op = "<="
 
-	OpIn Op = "IN"
+	OpIn        Op = "IN"
+	OpNotIn     Op = "NOT IN"      // 空列表恒为真
+	OpLike      Op = "LIKE"        // % 匹配任意字符，_ 匹配单个字符，\ 转义
+	OpNotLike   Op = "NOT LIKE"    // 与 LIKE 相反
+	OpILike     Op = "ILIKE"       // 不区分大小写的 LIKE
+	OpBetween   Op = "BETWEEN"     // RHS 为 [最小值, 最大值]，闭区间
+	OpIsNull    Op = "IS NULL"     // 不需要 RHS
+	OpIsNotNull Op = "IS NOT NULL" // 不需要 RHS
+	OpRegexp    Op = "REGEXP"      // 正则匹配，表达式中也可以写作 =~
+	OpContains  Op = "CONTAINS"    // JSON 包含，LHS 为 JSON 数组或对象
 
 	OpAnd Op = "&&"
 	OpOr  Op = "||"
@@ -0,0 +1,186 @@
+package hcond
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendedOperatorsToSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		cond     Condition
+		sql      string
+		args     []interface{}
+		hasError error
+	}{
+		{"not in", Condition{Operator: "NOT IN", LHS: "city", RHS: []string{"sz", "bj"}}, "city NOT IN (?, ?)", []interface{}{"sz", "bj"}, nil},
+		{"in typed slice", Condition{Operator: "IN", LHS: "id", RHS: []int{1, 2, 3}}, "id IN (?, ?, ?)", []interface{}{1, 2, 3}, nil},
+		{"in empty", Condition{Operator: "IN", LHS: "id", RHS: []int{}}, "1 = 0", nil, nil},
+		{"not in empty", Condition{Operator: "NOT IN", LHS: "id", RHS: []int{}}, "1 = 1", nil, nil},
+		{"in bytes", Condition{Operator: "IN", LHS: "id", RHS: []byte("ab")}, "", nil, ErrRHSNotSlice},
+		{"like", Condition{Operator: "LIKE", LHS: "name", RHS: "a%"}, "name LIKE ?", []interface{}{"a%"}, nil},
+		{"not like", Condition{Operator: "NOT LIKE", LHS: "name", RHS: "a%"}, "name NOT LIKE ?", []interface{}{"a%"}, nil},
+		{"ilike", Condition{Operator: "ILIKE", LHS: "name", RHS: "A%"}, "LOWER(name) LIKE LOWER(?)", []interface{}{"A%"}, nil},
+		{"between", Condition{Operator: "BETWEEN", LHS: "age", RHS: []int{18, 30}}, "age BETWEEN ? AND ?", []interface{}{18, 30}, nil},
+		{"between invalid", Condition{Operator: "BETWEEN", LHS: "age", RHS: []int{18}}, "", nil, ErrInvalidBetween},
+		{"is null", Condition{Operator: "IS NULL", LHS: "deleted_at"}, "deleted_at IS NULL", nil, nil},
+		{"is not null", Condition{Operator: "IS NOT NULL", LHS: "deleted_at"}, "deleted_at IS NOT NULL", nil, nil},
+		{"regexp", Condition{Operator: "REGEXP", LHS: "email", RHS: "@qq\\.com$"}, "email REGEXP ?", []interface{}{"@qq\\.com$"}, nil},
+		{"contains", Condition{Operator: "CONTAINS", LHS: "tags", RHS: []string{"vip"}}, "JSON_CONTAINS(tags, ?)", []interface{}{`["vip"]`}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.cond.ToSQL()
+			if tt.hasError != nil {
+				assert.ErrorIs(t, err, tt.hasError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.sql, sql)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestParseExtendedOperators(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+		sql      string
+	}{
+		{`city not in ["sz", "bj"]`, `city NOT IN ["sz", "bj"]`, "city NOT IN (?, ?)"},
+		{`name LIKE "a%"`, `name LIKE "a%"`, "name LIKE ?"},
+		{`name NOT LIKE 'a%'`, `name NOT LIKE "a%"`, "name NOT LIKE ?"},
+		{`name ilike "A%"`, `name ILIKE "A%"`, "LOWER(name) LIKE LOWER(?)"},
+		{`age BETWEEN 18 AND 30 && vip == true`, `age BETWEEN 18 AND 30 && vip == true`, "(age BETWEEN ? AND ? AND vip = ?)"},
+		{`deleted_at IS NULL`, `deleted_at IS NULL`, "deleted_at IS NULL"},
+		{`deleted_at is not null`, `deleted_at IS NOT NULL`, "deleted_at IS NOT NULL"},
+		{`email =~ "@qq\\.com$"`, `email REGEXP "@qq\\.com$"`, "email REGEXP ?"},
+		{`tags CONTAINS ["vip", "new"]`, `tags CONTAINS ["vip", "new"]`, "JSON_CONTAINS(tags, ?)"},
+		{`tags contains "vip"`, `tags CONTAINS "vip"`, "JSON_CONTAINS(tags, ?)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cond.String())
+
+			// 格式化后的字符串可以再次解析为相同的条件
+			again, err := Parse(cond.String())
+			require.NoError(t, err)
+			assert.Equal(t, cond, again)
+
+			sql, _, err := cond.ToSQL()
+			require.NoError(t, err)
+			assert.Equal(t, tt.sql, sql)
+		})
+	}
+}
+
+func TestParseExtendedOperatorsError(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column int
+	}{
+		{`city NOT == 1`, 10},
+		{`name LIKE 1`, 11},
+		{`age BETWEEN 1 OR 2`, 15},
+		{`deleted_at IS 1`, 15},
+		{`email =~ ["a"]`, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			var syntaxErr *SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, tt.column, syntaxErr.Column)
+		})
+	}
+}
+
+func TestEvalExtendedOperators(t *testing.T) {
+	target := map[string]interface{}{
+		"name":       "Alice_01",
+		"age":        25,
+		"city":       "sz",
+		"email":      "alice@qq.com",
+		"ids":        []int{1, 2},
+		"deleted_at": nil,
+		"tags":       []interface{}{"vip", "new"},
+		"attrs":      `{"level": 3, "labels": ["a", "b"]}`,
+	}
+
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{`city NOT IN ["bj", "sh"]`, true},
+		{`city NOT IN ["sz"]`, false},
+		{`name LIKE "Alice%"`, true},
+		{`name LIKE "alice%"`, false},
+		{`name ILIKE "alice%"`, true},
+		{`name LIKE "Alice\\_0_"`, true},
+		{`name LIKE "Alice\\%%"`, false},
+		{`name NOT LIKE "Bob%"`, true},
+		{`age BETWEEN 18 AND 25`, true},
+		{`age BETWEEN 26 AND 30`, false},
+		{`deleted_at IS NULL && missing IS NULL`, true},
+		{`email IS NOT NULL`, true},
+		{`email =~ "@qq\\.com$"`, true},
+		{`email REGEXP "^bob"`, false},
+		{`tags CONTAINS "vip"`, true},
+		{`tags CONTAINS ["new", "vip"]`, true},
+		{`tags CONTAINS ["vip", "old"]`, false},
+		{`attrs CONTAINS "a"`, false},
+		{`attrs.level IS NULL`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			ok, err := MustParse(tt.expr).Eval(target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+
+	// IN 支持任意类型的切片，空列表时 IN 恒为假、NOT IN 恒为真
+	in := Condition{Operator: "IN", LHS: "age", RHS: []int{20, 25}}
+	ok, err := in.Eval(target)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	empty := Condition{Operator: "NOT IN", LHS: "age", RHS: []string{}}
+	ok, err = empty.Eval(target)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// JSON 对象按键包含
+	contains := Condition{Operator: "CONTAINS", LHS: "attrs", RHS: map[string]interface{}{"labels": []string{"b"}}}
+	ok, err = contains.Eval(target)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = (&Condition{Operator: "REGEXP", LHS: "email", RHS: "("}).Eval(target)
+	assert.Error(t, err)
+
+	_, err = (&Condition{Operator: "BETWEEN", LHS: "age", RHS: 1}).Eval(target)
+	assert.ErrorIs(t, err, ErrInvalidBetween)
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `50\%\_off\\`, EscapeLike(`50%_off\`))
+
+	cond := Condition{Operator: "LIKE", LHS: "title", RHS: "%" + EscapeLike("50%") + "%"}
+	ok, err := cond.Eval(map[string]interface{}{"title": "save 50% today"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = cond.Eval(map[string]interface{}{"title": "save 500 today"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -2,10 +2,383 @@ package hcond
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
-// Parse 将 Condition 转换为 SQL WHERE 片段
-func Parse(cond Condition) (string, []interface{}, error) {
-	sql, args, err := cond.ToSQL()
+// SyntaxError 表达式语法错误，Offset 为出错位置的字节偏移，Line 和 Column 从 1 开始
+type SyntaxError struct {
+	Expr   string
+	Offset int
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error 实现 error 接口
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("hcond: syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Snippet 返回出错的行以及指向出错位置的标记，便于在界面上展示
+//
+//	age >= && vip == true
+//	       ^
+func (e *SyntaxError) Snippet() string {
+	lines := strings.Split(e.Expr, "\n")
+	if e.Line < 1 || e.Line > len(lines) {
+		return ""
+	}
+	// Column 按字符计数，不能作为字节下标使用
+	line := lines[e.Line-1]
+	return line + "\n" + strings.Repeat(" ", min(e.Column-1, len([]rune(line)))) + "^"
+}
+
+func newSyntaxError(expr string, offset int, msg string) *SyntaxError {
+	line, column := 1, 1
+	for _, r := range expr[:min(offset, len(expr))] {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return &SyntaxError{Expr: expr, Offset: offset, Line: line, Column: column, Msg: msg}
+}
+
+// Parse 将字符串表达式解析为 Condition 树，例如
+//
+//	age >= 18 && (city IN ["sz", "bj"] || vip == true)
+//
+// 支持的语法：
+//   - 比较：== = != <> > < >= <=，其中 = 等同于 ==，<> 等同于 !=
+//   - 集合：field IN [v1, v2]、field NOT IN [v1, v2]
+//   - 模式：field LIKE "a%"、field NOT LIKE "a%"、field ILIKE "a%"、field REGEXP "^a"（也可以写作 =~）
+//   - 区间：field BETWEEN 1 AND 10
+//   - 空值：field IS NULL、field IS NOT NULL
+//   - JSON：field CONTAINS "a"、field CONTAINS ["a", "b"]
+//   - 逻辑：&& || ! 以及不区分大小写的 AND OR NOT，优先级 ! > && > ||
+//   - 值：整数、小数、单双引号字符串、true、false、null
+func Parse(expr string) (*Condition, error) {
+	p := &parser{lex: &lexer{expr: expr}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenEOF {
+		return nil, p.errorf(p.tok, "empty expression")
+	}
+
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, p.errorf(p.tok, "unexpected %s", p.tok)
+	}
+	return cond, nil
+}
+
+// MustParse 解析表达式，失败时 panic，适合解析代码中的常量表达式
+func MustParse(expr string) *Condition {
+	cond, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return cond
+}
+
+// Where 将 Condition 转换为 SQL WHERE 片段
+func Where(cond Condition, opts ...Option) (string, []interface{}, error) {
+	sql, args, err := cond.ToSQL(opts...)
 	return fmt.Sprintf("WHERE %s", sql), args, err
 }
+
+// parser 递归下降解析器
+type parser struct {
+	lex *lexer
+	tok token // 当前词法单元
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// parseOr or := and (("||" | "OR") and)*
+func (p *parser) parseOr() (*Condition, error) {
+	return p.parseLogical(tokenOr, OpOr, p.parseAnd)
+}
+
+// parseAnd and := unary (("&&" | "AND") unary)*
+func (p *parser) parseAnd() (*Condition, error) {
+	return p.parseLogical(tokenAnd, OpAnd, p.parseUnary)
+}
+
+// parseLogical 解析同一优先级的逻辑运算，连续的同类运算合并为一个节点
+func (p *parser) parseLogical(kind tokenKind, op Op, next func() (*Condition, error)) (*Condition, error) {
+	first, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != kind {
+		return first, nil
+	}
+
+	cond := &Condition{Operator: string(op), Conditions: []Condition{*first}}
+	for p.tok.kind == kind {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		sub, err := next()
+		if err != nil {
+			return nil, err
+		}
+		cond.Conditions = append(cond.Conditions, *sub)
+	}
+	return cond, nil
+}
+
+// parseUnary unary := ("!" | "NOT") unary | primary
+func (p *parser) parseUnary() (*Condition, error) {
+	if p.tok.kind != tokenNot {
+		return p.parsePrimary()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	sub, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &Condition{Operator: string(OpNot), Conditions: []Condition{*sub}}, nil
+}
+
+// parsePrimary primary := "(" or ")" | comparison
+func (p *parser) parsePrimary() (*Condition, error) {
+	switch p.tok.kind {
+	case tokenLParen:
+		open := p.tok
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenRParen {
+			if p.tok.kind == tokenEOF {
+				return nil, p.errorf(open, "unclosed parenthesis")
+			}
+			return nil, p.errorf(p.tok, "expected \")\", got %s", p.tok)
+		}
+		return cond, p.advance()
+	case tokenIdent:
+		return p.parseComparison()
+	default:
+		return nil, p.errorf(p.tok, "expected field name or \"(\", got %s", p.tok)
+	}
+}
+
+// parseComparison comparison := field op value
+//
+//	| field ["NOT"] "IN" list
+//	| field ["NOT"] "LIKE" string | field "ILIKE" string
+//	| field "BETWEEN" value "AND" value
+//	| field "IS" ["NOT"] "NULL"
+//	| field ("REGEXP" | "=~") string
+//	| field "CONTAINS" (value | list)
+func (p *parser) parseComparison() (*Condition, error) {
+	field := p.tok.text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	opTok := p.tok
+	switch p.tok.kind {
+	case tokenOp:
+		op := normalizeOp(p.tok.text)
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return &Condition{Operator: string(op), LHS: field, RHS: value}, nil
+	case tokenNot:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		switch p.tok.kind {
+		case tokenIn:
+			return p.parseIn(field, OpNotIn)
+		case tokenLike:
+			return p.parseLike(field, OpNotLike)
+		default:
+			return nil, p.errorf(p.tok, "expected IN or LIKE after NOT, got %s", p.tok)
+		}
+	case tokenIn:
+		return p.parseIn(field, OpIn)
+	case tokenLike:
+		return p.parseLike(field, OpLike)
+	case tokenILike:
+		return p.parseLike(field, OpILike)
+	case tokenRegexp:
+		return p.parseLike(field, OpRegexp)
+	case tokenBetween:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		low, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenAnd {
+			return nil, p.errorf(p.tok, "expected AND in BETWEEN, got %s", p.tok)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		high, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return &Condition{Operator: string(OpBetween), LHS: field, RHS: []interface{}{low, high}}, nil
+	case tokenIs:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		op := OpIsNull
+		if p.tok.kind == tokenNot {
+			op = OpIsNotNull
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		if p.tok.kind != tokenNull {
+			return nil, p.errorf(p.tok, "expected NULL after %s, got %s", opTok.text, p.tok)
+		}
+		return &Condition{Operator: string(op), LHS: field}, p.advance()
+	case tokenContains:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var value interface{}
+		var err error
+		if p.tok.kind == tokenLBrack {
+			value, err = p.parseList()
+		} else {
+			value, err = p.parseValue()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Condition{Operator: string(OpContains), LHS: field, RHS: value}, nil
+	default:
+		return nil, p.errorf(p.tok, "expected operator after field %q, got %s", field, p.tok)
+	}
+}
+
+// parseIn 解析 IN 或 NOT IN 之后的列表
+func (p *parser) parseIn(field string, op Op) (*Condition, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	values, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	return &Condition{Operator: string(op), LHS: field, RHS: values}, nil
+}
+
+// parseLike 解析 LIKE、ILIKE、REGEXP 之后的字符串模式
+func (p *parser) parseLike(field string, op Op) (*Condition, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenString {
+		return nil, p.errorf(p.tok, "expected string pattern after %s, got %s", op, p.tok)
+	}
+	pattern := p.tok.text
+	return &Condition{Operator: string(op), LHS: field, RHS: pattern}, p.advance()
+}
+
+// parseList list := "[" [value ("," value)*] "]"
+func (p *parser) parseList() ([]interface{}, error) {
+	if p.tok.kind != tokenLBrack {
+		return nil, p.errorf(p.tok, "expected \"[\" after IN, got %s", p.tok)
+	}
+	open := p.tok
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	values := []interface{}{}
+	for p.tok.kind != tokenRBrack {
+		if len(values) > 0 {
+			if p.tok.kind != tokenComma {
+				if p.tok.kind == tokenEOF {
+					return nil, p.errorf(open, "unclosed list")
+				}
+				return nil, p.errorf(p.tok, "expected \",\" or \"]\", got %s", p.tok)
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, p.advance()
+}
+
+// parseValue value := number | string | true | false | null
+func (p *parser) parseValue() (interface{}, error) {
+	tok := p.tok
+	var value interface{}
+	switch tok.kind {
+	case tokenNumber:
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			value = i
+		} else if f, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			value = f
+		} else {
+			return nil, p.errorf(tok, "invalid number %q", tok.text)
+		}
+	case tokenString:
+		value = tok.text
+	case tokenTrue:
+		value = true
+	case tokenFalse:
+		value = false
+	case tokenNull:
+		value = nil
+	default:
+		return nil, p.errorf(tok, "expected value, got %s", tok)
+	}
+	return value, p.advance()
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return newSyntaxError(p.lex.expr, tok.pos, fmt.Sprintf(format, args...))
+}
+
+// normalizeOp 将同义的运算符统一为 op.go 中定义的运算符
+func normalizeOp(op string) Op {
+	switch op {
+	case "=":
+		return OpEq
+	case "<>":
+		return OpNotEq
+	default:
+		return Op(op)
+	}
+}
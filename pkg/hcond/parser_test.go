@@ -0,0 +1,137 @@
+package hcond
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cond, err := Parse(`age >= 18 && (city IN ["sz","bj"] || vip == true)`)
+	require.NoError(t, err)
+	assert.Equal(t, &Condition{
+		Operator: "&&",
+		Conditions: []Condition{
+			{Operator: ">=", LHS: "age", RHS: int64(18)},
+			{Operator: "||", Conditions: []Condition{
+				{Operator: "IN", LHS: "city", RHS: []interface{}{"sz", "bj"}},
+				{Operator: "==", LHS: "vip", RHS: true},
+			}},
+		},
+	}, cond)
+
+	sql, args, err := cond.ToSQL()
+	require.NoError(t, err)
+	assert.Equal(t, "(age >= ? AND (city IN (?, ?) OR vip = ?))", sql)
+	assert.Equal(t, []interface{}{int64(18), "sz", "bj", true}, args)
+}
+
+func TestParseSyntax(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected string // 解析后再输出的规范形式
+	}{
+		{"等号同义", `name = 'vayne'`, `name == "vayne"`},
+		{"不等号同义", `status <> 1`, `status != 1`},
+		{"关键字不区分大小写", `a == 1 and b == 2 OR not c == 3`, `(a == 1 && b == 2) || !(c == 3)`},
+		{"优先级", `a == 1 || b == 2 && c == 3`, `a == 1 || (b == 2 && c == 3)`},
+		{"连续的同类运算合并", `a == 1 && b == 2 && c == 3`, `a == 1 && b == 2 && c == 3`},
+		{"取反嵌套", `!!(a > 1)`, `!!(a > 1)`},
+		{"取反逻辑条件", `!(a > 1 || b < 2)`, `!(a > 1 || b < 2)`},
+		{"多余的括号", `((a >= -1.5))`, `a >= -1.5`},
+		{"字段名带点号", `user.age <= 30`, `user.age <= 30`},
+		{"转义字符串", `name == "a\"b\\c"`, `name == "a\"b\\c"`},
+		{"null 和空列表", `deleted_at == null && id IN []`, `deleted_at == null && id IN []`},
+		{"小数保留小数点", `score > 1.0`, `score > 1.0`},
+		{"科学计数法", `score > 1e3`, `score > 1000.0`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cond.String())
+
+			// 输出的表达式可以解析为相同的条件
+			again, err := Parse(cond.String())
+			require.NoError(t, err)
+			assert.Equal(t, cond, again)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		expr   string
+		line   int
+		column int
+		msg    string
+	}{
+		{``, 1, 1, "empty expression"},
+		{`age >= && vip == true`, 1, 8, `expected value, got "&&"`},
+		{`age 18`, 1, 5, `expected operator after field "age", got "18"`},
+		{`(age > 1`, 1, 1, "unclosed parenthesis"},
+		{`age > 1)`, 1, 8, `unexpected ")"`},
+		{`city IN "sz"`, 1, 9, `expected "[" after IN, got string "sz"`},
+		{`city IN ["sz" "bj"]`, 1, 15, `expected "," or "]", got string "bj"`},
+		{`city IN ["sz"`, 1, 9, "unclosed list"},
+		{`name == "abc`, 1, 9, "unterminated string"},
+		{`age > 1.2.3`, 1, 7, `invalid number "1.2.3"`},
+		{`age > 1 &&` + "\n" + `name ~ 1`, 2, 6, `unexpected character '~'`},
+		{`== 1`, 1, 1, `expected field name or "(", got "=="`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			var syntaxErr *SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "expected syntax error, got %v", err)
+			assert.Equal(t, tt.line, syntaxErr.Line)
+			assert.Equal(t, tt.column, syntaxErr.Column)
+			assert.Equal(t, tt.msg, syntaxErr.Msg)
+		})
+	}
+
+	_, err := Parse(`age >= && vip == true`)
+	assert.EqualError(t, err, `hcond: syntax error at line 1, column 8: expected value, got "&&"`)
+	assert.Equal(t, "age >= && vip == true\n       ^", err.(*SyntaxError).Snippet())
+
+	// 多字节字符按字符对齐
+	_, err = Parse(`name == "张三" && && vip`)
+	assert.EqualError(t, err, `hcond: syntax error at line 1, column 17: expected field name or "(", got "&&"`)
+	assert.Equal(t, "name == \"张三\" && && vip\n                ^", err.(*SyntaxError).Snippet())
+	_, err = Parse("a == 1 &&\nb == \"张三\" &&")
+	require.Error(t, err)
+	assert.Equal(t, "b == \"张三\" &&\n            ^", err.(*SyntaxError).Snippet())
+
+	assert.Panics(t, func() { MustParse("age >=") })
+}
+
+func TestToSQLOperators(t *testing.T) {
+	tests := []struct {
+		expr string
+		sql  string
+		args []interface{}
+	}{
+		{`a == 1`, "a = ?", []interface{}{int64(1)}},
+		{`a != "x"`, "a != ?", []interface{}{"x"}},
+		{`!(a == 1)`, "NOT (a = ?)", []interface{}{int64(1)}},
+		{`!(a == 1 || b == 2)`, "NOT (a = ? OR b = ?)", []interface{}{int64(1), int64(2)}},
+		{`a == null`, "a IS NULL", nil},
+		{`a != null`, "a IS NOT NULL", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			sql, args, err := MustParse(tt.expr).ToSQL()
+			require.NoError(t, err)
+			assert.Equal(t, tt.sql, sql)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+
+	sql, args, err := Where(*MustParse(`a == 1`))
+	require.NoError(t, err)
+	assert.Equal(t, "WHERE a = ?", sql)
+	assert.Equal(t, []interface{}{int64(1)}, args)
+}
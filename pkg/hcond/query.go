@@ -0,0 +1,100 @@
+package hcond
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// DefaultPageSize 未指定每页条数时的默认值
+	DefaultPageSize = 20
+	// MaxPageSize 每页条数的上限，避免客户端一次查询过多数据
+	MaxPageSize = 1000
+)
+
+// Sort 排序字段
+type Sort struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
+}
+
+// String 返回 ParseSort 可以解析的格式
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// ParseSort 解析逗号分隔的排序字段，字段前加 - 表示降序，加 + 或不加表示升序，例如 -created_at,name
+func ParseSort(s string) []Sort {
+	var sorts []Sort
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		sort := Sort{Field: part}
+		switch part[0] {
+		case '-':
+			sort = Sort{Field: part[1:], Desc: true}
+		case '+':
+			sort.Field = part[1:]
+		}
+		sorts = append(sorts, sort)
+	}
+	return sorts
+}
+
+// Query 列表查询的参数：过滤条件、排序、分页和返回字段，可以直接从请求的 JSON 中解析，
+// 通过 Scopes 转换为 GORM 的查询
+type Query struct {
+	Filter   *Condition `json:"filter,omitempty"`
+	Sort     []Sort     `json:"sort,omitempty"`
+	Page     int        `json:"page,omitempty"`      // 从 1 开始，为 0 时不分页
+	PageSize int        `json:"page_size,omitempty"` // 为 0 时使用 DefaultPageSize
+	Fields   []string   `json:"fields,omitempty"`    // 为空时返回所有字段
+}
+
+// Offset 返回分页的偏移量和条数，page 小于 1 时按第一页处理，pageSize 限制在 MaxPageSize 以内
+func Offset(page, pageSize int) (offset, limit int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return (page - 1) * pageSize, pageSize
+}
+
+// orderSQL 生成 ORDER BY 的内容，例如 `age` DESC, `name`
+func orderSQL(sorts []Sort, o *options) (string, error) {
+	clauses := make([]string, 0, len(sorts))
+	for _, s := range sorts {
+		column, err := o.columnOf(s.Field)
+		if err != nil {
+			return "", fmt.Errorf("hcond: sort: %w", err)
+		}
+		if s.Desc {
+			column += " DESC"
+		}
+		clauses = append(clauses, column)
+	}
+	return strings.Join(clauses, ", "), nil
+}
+
+// selectSQL 生成 SELECT 的字段列表
+func selectSQL(fields []string, o *options) (string, error) {
+	columns := make([]string, 0, len(fields))
+	for _, f := range fields {
+		column, err := o.columnOf(f)
+		if err != nil {
+			return "", fmt.Errorf("hcond: select: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	return strings.Join(columns, ", "), nil
+}
@@ -0,0 +1,241 @@
+package hcond
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// RediSearch RediSearch 后端，生成 FT.SEARCH 的查询字符串。
+// 数值类型的字段（Schema 中的 TypeInt、TypeFloat，没有 Schema 时根据右值推断）使用数值范围查询，
+// 其他字段按 TAG 字段查询；IS NULL 需要字段在索引中声明 INDEXMISSING
+var RediSearch Backend[string] = rediSearchBackend{}
+
+// ToRediSearch 将条件转换为 RediSearch 查询字符串，支持 WithSchema 校验字段并映射为索引字段名
+func (c *Condition) ToRediSearch(opts ...Option) (string, error) {
+	return Translate(c, RediSearch, opts...)
+}
+
+type rediSearchBackend struct{}
+
+func (rediSearchBackend) Name() string { return "redisearch" }
+
+func (b rediSearchBackend) Atom(f Field, op Op, value interface{}) (string, error) {
+	field := "@" + f.Column
+	switch op {
+	case OpEq, OpNotEq:
+		var q string
+		if value == nil {
+			q = fmt.Sprintf("ismissing(%s)", field)
+		} else {
+			var err error
+			if q, err = rsMatch(f, field, value); err != nil {
+				return "", err
+			}
+		}
+		if op == OpNotEq {
+			return "-" + q, nil
+		}
+		return q, nil
+	case OpGt, OpLt, OpGte, OpLte:
+		n, err := rsNumber(value)
+		if err != nil {
+			return "", unsupported(op, err.Error())
+		}
+		switch op {
+		case OpGt:
+			return fmt.Sprintf("%s:[(%s +inf]", field, n), nil
+		case OpGte:
+			return fmt.Sprintf("%s:[%s +inf]", field, n), nil
+		case OpLt:
+			return fmt.Sprintf("%s:[-inf (%s]", field, n), nil
+		default:
+			return fmt.Sprintf("%s:[-inf %s]", field, n), nil
+		}
+	case OpIn, OpNotIn:
+		values, ok := toSlice(value)
+		if !ok {
+			return "", ErrRHSNotSlice
+		}
+		if len(values) == 0 {
+			return "", unsupported(op, "empty list cannot be expressed")
+		}
+		q, err := rsIn(f, field, values)
+		if err != nil {
+			return "", err
+		}
+		if op == OpNotIn {
+			return "-" + q, nil
+		}
+		return q, nil
+	case OpLike, OpNotLike, OpILike:
+		// TAG 字段本身不区分大小写，只支持 % 通配符
+		pattern, ok := value.(string)
+		if !ok {
+			return "", unsupported(op, "pattern must be a string")
+		}
+		tag, err := rsWildcard(pattern)
+		if err != nil {
+			return "", unsupported(op, err.Error())
+		}
+		q := fmt.Sprintf("%s:{%s}", field, tag)
+		if op == OpNotLike {
+			return "-" + q, nil
+		}
+		return q, nil
+	case OpBetween:
+		values, ok := toSlice(value)
+		if !ok || len(values) != 2 {
+			return "", ErrInvalidBetween
+		}
+		low, err := rsNumber(values[0])
+		if err != nil {
+			return "", unsupported(op, err.Error())
+		}
+		high, err := rsNumber(values[1])
+		if err != nil {
+			return "", unsupported(op, err.Error())
+		}
+		return fmt.Sprintf("%s:[%s %s]", field, low, high), nil
+	case OpIsNull:
+		return fmt.Sprintf("ismissing(%s)", field), nil
+	case OpIsNotNull:
+		return fmt.Sprintf("-ismissing(%s)", field), nil
+	case OpContains:
+		// 多值 TAG 字段包含候选值的每个元素
+		values, ok := toSlice(value)
+		if !ok {
+			values = []interface{}{value}
+		}
+		subs := make([]string, 0, len(values))
+		for _, v := range values {
+			if _, nested := toSlice(v); nested || isMap(v) || v == nil {
+				return "", unsupported(op, "only scalar values are supported")
+			}
+			subs = append(subs, fmt.Sprintf("%s:{%s}", field, rsEscape(cast.ToString(v))))
+		}
+		return b.And(subs)
+	default:
+		return "", unsupported(op, "")
+	}
+}
+
+func (rediSearchBackend) And(subs []string) (string, error) {
+	if len(subs) == 1 {
+		return subs[0], nil
+	}
+	return "(" + strings.Join(subs, " ") + ")", nil
+}
+
+func (rediSearchBackend) Or(subs []string) (string, error) {
+	if len(subs) == 1 {
+		return subs[0], nil
+	}
+	return "(" + strings.Join(subs, " | ") + ")", nil
+}
+
+func (rediSearchBackend) Not(sub string) (string, error) {
+	if strings.HasPrefix(sub, "(") {
+		return "-" + sub, nil
+	}
+	return "-(" + sub + ")", nil
+}
+
+// rsNumeric 字段是否按数值查询
+func rsNumeric(f Field, value interface{}) bool {
+	switch f.Type {
+	case TypeInt, TypeFloat:
+		return true
+	case TypeAny:
+		k := reflect.ValueOf(value).Kind()
+		return k >= reflect.Int && k <= reflect.Float64
+	default:
+		return false
+	}
+}
+
+// rsMatch 等值匹配
+func rsMatch(f Field, field string, value interface{}) (string, error) {
+	if rsNumeric(f, value) {
+		n, err := rsNumber(value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s:[%s %s]", field, n, n), nil
+	}
+	return fmt.Sprintf("%s:{%s}", field, rsEscape(cast.ToString(value))), nil
+}
+
+// rsIn 数值字段转换为多个范围的 OR，TAG 字段使用 {a | b}
+func rsIn(f Field, field string, values []interface{}) (string, error) {
+	if rsNumeric(f, values[0]) {
+		subs := make([]string, 0, len(values))
+		for _, v := range values {
+			q, err := rsMatch(f, field, v)
+			if err != nil {
+				return "", err
+			}
+			subs = append(subs, q)
+		}
+		return rediSearchBackend{}.Or(subs)
+	}
+	tags := make([]string, len(values))
+	for i, v := range values {
+		tags[i] = rsEscape(cast.ToString(v))
+	}
+	return fmt.Sprintf("%s:{%s}", field, strings.Join(tags, " | ")), nil
+}
+
+func rsNumber(value interface{}) (string, error) {
+	f, err := toFloat(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: expects a number, got %T", ErrTypeMismatch, value)
+	}
+	return formatNumber(f), nil
+}
+
+func formatNumber(f float64) string {
+	return strings.TrimSuffix(formatFloat(f), ".0")
+}
+
+// rsEscape 转义 TAG 值中的标点和空格
+func rsEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(",.<>{}[]\"':;!@#$%^&*()-+=~|/\\ ", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// rsWildcard 将 LIKE 模式转换为 TAG 的前缀、后缀、中缀匹配，例如 abc% 转换为 abc*
+func rsWildcard(pattern string) (string, error) {
+	var b strings.Builder
+	var literal strings.Builder
+	flush := func() {
+		b.WriteString(rsEscape(literal.String()))
+		literal.Reset()
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '%':
+			flush()
+			b.WriteByte('*')
+		case '_':
+			return "", fmt.Errorf("single character wildcard _ is not supported")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			literal.WriteByte(pattern[i])
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+	return b.String(), nil
+}
@@ -0,0 +1,316 @@
+package hcond
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	// ErrFieldNotAllowed 字段没有在 Schema 中声明，或者字段名不是合法的标识符
+	ErrFieldNotAllowed = errors.New("field not allowed")
+	// ErrOperatorNotAllowed 字段不允许使用该运算符
+	ErrOperatorNotAllowed = errors.New("operator not allowed")
+)
+
+// ValueType 字段的值类型，用于校验条件中的右值
+type ValueType string
+
+const (
+	TypeAny    ValueType = "any"
+	TypeString ValueType = "string"
+	TypeInt    ValueType = "int"
+	TypeFloat  ValueType = "float"
+	TypeBool   ValueType = "bool"
+	TypeTime   ValueType = "time"
+	TypeJSON   ValueType = "json"
+)
+
+// typeOps 各类型默认允许的运算符
+var typeOps = map[ValueType][]Op{
+	TypeString: {OpEq, OpNotEq, OpGt, OpLt, OpGte, OpLte, OpIn, OpNotIn, OpLike, OpNotLike, OpILike, OpRegexp, OpIsNull, OpIsNotNull},
+	TypeInt:    {OpEq, OpNotEq, OpGt, OpLt, OpGte, OpLte, OpIn, OpNotIn, OpBetween, OpIsNull, OpIsNotNull},
+	TypeFloat:  {OpEq, OpNotEq, OpGt, OpLt, OpGte, OpLte, OpIn, OpNotIn, OpBetween, OpIsNull, OpIsNotNull},
+	TypeTime:   {OpEq, OpNotEq, OpGt, OpLt, OpGte, OpLte, OpIn, OpNotIn, OpBetween, OpIsNull, OpIsNotNull},
+	TypeBool:   {OpEq, OpNotEq, OpIsNull, OpIsNotNull},
+	TypeJSON:   {OpContains, OpIsNull, OpIsNotNull},
+}
+
+// Field 可以用于查询的字段
+type Field struct {
+	Name   string    // 条件中使用的逻辑字段名，即 Condition.LHS
+	Column string    // 数据库列名，可以带表名，例如 u.age，为空时与 Name 相同
+	Type   ValueType // 值类型，为空时不校验
+	Ops    []Op      // 允许的运算符，为空时使用 Type 的默认运算符
+}
+
+// allows 字段是否允许使用运算符
+func (f *Field) allows(op Op) bool {
+	ops := f.Ops
+	if len(ops) == 0 {
+		ops = typeOps[f.Type]
+	}
+	// TypeAny 没有默认运算符，不做限制
+	return len(ops) == 0 || slices.Contains(ops, op)
+}
+
+// check 校验右值能否转换为字段类型
+func (f *Field) check(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	var err error
+	switch f.Type {
+	case TypeString:
+		_, err = cast.ToStringE(value)
+	case TypeInt:
+		_, err = toInt(value)
+	case TypeFloat:
+		_, err = toFloat(value)
+	case TypeBool:
+		_, err = cast.ToBoolE(value)
+	case TypeTime:
+		_, err = cast.ToTimeE(value)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: field %q expects %s, got %T", ErrTypeMismatch, f.Name, f.Type, value)
+	}
+	return nil
+}
+
+// Schema 条件字段白名单，将逻辑字段名映射为加引号的列名，
+// 客户端传入的条件只能使用 Schema 中声明的字段和运算符
+type Schema struct {
+	fields map[string]*Field
+}
+
+// NewSchema 创建 Schema，字段名重复或者列名不是合法标识符时返回错误
+func NewSchema(fields ...Field) (*Schema, error) {
+	s := &Schema{fields: make(map[string]*Field, len(fields))}
+	for i := range fields {
+		f := fields[i]
+		if f.Column == "" {
+			f.Column = f.Name
+		}
+		if f.Type == "" {
+			f.Type = TypeAny
+		}
+		if f.Name == "" {
+			return nil, fmt.Errorf("hcond: schema field name is empty")
+		}
+		if _, ok := s.fields[f.Name]; ok {
+			return nil, fmt.Errorf("hcond: duplicate schema field %q", f.Name)
+		}
+		if !IsIdentifier(f.Column) {
+			return nil, fmt.Errorf("hcond: invalid column %q for field %q", f.Column, f.Name)
+		}
+		s.fields[f.Name] = &f
+	}
+	return s, nil
+}
+
+// MustNewSchema 创建 Schema，失败时 panic
+func MustNewSchema(fields ...Field) *Schema {
+	s, err := NewSchema(fields...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// SchemaOf 根据结构体生成 Schema，导出字段默认都允许查询，标签格式为
+//
+//	Age int `hcond:"age,column=u.age,ops=>|<|BETWEEN"`
+//
+// 字段名依次取 hcond 标签、json 标签、字段名的 snake_case，hcond:"-" 表示不允许查询；
+// 列名依次取 column 选项、gorm 标签的 column、字段名的 snake_case；
+// 类型根据 Go 类型推断，嵌入结构体的字段会展开
+func SchemaOf(v interface{}) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("hcond: SchemaOf expects a struct, got %T", v)
+	}
+	fields, err := structFields(t)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchema(fields...)
+}
+
+// MustSchemaOf 根据结构体生成 Schema，失败时 panic
+func MustSchemaOf(v interface{}) *Schema {
+	s, err := SchemaOf(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func structFields(t reflect.Type) ([]Field, error) {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("hcond")
+		if tag == "-" {
+			continue
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && ft.Kind() == reflect.Struct && tag == "" {
+			embedded, err := structFields(ft)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedded...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		f := Field{Name: tagName(tag), Type: valueTypeOf(ft)}
+		if f.Name == "" {
+			f.Name = tagName(sf.Tag.Get("json"))
+		}
+		if f.Name == "" || f.Name == "-" {
+			f.Name = toSnake(sf.Name)
+		}
+		_, opts, _ := strings.Cut(tag, ",")
+		for _, opt := range strings.Split(opts, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch key {
+			case "":
+			case "column":
+				f.Column = value
+			case "ops":
+				for _, op := range strings.Split(value, "|") {
+					f.Ops = append(f.Ops, normalizeOp(strings.ToUpper(strings.TrimSpace(op))))
+				}
+			default:
+				return nil, fmt.Errorf("hcond: unknown option %q in tag of field %s", key, sf.Name)
+			}
+		}
+		if f.Column == "" {
+			f.Column = gormColumn(sf.Tag.Get("gorm"))
+		}
+		if f.Column == "" {
+			f.Column = toSnake(sf.Name)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// valueTypeOf 根据 Go 类型推断值类型
+func valueTypeOf(t reflect.Type) ValueType {
+	if t == reflect.TypeOf(time.Time{}) {
+		return TypeTime
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return TypeString
+	case reflect.Bool:
+		return TypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypeInt
+	case reflect.Float32, reflect.Float64:
+		return TypeFloat
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		return TypeJSON
+	default:
+		return TypeAny
+	}
+}
+
+// gormColumn 取 gorm 标签中的 column，例如 gorm:"column:user_name;size:64"
+func gormColumn(tag string) string {
+	for _, part := range strings.Split(tag, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if ok && strings.EqualFold(key, "column") {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// toSnake 将 UserID 转换为 user_id
+func toSnake(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Field 返回逻辑字段名对应的字段
+func (s *Schema) Field(name string) (Field, bool) {
+	f, ok := s.fields[name]
+	if !ok {
+		return Field{}, false
+	}
+	return *f, true
+}
+
+// Fields 返回所有字段，按字段名排序
+func (s *Schema) Fields() []Field {
+	fields := make([]Field, 0, len(s.fields))
+	for _, f := range s.fields {
+		fields = append(fields, *f)
+	}
+	slices.SortFunc(fields, func(a, b Field) int { return strings.Compare(a.Name, b.Name) })
+	return fields
+}
+
+// resolve 校验原子条件的字段、运算符和右值，返回对应的字段
+func (s *Schema) resolve(c *Condition) (*Field, error) {
+	f, ok := s.fields[c.LHS]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrFieldNotAllowed, c.LHS)
+	}
+	op := normalizeOp(c.Operator)
+	if !f.allows(op) {
+		return nil, fmt.Errorf("%w: %s on field %q", ErrOperatorNotAllowed, c.Operator, c.LHS)
+	}
+	switch op {
+	case OpContains, OpIsNull, OpIsNotNull:
+	case OpIn, OpNotIn, OpBetween:
+		values, _ := toSlice(c.RHS)
+		for _, v := range values {
+			if err := f.check(v); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		if err := f.check(c.RHS); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// IsIdentifier 是否为合法的列名，允许用点号分隔表名和列名，例如 u.age
+func IsIdentifier(s string) bool {
+	return identRe.MatchString(s)
+}
@@ -0,0 +1,123 @@
+package hcond
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaBase struct {
+	ID        int64     `json:"id" gorm:"column:id;primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type schemaUser struct {
+	schemaBase
+	Name     string            `json:"name" gorm:"column:user_name;size:64"`
+	Age      int               `hcond:"age,column=u.age,ops=>=|<=|BETWEEN"`
+	VIP      bool              `json:"vip"`
+	Tags     []string          `json:"tags"`
+	Password string            `hcond:"-"`
+	Score    *float64          `json:"score,omitempty"`
+	Extra    map[string]string `json:"-"`
+	internal string
+}
+
+func TestSchemaOf(t *testing.T) {
+	s, err := SchemaOf(&schemaUser{})
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range s.Fields() {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"age", "created_at", "extra", "id", "name", "score", "tags", "vip"}, names)
+
+	age, ok := s.Field("age")
+	require.True(t, ok)
+	assert.Equal(t, Field{Name: "age", Column: "u.age", Type: TypeInt, Ops: []Op{OpGte, OpLte, OpBetween}}, age)
+
+	name, _ := s.Field("name")
+	assert.Equal(t, "user_name", name.Column)
+	assert.Equal(t, TypeString, name.Type)
+
+	score, _ := s.Field("score")
+	assert.Equal(t, TypeFloat, score.Type)
+
+	created, _ := s.Field("created_at")
+	assert.Equal(t, TypeTime, created.Type)
+
+	_, ok = s.Field("password")
+	assert.False(t, ok)
+
+	_, err = SchemaOf(1)
+	assert.Error(t, err)
+
+	_, err = SchemaOf(struct {
+		A int `hcond:"a,unknown=1"`
+	}{})
+	assert.Error(t, err)
+}
+
+func TestNewSchema(t *testing.T) {
+	_, err := NewSchema(Field{Name: "a"}, Field{Name: "a"})
+	assert.Error(t, err)
+
+	_, err = NewSchema(Field{Name: "a", Column: "a; DROP TABLE users"})
+	assert.Error(t, err)
+
+	s, err := NewSchema(Field{Name: "a"})
+	require.NoError(t, err)
+	f, _ := s.Field("a")
+	assert.Equal(t, Field{Name: "a", Column: "a", Type: TypeAny}, f)
+}
+
+func TestToSQLWithSchema(t *testing.T) {
+	s := MustSchemaOf(schemaUser{})
+
+	tests := []struct {
+		expr     string
+		sql      string
+		args     []interface{}
+		hasError error
+	}{
+		{`age BETWEEN 18 AND 30 && name LIKE "a%"`, "(`u`.`age` BETWEEN ? AND ? AND `user_name` LIKE ?)", []interface{}{int64(18), int64(30), "a%"}, nil},
+		{`id IN [1, 2] || !(vip == true)`, "(`id` IN (?, ?) OR NOT (`vip` = ?))", []interface{}{int64(1), int64(2), true}, nil},
+		{`tags CONTAINS "new" && score IS NULL`, "(JSON_CONTAINS(`tags`, ?) AND `score` IS NULL)", []interface{}{`"new"`}, nil},
+		{`created_at > "2024-01-01"`, "`created_at` > ?", []interface{}{"2024-01-01"}, nil},
+		{`password == "x"`, "", nil, ErrFieldNotAllowed},
+		{`age == 18`, "", nil, ErrOperatorNotAllowed},
+		{`vip LIKE "t%"`, "", nil, ErrOperatorNotAllowed},
+		{`id IN [1, "x"]`, "", nil, ErrTypeMismatch},
+		{`created_at > "yesterday"`, "", nil, ErrTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			sql, args, err := MustParse(tt.expr).ToSQL(WithSchema(s))
+			if tt.hasError != nil {
+				assert.ErrorIs(t, err, tt.hasError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.sql, sql)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestToSQLRejectsInvalidIdentifier(t *testing.T) {
+	// 没有 Schema 时也不允许把任意字符串拼接到 SQL 中
+	for _, lhs := range []string{"1=1 OR id", "name; DROP TABLE users", "`name`", "a..b", ""} {
+		cond := Condition{Operator: "==", LHS: lhs, RHS: 1}
+		_, _, err := cond.ToSQL()
+		assert.ErrorIs(t, err, ErrFieldNotAllowed, lhs)
+	}
+
+	cond := Condition{Operator: "==", LHS: "u.name", RHS: 1}
+	sql, _, err := cond.ToSQL()
+	require.NoError(t, err)
+	assert.Equal(t, "u.name = ?", sql)
+}
@@ -0,0 +1,124 @@
+package hcond
+
+import (
+	"fmt"
+)
+
+// Backend 条件的查询后端，将条件树转换为其他存储的查询，例如 Elasticsearch、RediSearch。
+// 新增存储时实现该接口并调用 Translate，逻辑条件的遍历、Schema 校验由 Translate 完成
+type Backend[Q any] interface {
+	// Name 后端名称，用于错误信息
+	Name() string
+	// Atom 转换原子条件，field.Column 为存储中的字段名，op 已经统一为 op.go 中定义的运算符
+	Atom(field Field, op Op, value interface{}) (Q, error)
+	// And 所有子查询都满足
+	And(subs []Q) (Q, error)
+	// Or 任意子查询满足
+	Or(subs []Q) (Q, error)
+	// Not 子查询取反
+	Not(sub Q) (Q, error)
+}
+
+// Translate 使用后端转换条件树，支持 WithSchema 校验字段并映射字段名
+func Translate[Q any](c *Condition, b Backend[Q], opts ...Option) (Q, error) {
+	var zero Q
+	if c == nil {
+		return zero, fmt.Errorf("hcond: %s: nil condition", b.Name())
+	}
+	q, err := translate(c, b, newOptions(opts))
+	if err != nil {
+		return zero, fmt.Errorf("hcond: %s: %w", b.Name(), err)
+	}
+	return q, nil
+}
+
+func translate[Q any](c *Condition, b Backend[Q], o *options) (Q, error) {
+	var zero Q
+	switch op := Op(c.Operator); {
+	case op == OpNot:
+		if len(c.Conditions) != 1 {
+			return zero, fmt.Errorf("%w: %s requires exactly one sub condition", ErrUnsupportedOperator, c.Operator)
+		}
+		sub, err := translate(&c.Conditions[0], b, o)
+		if err != nil {
+			return zero, err
+		}
+		return b.Not(sub)
+	case len(c.Conditions) > 0:
+		subs := make([]Q, 0, len(c.Conditions))
+		for i := range c.Conditions {
+			sub, err := translate(&c.Conditions[i], b, o)
+			if err != nil {
+				return zero, err
+			}
+			subs = append(subs, sub)
+		}
+		// 与 ToSQL 保持一致，除 || 以外的逻辑条件都按 AND 处理
+		if op == OpOr {
+			return b.Or(subs)
+		}
+		return b.And(subs)
+	default:
+		field := Field{Name: c.LHS, Column: c.LHS, Type: TypeAny}
+		if o.schema != nil {
+			f, err := o.schema.resolve(c)
+			if err != nil {
+				return zero, err
+			}
+			field = *f
+		} else if !IsIdentifier(c.LHS) {
+			return zero, fmt.Errorf("%w: %q", ErrFieldNotAllowed, c.LHS)
+		}
+		return b.Atom(field, normalizeOp(c.Operator), c.RHS)
+	}
+}
+
+// unsupported 后端不支持的运算符，reason 说明原因
+func unsupported(op Op, reason string) error {
+	if reason != "" {
+		return fmt.Errorf("%w: %s, %s", ErrUnsupportedOperator, op, reason)
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedOperator, op)
+}
+
+// Validate 校验条件的运算符和右值是否合法，指定 WithSchema 时同时校验字段白名单，
+// 适合在接收客户端传入的条件后尽早返回参数错误
+func (c *Condition) Validate(opts ...Option) error {
+	_, err := Translate(c, Backend[struct{}](validator{}), opts...)
+	return err
+}
+
+// validator 只做校验的后端
+type validator struct{}
+
+func (validator) Name() string { return "validate" }
+
+func (validator) Atom(f Field, op Op, value interface{}) (struct{}, error) {
+	switch op {
+	case OpEq, OpNotEq, OpGt, OpLt, OpGte, OpLte, OpIsNull, OpIsNotNull, OpContains:
+	case OpIn, OpNotIn:
+		if _, ok := toSlice(value); !ok {
+			return struct{}{}, ErrRHSNotSlice
+		}
+	case OpBetween:
+		if values, ok := toSlice(value); !ok || len(values) != 2 {
+			return struct{}{}, ErrInvalidBetween
+		}
+	case OpLike, OpNotLike, OpILike, OpRegexp:
+		if _, ok := value.(string); !ok {
+			return struct{}{}, fmt.Errorf("%w: %s pattern must be a string", ErrTypeMismatch, op)
+		}
+		if op == OpRegexp {
+			if _, err := compileRegexp(value.(string)); err != nil {
+				return struct{}{}, err
+			}
+		}
+	default:
+		return struct{}{}, unsupported(op, "")
+	}
+	return struct{}{}, nil
+}
+
+func (validator) And([]struct{}) (struct{}, error) { return struct{}{}, nil }
+func (validator) Or([]struct{}) (struct{}, error)  { return struct{}{}, nil }
+func (validator) Not(struct{}) (struct{}, error)   { return struct{}{}, nil }
@@ -0,0 +1,130 @@
+package hcond
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// translateCases 每个后端的 golden 文件都包含这些表达式的转换结果
+var translateCases = []string{
+	`age >= 18 && vip == true`,
+	`city IN ["sz", "bj"] || status == "on hold"`,
+	`id IN [1, 2] && id NOT IN [3]`,
+	`name LIKE "al%" && name NOT LIKE "%\\%off"`,
+	`name ILIKE "%ice"`,
+	`age BETWEEN 18 AND 30 && score < 99.5 && score > 0`,
+	`deleted_at IS NULL && email IS NOT NULL`,
+	`deleted_at == null || deleted_at != null`,
+	`tags CONTAINS ["vip", "new"] && !(city == "sh")`,
+}
+
+func TestESGolden(t *testing.T) {
+	var b strings.Builder
+	for _, expr := range translateCases {
+		q, err := MustParse(expr).ToES()
+		require.NoError(t, err, expr)
+		data, err := json.MarshalIndent(q, "", "  ")
+		require.NoError(t, err)
+		fmt.Fprintf(&b, "-- %s\n%s\n\n", expr, data)
+	}
+	assertGolden(t, "es.golden", b.String())
+}
+
+func TestRediSearchGolden(t *testing.T) {
+	var b strings.Builder
+	for _, expr := range translateCases {
+		q, err := MustParse(expr).ToRediSearch()
+		require.NoError(t, err, expr)
+		fmt.Fprintf(&b, "-- %s\n%s\n\n", expr, q)
+	}
+	assertGolden(t, "redisearch.golden", b.String())
+}
+
+func TestTranslateWithSchema(t *testing.T) {
+	s := MustSchemaOf(schemaUser{})
+
+	q, err := MustParse(`name == "alice" && age >= 18`).ToES(WithSchema(s))
+	require.NoError(t, err)
+	assert.Equal(t, ESQuery{"bool": ESQuery{"filter": []ESQuery{
+		{"term": ESQuery{"user_name": "alice"}},
+		{"range": ESQuery{"u.age": ESQuery{"gte": int64(18)}}},
+	}}}, q)
+
+	// Schema 中的数值字段按范围查询，即使右值是字符串
+	rs, err := MustParse(`id == "7" && name == "7"`).ToRediSearch(WithSchema(s))
+	require.NoError(t, err)
+	assert.Equal(t, `(@id:[7 7] @user_name:{7})`, rs)
+
+	_, err = MustParse(`password == "x"`).ToES(WithSchema(s))
+	assert.ErrorIs(t, err, ErrFieldNotAllowed)
+}
+
+func TestTranslateUnsupported(t *testing.T) {
+	tests := []struct {
+		expr    string
+		backend string
+	}{
+		{`name LIKE "a_c"`, "redisearch"},
+		{`email =~ "^a"`, "redisearch"},
+		{`id IN []`, "redisearch"},
+		{`age > "abc"`, "redisearch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := MustParse(tt.expr).ToRediSearch()
+			assert.ErrorIs(t, err, ErrUnsupportedOperator)
+			assert.Contains(t, err.Error(), "hcond: "+tt.backend+": ")
+		})
+	}
+
+	contains := Condition{Operator: "CONTAINS", LHS: "attrs", RHS: map[string]interface{}{"a": 1}}
+	_, err := contains.ToES()
+	assert.ErrorIs(t, err, ErrUnsupportedOperator)
+	assert.EqualError(t, err, "hcond: elasticsearch: unsupported operator: CONTAINS, only scalar values are supported")
+
+	_, err = (&Condition{Operator: "~~", LHS: "a", RHS: 1}).ToES()
+	assert.ErrorIs(t, err, ErrUnsupportedOperator)
+
+	_, err = Eq("a b", 1).ToES()
+	assert.ErrorIs(t, err, ErrFieldNotAllowed)
+}
+
+func TestESWildcard(t *testing.T) {
+	assert.Equal(t, `a*b?c`, esWildcard(`a%b_c`))
+	assert.Equal(t, `50%\*off_`, esWildcard(`50\%*off\_`))
+}
+
+// fieldsBackend 自定义后端，收集条件中用到的字段
+type fieldsBackend struct{}
+
+func (fieldsBackend) Name() string { return "fields" }
+
+func (fieldsBackend) Atom(f Field, op Op, value interface{}) ([]string, error) {
+	return []string{f.Column}, nil
+}
+
+func (fieldsBackend) And(subs [][]string) ([]string, error) {
+	var fields []string
+	for _, sub := range subs {
+		fields = append(fields, sub...)
+	}
+	return fields, nil
+}
+
+func (b fieldsBackend) Or(subs [][]string) ([]string, error) { return b.And(subs) }
+
+func (fieldsBackend) Not(sub []string) ([]string, error) { return sub, nil }
+
+func TestTranslateCustomBackend(t *testing.T) {
+	fields, err := Translate(MustParse(`a == 1 && (b > 2 || !(c IS NULL))`), Backend[[]string](fieldsBackend{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, fields)
+
+	_, err = Translate[[]string](nil, fieldsBackend{})
+	assert.Error(t, err)
+}
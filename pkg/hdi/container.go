@@ -0,0 +1,467 @@
+// Package hdi 轻量级依赖注入容器
+//
+// 通过 Provide 注册构造函数，构造函数内部通过 Resolve 获取依赖，
+// 依赖在第一次 Resolve 时懒加载创建（WithEager 的依赖在 Build 时创建），
+// 解析过程中检测循环依赖，Close 时按创建顺序的逆序释放资源。
+package hdi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// 包级错误变量定义
+var (
+	ErrNotProvided       = errors.New("hdi: provider not found")
+	ErrAlreadyProvided   = errors.New("hdi: provider already exists")
+	ErrCycle             = errors.New("hdi: dependency cycle detected")
+	ErrScopeRequired     = errors.New("hdi: scoped provider must be resolved in a scope")
+	ErrCaptiveDependency = errors.New("hdi: singleton cannot depend on scoped provider")
+	ErrClosed            = errors.New("hdi: container closed")
+)
+
+// Lifetime 依赖的生命周期
+type Lifetime int
+
+const (
+	// Singleton 容器内单例，第一次解析时创建
+	Singleton Lifetime = iota
+	// Scoped 作用域内单例，例如每个请求一个实例
+	Scoped
+	// Transient 每次解析都创建新的实例，需要释放资源的实例只能在作用域中解析或者作为单例的依赖
+	Transient
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Scoped:
+		return "scoped"
+	case Transient:
+		return "transient"
+	default:
+		return fmt.Sprintf("lifetime(%d)", int(l))
+	}
+}
+
+// Resolver 依赖解析器，Container、Scope 以及构造函数收到的参数都实现了该接口
+type Resolver interface {
+	resolve(k key) (any, error)
+}
+
+// key 依赖的唯一标识：类型 + 名称
+type key struct {
+	typ  reflect.Type
+	name string
+}
+
+func (k key) String() string {
+	if k.name == "" {
+		return k.typ.String()
+	}
+	return k.typ.String() + "#" + k.name
+}
+
+type provider struct {
+	key      key
+	lifetime Lifetime
+	eager    bool
+	build    func(r Resolver) (any, error)
+	close    func(v any) error
+}
+
+type options struct {
+	name     string
+	lifetime Lifetime
+	eager    bool
+	close    func(v any) error
+}
+
+// Option 注册选项
+type Option func(*options)
+
+// WithName 为同一类型注册多个实现时，用名称区分
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithLifetime 设置生命周期，默认为 Singleton
+func WithLifetime(lifetime Lifetime) Option {
+	return func(o *options) {
+		o.lifetime = lifetime
+	}
+}
+
+// WithEager 在 Build 时立即创建，用于尽早暴露配置错误，仅对 Singleton 生效
+func WithEager() Option {
+	return func(o *options) {
+		o.eager = true
+	}
+}
+
+// WithClose 自定义资源释放函数，未设置时自动识别 io.Closer 和 Close(ctx) error
+func WithClose[T any](fn func(T) error) Option {
+	return func(o *options) {
+		o.close = func(v any) error {
+			return fn(v.(T))
+		}
+	}
+}
+
+// Container 依赖注入容器
+type Container struct {
+	mu        sync.Mutex
+	providers map[key]*provider
+	order     []key
+	instances *instanceSet
+	closed    bool
+}
+
+// New 创建容器
+func New() *Container {
+	return &Container{
+		providers: make(map[key]*provider),
+		instances: newInstanceSet(),
+	}
+}
+
+// Provide 注册构造函数
+func Provide[T any](c *Container, fn func(r Resolver) (T, error), opts ...Option) error {
+	o := options{lifetime: Singleton}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p := &provider{
+		key:      key{typ: typeOf[T](), name: o.name},
+		lifetime: o.lifetime,
+		eager:    o.eager && o.lifetime == Singleton,
+		build: func(r Resolver) (any, error) {
+			return fn(r)
+		},
+		close: o.close,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.providers[p.key]; exists {
+		return fmt.Errorf("%w: %s", ErrAlreadyProvided, p.key)
+	}
+	c.providers[p.key] = p
+	c.order = append(c.order, p.key)
+	return nil
+}
+
+// MustProvide 注册构造函数，失败时 panic
+func MustProvide[T any](c *Container, fn func(r Resolver) (T, error), opts ...Option) {
+	if err := Provide(c, fn, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// Supply 注册一个已经创建好的实例
+func Supply[T any](c *Container, v T, opts ...Option) error {
+	return Provide(c, func(Resolver) (T, error) { return v, nil }, opts...)
+}
+
+// Resolve 解析依赖
+func Resolve[T any](r Resolver, opts ...Option) (T, error) {
+	var zero T
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	v, err := r.resolve(key{typ: typeOf[T](), name: o.name})
+	if err != nil {
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// MustResolve 解析依赖，失败时 panic
+func MustResolve[T any](r Resolver, opts ...Option) T {
+	v, err := Resolve[T](r, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Has 判断是否注册了指定类型
+func Has[T any](c *Container, opts ...Option) bool {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.providers[key{typ: typeOf[T](), name: o.name}]
+	return ok
+}
+
+// Build 创建所有 WithEager 注册的依赖，同时检查它们的依赖链
+func (c *Container) Build() error {
+	c.mu.Lock()
+	var eager []key
+	for _, k := range c.order {
+		if c.providers[k].eager {
+			eager = append(eager, k)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, k := range eager {
+		if _, err := c.resolve(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scope 创建一个新的作用域，Scoped 依赖在作用域内单例
+// 作用域使用完后需要调用 Close 释放作用域内创建的资源
+func (c *Container) Scope() *Scope {
+	return &Scope{root: c, instances: newInstanceSet()}
+}
+
+// Close 按创建顺序的逆序释放单例资源，只会执行一次
+func (c *Container) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+	return c.instances.close(ctx)
+}
+
+func (c *Container) resolve(k key) (any, error) {
+	return (&resolution{root: c}).resolve(k)
+}
+
+func (c *Container) provider(k key) (*provider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	p, ok := c.providers[k]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotProvided, k)
+	}
+	return p, nil
+}
+
+// Scope 依赖作用域
+type Scope struct {
+	root      *Container
+	instances *instanceSet
+}
+
+// Close 按创建顺序的逆序释放作用域内的资源
+func (s *Scope) Close(ctx context.Context) error {
+	return s.instances.close(ctx)
+}
+
+func (s *Scope) resolve(k key) (any, error) {
+	return (&resolution{root: s.root, scope: s}).resolve(k)
+}
+
+// resolution 一次解析过程，记录解析路径用于循环依赖检测
+type resolution struct {
+	root  *Container
+	scope *Scope
+	stack []key
+	// singleton 为 true 表示当前处于单例的构造过程中，不允许依赖 Scoped
+	singleton bool
+}
+
+func (r *resolution) resolve(k key) (any, error) {
+	for _, s := range r.stack {
+		if s == k {
+			return nil, fmt.Errorf("%w: %s", ErrCycle, r.path(k))
+		}
+	}
+
+	p, err := r.root.provider(k)
+	if err != nil {
+		if len(r.stack) > 0 {
+			return nil, fmt.Errorf("%w (required by %s)", err, r.stack[len(r.stack)-1])
+		}
+		return nil, err
+	}
+
+	switch p.lifetime {
+	case Singleton:
+		return r.root.instances.getOrCreate(k, func() (any, error) {
+			return r.build(p, true)
+		}, p.close)
+	case Scoped:
+		if r.singleton {
+			return nil, fmt.Errorf("%w: %s", ErrCaptiveDependency, r.path(k))
+		}
+		if r.scope == nil {
+			return nil, fmt.Errorf("%w: %s", ErrScopeRequired, k)
+		}
+		return r.scope.instances.getOrCreate(k, func() (any, error) {
+			return r.build(p, false)
+		}, p.close)
+	default:
+		v, err := r.build(p, r.singleton)
+		if err != nil {
+			return nil, err
+		}
+		// Transient 实例的生命周期归属于当前作用域，或者依赖它的单例所在的容器；
+		// 直接从容器解析时没有归属，需要释放资源的实例如果由容器持有，每次解析都会增加一个直到容器关闭
+		switch {
+		case r.singleton:
+			r.root.instances.track(v, p.close)
+		case r.scope != nil:
+			r.scope.instances.track(v, p.close)
+		default:
+			if closeFn := closerOf(v, p.close); closeFn != nil {
+				_ = closeFn(context.Background())
+				return nil, fmt.Errorf("%w: transient %s has cleanup", ErrScopeRequired, k)
+			}
+		}
+		return v, nil
+	}
+}
+
+func (r *resolution) build(p *provider, singleton bool) (any, error) {
+	next := &resolution{
+		root:      r.root,
+		scope:     r.scope,
+		stack:     append(append([]key{}, r.stack...), p.key),
+		singleton: r.singleton || singleton,
+	}
+	if next.singleton {
+		// 单例不能持有作用域，避免请求结束后仍被引用
+		next.scope = nil
+	}
+	v, err := p.build(next)
+	if err != nil {
+		return nil, fmt.Errorf("hdi: build %s: %w", p.key, err)
+	}
+	return v, nil
+}
+
+func (r *resolution) path(k key) string {
+	parts := make([]string, 0, len(r.stack)+1)
+	for _, s := range r.stack {
+		parts = append(parts, s.String())
+	}
+	parts = append(parts, k.String())
+	return strings.Join(parts, " -> ")
+}
+
+// instanceSet 已创建的实例集合，记录创建顺序
+type instanceSet struct {
+	mu       sync.Mutex
+	values   map[key]any
+	creating map[key]*sync.Mutex
+	closers  []func(ctx context.Context) error
+	closed   bool
+}
+
+func newInstanceSet() *instanceSet {
+	return &instanceSet{
+		values:   make(map[key]any),
+		creating: make(map[key]*sync.Mutex),
+	}
+}
+
+func (s *instanceSet) getOrCreate(k key, create func() (any, error), closeFn func(any) error) (any, error) {
+	s.mu.Lock()
+	if v, ok := s.values[k]; ok {
+		s.mu.Unlock()
+		return v, nil
+	}
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrClosed
+	}
+	lock, ok := s.creating[k]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.creating[k] = lock
+	}
+	s.mu.Unlock()
+
+	// 同一个依赖只创建一次，不同依赖可以并发创建
+	lock.Lock()
+	defer lock.Unlock()
+
+	s.mu.Lock()
+	if v, ok := s.values[k]; ok {
+		s.mu.Unlock()
+		return v, nil
+	}
+	s.mu.Unlock()
+
+	v, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.values[k] = v
+	s.mu.Unlock()
+	s.track(v, closeFn)
+	return v, nil
+}
+
+func (s *instanceSet) track(v any, closeFn func(any) error) {
+	fn := closerOf(v, closeFn)
+	if fn == nil {
+		return
+	}
+	s.mu.Lock()
+	s.closers = append(s.closers, fn)
+	s.mu.Unlock()
+}
+
+func (s *instanceSet) close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	closers := s.closers
+	s.closers = nil
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closerOf 识别实例的释放方法
+func closerOf(v any, closeFn func(any) error) func(ctx context.Context) error {
+	if closeFn != nil {
+		return func(context.Context) error { return closeFn(v) }
+	}
+	switch c := v.(type) {
+	case interface{ Close(context.Context) error }:
+		return c.Close
+	case io.Closer:
+		return func(context.Context) error { return c.Close() }
+	case interface{ Close() }:
+		return func(context.Context) error { c.Close(); return nil }
+	}
+	return nil
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
@@ -0,0 +1,252 @@
+package hdi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct{ DSN string }
+
+type testDB struct {
+	cfg    *testConfig
+	closed *[]string
+}
+
+func (d *testDB) Close() error {
+	*d.closed = append(*d.closed, "db")
+	return nil
+}
+
+type testRepo struct {
+	db     *testDB
+	closed *[]string
+}
+
+func (r *testRepo) Close(ctx context.Context) error {
+	*r.closed = append(*r.closed, "repo")
+	return nil
+}
+
+type UserService interface {
+	Name() string
+}
+
+type userService struct{ repo *testRepo }
+
+func (s *userService) Name() string { return "user" }
+
+func newTestContainer(closed *[]string) *Container {
+	c := New()
+	MustProvide(c, func(r Resolver) (*testConfig, error) {
+		return &testConfig{DSN: "mysql://"}, nil
+	})
+	MustProvide(c, func(r Resolver) (*testDB, error) {
+		cfg, err := Resolve[*testConfig](r)
+		if err != nil {
+			return nil, err
+		}
+		return &testDB{cfg: cfg, closed: closed}, nil
+	})
+	MustProvide(c, func(r Resolver) (*testRepo, error) {
+		return &testRepo{db: MustResolve[*testDB](r), closed: closed}, nil
+	})
+	MustProvide(c, func(r Resolver) (UserService, error) {
+		return &userService{repo: MustResolve[*testRepo](r)}, nil
+	})
+	return c
+}
+
+func TestContainerResolve(t *testing.T) {
+	var closed []string
+	c := newTestContainer(&closed)
+
+	svc, err := Resolve[UserService](c)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", svc.Name())
+
+	// 单例多次解析得到同一个实例
+	svc2 := MustResolve[UserService](c)
+	assert.Same(t, svc, svc2)
+	assert.Equal(t, "mysql://", svc.(*userService).repo.db.cfg.DSN)
+
+	// 按创建顺序的逆序释放
+	assert.NoError(t, c.Close(context.Background()))
+	assert.Equal(t, []string{"repo", "db"}, closed)
+
+	_, err = Resolve[UserService](c)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestContainerNotProvided(t *testing.T) {
+	c := New()
+	MustProvide(c, func(r Resolver) (*testDB, error) {
+		_, err := Resolve[*testConfig](r)
+		return nil, err
+	})
+
+	_, err := Resolve[*testDB](c)
+	assert.ErrorIs(t, err, ErrNotProvided)
+	assert.Contains(t, err.Error(), "required by *hdi.testDB")
+
+	assert.ErrorIs(t, Supply(c, &testDB{}), ErrAlreadyProvided)
+}
+
+func TestContainerCycle(t *testing.T) {
+	type a struct{}
+	type b struct{}
+	c := New()
+	MustProvide(c, func(r Resolver) (*a, error) {
+		_, err := Resolve[*b](r)
+		return &a{}, err
+	})
+	MustProvide(c, func(r Resolver) (*b, error) {
+		_, err := Resolve[*a](r)
+		return &b{}, err
+	})
+
+	_, err := Resolve[*a](c)
+	assert.ErrorIs(t, err, ErrCycle)
+	assert.Contains(t, err.Error(), "*hdi.a -> *hdi.b -> *hdi.a")
+}
+
+func TestContainerNamed(t *testing.T) {
+	c := New()
+	assert.NoError(t, Supply(c, &testConfig{DSN: "master"}, WithName("master")))
+	assert.NoError(t, Supply(c, &testConfig{DSN: "slave"}, WithName("slave")))
+
+	assert.Equal(t, "master", MustResolve[*testConfig](c, WithName("master")).DSN)
+	assert.Equal(t, "slave", MustResolve[*testConfig](c, WithName("slave")).DSN)
+	assert.True(t, Has[*testConfig](c, WithName("slave")))
+	assert.False(t, Has[*testConfig](c))
+}
+
+func TestContainerEager(t *testing.T) {
+	var built atomic.Int32
+	c := New()
+	MustProvide(c, func(r Resolver) (*testConfig, error) {
+		built.Add(1)
+		return &testConfig{}, nil
+	}, WithEager())
+	MustProvide(c, func(r Resolver) (*testDB, error) {
+		return nil, errors.New("should be lazy")
+	})
+
+	assert.NoError(t, c.Build())
+	assert.Equal(t, int32(1), built.Load())
+
+	failing := New()
+	MustProvide(failing, func(r Resolver) (*testDB, error) {
+		return nil, errors.New("connect failed")
+	}, WithEager())
+	assert.ErrorContains(t, failing.Build(), "connect failed")
+}
+
+func TestContainerScope(t *testing.T) {
+	type requestCtx struct{ id int }
+	var closed []string
+	var seq atomic.Int32
+
+	c := New()
+	MustProvide(c, func(r Resolver) (*requestCtx, error) {
+		return &requestCtx{id: int(seq.Add(1))}, nil
+	}, WithLifetime(Scoped))
+	MustProvide(c, func(r Resolver) (*testRepo, error) {
+		MustResolve[*requestCtx](r)
+		return &testRepo{closed: &closed}, nil
+	}, WithLifetime(Scoped))
+
+	// 作用域外不能解析 Scoped 依赖
+	_, err := Resolve[*requestCtx](c)
+	assert.ErrorIs(t, err, ErrScopeRequired)
+
+	s1 := c.Scope()
+	s2 := c.Scope()
+	r1 := MustResolve[*requestCtx](s1)
+	assert.Same(t, r1, MustResolve[*requestCtx](s1))
+	assert.NotSame(t, r1, MustResolve[*requestCtx](s2))
+
+	MustResolve[*testRepo](s1)
+	assert.NoError(t, s1.Close(context.Background()))
+	assert.Equal(t, []string{"repo"}, closed)
+
+	// 单例不能依赖 Scoped 依赖
+	MustProvide(c, func(r Resolver) (*testDB, error) {
+		_, err := Resolve[*requestCtx](r)
+		return &testDB{}, err
+	})
+	_, err = Resolve[*testDB](s2)
+	assert.ErrorIs(t, err, ErrCaptiveDependency)
+}
+
+func TestContainerTransient(t *testing.T) {
+	c := New()
+	MustProvide(c, func(r Resolver) (*testConfig, error) {
+		return &testConfig{}, nil
+	}, WithLifetime(Transient))
+
+	assert.NotSame(t, MustResolve[*testConfig](c), MustResolve[*testConfig](c))
+
+	// 需要释放资源的 Transient 直接从容器解析时容器不持有，返回错误并立即释放
+	var closed []string
+	MustProvide(c, func(r Resolver) (*testDB, error) {
+		return &testDB{closed: &closed}, nil
+	}, WithLifetime(Transient))
+	_, err := Resolve[*testDB](c)
+	assert.ErrorIs(t, err, ErrScopeRequired)
+	assert.Equal(t, []string{"db"}, closed)
+
+	// 在作用域中解析时随作用域释放
+	closed = nil
+	s := c.Scope()
+	assert.NotSame(t, MustResolve[*testDB](s), MustResolve[*testDB](s))
+	assert.NoError(t, s.Close(context.Background()))
+	assert.Equal(t, []string{"db", "db"}, closed)
+
+	// 作为单例的依赖时随容器释放
+	closed = nil
+	MustProvide(c, func(r Resolver) (*testRepo, error) {
+		return &testRepo{db: MustResolve[*testDB](r), closed: &closed}, nil
+	})
+	MustResolve[*testRepo](c)
+	assert.Empty(t, closed)
+	assert.NoError(t, c.Close(context.Background()))
+	assert.Equal(t, []string{"repo", "db"}, closed)
+}
+
+func TestContainerConcurrentResolve(t *testing.T) {
+	var built atomic.Int32
+	c := New()
+	MustProvide(c, func(r Resolver) (*testConfig, error) {
+		built.Add(1)
+		return &testConfig{}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			MustResolve[*testConfig](c)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), built.Load())
+}
+
+func TestContext(t *testing.T) {
+	c := New()
+	assert.NoError(t, Supply(c, &testConfig{DSN: "ctx"}))
+
+	_, err := ResolveFromContext[*testConfig](context.Background())
+	assert.ErrorIs(t, err, ErrScopeRequired)
+
+	ctx := NewContext(context.Background(), c.Scope())
+	cfg, err := ResolveFromContext[*testConfig](ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "ctx", cfg.DSN)
+}
@@ -0,0 +1,26 @@
+package hdi
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext 将解析器保存到上下文中，通常保存的是请求作用域
+func NewContext(ctx context.Context, r Resolver) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext 从上下文中获取解析器
+func FromContext(ctx context.Context) (Resolver, bool) {
+	r, ok := ctx.Value(contextKey{}).(Resolver)
+	return r, ok
+}
+
+// ResolveFromContext 使用上下文中的解析器解析依赖
+func ResolveFromContext[T any](ctx context.Context, opts ...Option) (T, error) {
+	r, ok := FromContext(ctx)
+	if !ok {
+		var zero T
+		return zero, ErrScopeRequired
+	}
+	return Resolve[T](r, opts...)
+}
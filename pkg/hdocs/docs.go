@@ -0,0 +1,45 @@
+// Package hdocs 提供 OpenAPI 文档和离线的文档页面
+//
+// 文档由 hollow-cli openapi 生成，页面的 HTML、CSS 和 JavaScript 都内嵌在二进制中，不依赖 CDN，
+// 内网和离线环境也能使用。页面中可以直接调用接口，错误码目录来自文档中的 x-hecode 扩展。
+package hdocs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed ui/index.html
+var indexHTML []byte
+
+// Register 在 path 下注册文档路由：path/ 为文档页面，path/openapi.json 为 OpenAPI 文档，
+// 访问 path 时重定向到 path/。spec 不是合法的 JSON 时返回错误
+func Register(r gin.IRoutes, path string, spec []byte) error {
+	if !json.Valid(spec) {
+		return fmt.Errorf("hdocs: OpenAPI 文档不是合法的 JSON")
+	}
+	path = "/" + strings.Trim(path, "/")
+	if path == "/" {
+		path = ""
+	}
+
+	r.GET(path+"/", func(c *gin.Context) {
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", indexHTML)
+	})
+	r.GET(path+"/openapi.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+	})
+	if path != "" {
+		r.GET(path, func(c *gin.Context) {
+			c.Redirect(http.StatusMovedPermanently, path+"/")
+		})
+	}
+	return nil
+}
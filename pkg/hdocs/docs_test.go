@@ -0,0 +1,40 @@
+package hdocs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	require.NoError(t, Register(r, "/docs/", []byte(`{"openapi":"3.1.0"}`)))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/docs")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/docs/", w.Header().Get("Location"))
+
+	w = get("/docs/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	// 页面不引用外部资源，离线可用
+	assert.Contains(t, w.Body.String(), `fetch("openapi.json")`)
+	assert.NotContains(t, w.Body.String(), "https://")
+
+	w = get("/docs/openapi.json")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"openapi":"3.1.0"}`, w.Body.String())
+
+	assert.Error(t, Register(gin.New(), "/docs", []byte("openapi: 3.1.0")))
+}
@@ -0,0 +1,192 @@
+package hecode
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Detail 错误详情，会序列化到响应的 data.details 中
+// Type 用于客户端区分详情类型，其余字段按 JSON 序列化
+type Detail interface {
+	DetailType() string
+}
+
+// FieldViolation 参数校验失败的字段
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// DetailType 实现 Detail 接口
+func (FieldViolation) DetailType() string { return "field_violation" }
+
+// RetryAfter 建议客户端重试的等待时间
+type RetryAfter struct {
+	Delay time.Duration `json:"-"`
+}
+
+// DetailType 实现 Detail 接口
+func (RetryAfter) DetailType() string { return "retry_after" }
+
+// Seconds 返回向上取整的等待秒数，用于 Retry-After 响应头
+func (r RetryAfter) Seconds() int64 {
+	return int64((r.Delay + time.Second - 1) / time.Second)
+}
+
+// MarshalJSON 以秒为单位输出等待时间
+func (r RetryAfter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Seconds int64 `json:"seconds"`
+	}{Seconds: r.Seconds()})
+}
+
+// ResourceInfo 错误相关的资源
+type ResourceInfo struct {
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	Description  string `json:"description,omitempty"`
+}
+
+// DetailType 实现 Detail 接口
+func (ResourceInfo) DetailType() string { return "resource_info" }
+
+// Metadata 任意的键值对详情
+type Metadata map[string]string
+
+// DetailType 实现 Detail 接口
+func (Metadata) DetailType() string { return "metadata" }
+
+// WithDetails 返回附带详情的错误，保持错误码和消息不变
+func WithDetails(err error, details ...Detail) error {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := err.(*EcodeError)
+	if !ok {
+		return &EcodeError{
+			code:    ErrCodeUnknown,
+			msg:     err.Error(),
+			cause:   err,
+			details: details,
+			stack:   callers(1),
+		}
+	}
+
+	return &EcodeError{
+		code:    e.code,
+		msg:     e.msg,
+		cause:   e,
+		details: details,
+		stack:   callers(1),
+	}
+}
+
+// Details 返回当前错误附带的详情，不包括错误链上其他错误的详情
+func (e *EcodeError) Details() []Detail {
+	return e.details
+}
+
+// Details 返回错误链上所有的详情，外层错误的详情在前
+func Details(err error) []Detail {
+	var details []Detail
+	for _, ec := range chain(err) {
+		details = append(details, ec.details...)
+	}
+	return details
+}
+
+// DetailOf 返回错误链上第一个指定类型的详情
+func DetailOf[T Detail](err error) (T, bool) {
+	for _, d := range Details(err) {
+		if v, ok := d.(T); ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// DetailsData 将错误链上的详情转换为响应数据，每个详情附带 type 字段
+// 例如 [{"type":"field_violation","field":"name","description":"required"}]
+func DetailsData(err error) []map[string]interface{} {
+	details := Details(err)
+	if len(details) == 0 {
+		return nil
+	}
+
+	result := make([]map[string]interface{}, 0, len(details))
+	for _, d := range details {
+		item := map[string]interface{}{}
+		if data, err := json.Marshal(d); err == nil {
+			if json.Unmarshal(data, &item) != nil {
+				// 非对象类型的详情放到 value 字段中
+				item = map[string]interface{}{"value": json.RawMessage(data)}
+			}
+		}
+		item["type"] = d.DetailType()
+		result = append(result, item)
+	}
+	return result
+}
+
+// DetailsFromData 是 DetailsData 的逆操作，将响应中的 data.details 还原为详情，
+// 未知类型的详情转换为 Metadata，值为 JSON 字符串以外的类型时使用其 JSON 编码
+func DetailsFromData(data []map[string]interface{}) []Detail {
+	details := make([]Detail, 0, len(data))
+	for _, item := range data {
+		typ, _ := item["type"].(string)
+		raw, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		switch typ {
+		case FieldViolation{}.DetailType():
+			var d FieldViolation
+			if json.Unmarshal(raw, &d) == nil {
+				details = append(details, d)
+				continue
+			}
+		case RetryAfter{}.DetailType():
+			var d struct {
+				Seconds int64 `json:"seconds"`
+			}
+			if json.Unmarshal(raw, &d) == nil {
+				details = append(details, RetryAfter{Delay: time.Duration(d.Seconds) * time.Second})
+				continue
+			}
+		case ResourceInfo{}.DetailType():
+			var d ResourceInfo
+			if json.Unmarshal(raw, &d) == nil {
+				details = append(details, d)
+				continue
+			}
+		}
+		md := Metadata{}
+		for k, v := range item {
+			if typ == Metadata(nil).DetailType() && k == "type" {
+				continue
+			}
+			if str, ok := v.(string); ok {
+				md[k] = str
+			} else if b, err := json.Marshal(v); err == nil {
+				md[k] = string(b)
+			}
+		}
+		details = append(details, md)
+	}
+	return details
+}
+
+// chain 返回错误链上所有的 EcodeError，外层在前
+func chain(err error) []*EcodeError {
+	var result []*EcodeError
+	for err != nil {
+		if ec, ok := err.(*EcodeError); ok {
+			result = append(result, ec)
+		}
+		err = errors.Unwrap(err)
+	}
+	return result
+}
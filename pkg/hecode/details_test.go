@@ -0,0 +1,145 @@
+package hecode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestStackTrace(t *testing.T) {
+	Convey("StackTrace", t, func() {
+		defer EnableStack(defaultStackEnabled)
+		EnableStack(false)
+		So(StackTrace(Wrap(ErrNotFound, "user")), ShouldBeNil)
+
+		EnableStack(true)
+
+		err := Wrap(WithMessage(ErrNotFound, "user 1"), "get user")
+		frames := StackTrace(err)
+		So(frames, ShouldNotBeEmpty)
+		// 第一帧是调用 WithMessage 的位置而不是 hecode 内部
+		So(frames[0].Function, ShouldEndWith, "TestStackTrace.func1")
+		So(frames[0].File, ShouldEndWith, "details_test.go")
+
+		ec := err.(*EcodeError)
+		So(ec.StackTrace(), ShouldNotBeEmpty)
+		So(WithMessagef(ErrNotFound, "user %d", 1).(*EcodeError).StackTrace()[0].Function, ShouldEndWith, "TestStackTrace.func1")
+	})
+}
+
+func TestEnableStackUntil(t *testing.T) {
+	Convey("EnableStackUntil", t, func() {
+		defer EnableStack(defaultStackEnabled)
+		EnableStack(false)
+
+		ctx1, cancel1 := context.WithCancel(context.Background())
+		ctx2, cancel2 := context.WithCancel(context.Background())
+		EnableStackUntil(ctx1)
+		EnableStackUntil(ctx2)
+		So(StackTrace(Wrap(ErrNotFound, "user")), ShouldNotBeEmpty)
+
+		// 所有 ctx 都结束后恢复为关闭
+		cancel1()
+		So(StackEnabled(), ShouldBeTrue)
+		cancel2()
+		deadline := time.Now().Add(time.Second)
+		for StackEnabled() && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		So(StackEnabled(), ShouldBeFalse)
+		So(StackTrace(Wrap(ErrNotFound, "user")), ShouldBeNil)
+	})
+}
+
+func TestDetails(t *testing.T) {
+	Convey("Details", t, func() {
+		So(WithDetails(nil, Metadata{"k": "v"}), ShouldBeNil)
+
+		err := WithDetails(ErrInvalidParam, FieldViolation{Field: "name", Description: "required"})
+		err = Wrap(WithDetails(err, RetryAfter{Delay: 1500 * time.Millisecond}), "create user")
+		So(Code(err), ShouldEqual, Code(ErrInvalidParam))
+		So(len(Details(err)), ShouldEqual, 2)
+
+		retry, ok := DetailOf[RetryAfter](err)
+		So(ok, ShouldBeTrue)
+		So(retry.Seconds(), ShouldEqual, 2)
+		_, ok = DetailOf[ResourceInfo](err)
+		So(ok, ShouldBeFalse)
+
+		data, jsonErr := json.Marshal(DetailsData(err))
+		So(jsonErr, ShouldBeNil)
+		So(string(data), ShouldEqual, `[{"seconds":2,"type":"retry_after"},{"description":"required","field":"name","type":"field_violation"}]`)
+
+		// 非 EcodeError 使用未知错误码
+		plain := WithDetails(errors.New("plain"), ResourceInfo{ResourceType: "user", ResourceID: "1"})
+		So(Code(plain), ShouldEqual, ErrCodeUnknown)
+		So(DetailsData(plain)[0]["resource_id"], ShouldEqual, "1")
+	})
+}
+
+func TestDetailsFromData(t *testing.T) {
+	Convey("DetailsFromData", t, func() {
+		err := WithDetails(ErrInvalidParam,
+			FieldViolation{Field: "name", Description: "required"},
+			RetryAfter{Delay: 2 * time.Second},
+			ResourceInfo{ResourceType: "user", ResourceID: "1"},
+			Metadata{"k": "v"},
+		)
+		// 经过 JSON 序列化后还原
+		data, jsonErr := json.Marshal(DetailsData(err))
+		So(jsonErr, ShouldBeNil)
+		var items []map[string]interface{}
+		So(json.Unmarshal(data, &items), ShouldBeNil)
+		So(DetailsFromData(items), ShouldResemble, Details(err))
+
+		// 未知类型的详情转换为 Metadata
+		unknown := DetailsFromData([]map[string]interface{}{{"type": "quota", "limit": 10.0}})
+		So(unknown, ShouldResemble, []Detail{Metadata{"type": "quota", "limit": "10"}})
+	})
+}
+
+func TestFromCode(t *testing.T) {
+	Convey("FromCode", t, func() {
+		cause := errors.New("dial tcp: connection refused")
+		err := FromCode(Code(ErrNetwork), "network error", cause)
+		So(IsError(err, ErrNetwork), ShouldBeTrue)
+		So(IsRetryable(err), ShouldBeTrue)
+		So(errors.Is(err, cause), ShouldBeTrue)
+
+		// 未注册的错误码不会登记到注册表
+		err = FromCode(98765, "remote error", nil)
+		So(Code(err), ShouldEqual, 98765)
+		_, ok := Lookup(98765)
+		So(ok, ShouldBeFalse)
+	})
+}
+
+func TestMarshalLogObject(t *testing.T) {
+	Convey("MarshalLogObject", t, func() {
+		core, logs := observer.New(zapcore.DebugLevel)
+		logger := zap.New(core)
+
+		err := Wrap(WithDetails(NewErrorWithCause(1000101, "query failed", errors.New("timeout")), Metadata{"table": "user"}), "get user")
+		logger.Error("failed", ZapError(err))
+		logger.Error("plain", ZapError(errors.New("plain")))
+
+		entries := logs.All()
+		So(len(entries), ShouldEqual, 2)
+		fields := entries[0].ContextMap()["error"].(map[string]interface{})
+		So(fields["code"], ShouldEqual, 1000101)
+		So(fields["msg"], ShouldEqual, "get user")
+		cause := fields["cause"].(map[string]interface{})
+		So(cause["details"], ShouldNotBeNil)
+		root := cause["cause"].(map[string]interface{})
+		So(root["msg"], ShouldEqual, "query failed")
+		So(root["cause"], ShouldEqual, "timeout")
+		So(entries[1].ContextMap()["error"], ShouldEqual, "plain")
+	})
+}
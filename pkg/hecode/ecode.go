@@ -2,85 +2,85 @@ package hecode
 
 import (
 	"fmt"
-	"sync"
-)
+	"net/http"
 
-// 全局变量，用于跟踪已使用的错误码
-var (
-	usedCodes            = make(map[int]bool) // 存储已使用的错误码
-	codesMutex           = &sync.RWMutex{}    // 用于保护 usedCodes 的互斥锁
-	enableCheckDuplicate = true               // 是否启用错误码重复检查
+	"google.golang.org/grpc/codes"
 )
 
+// 是否启用错误码重复检查，错误码记录在全局注册表 DefaultRegistry 中
+var enableCheckDuplicate = true
+
 const (
 	// 未知错误
 	ErrCodeUnknown = 1099
 )
 
-// 预定义的错误实例
+// 预定义的错误实例，按模块注册在内置的命名空间中
 var (
 	// 系统错误
-	ErrInternal = New(1001, "internal server error")
-	ErrCache    = New(1002, "cache error")
-	ErrNetwork  = New(1003, "network error")
-	ErrTimeout  = New(1004, "request timeout")
-	ErrConfig   = New(1005, "invalid configuration")
-	ErrResource = New(1006, "resource exhausted")
-	ErrService  = New(1007, "service unavailable")
-	ErrUnknown  = New(ErrCodeUnknown, "unknown error")
+	ErrInternal = NamespaceSystem.New(1001, "internal server error", WithLang("zh-CN", "服务内部错误"), WithSeverity(SeverityCritical))
+	ErrCache    = NamespaceSystem.New(1002, "cache error", WithLang("zh-CN", "缓存错误"), WithRetryable())
+	ErrNetwork  = NamespaceSystem.New(1003, "network error", WithLang("zh-CN", "网络错误"), WithHTTPStatus(http.StatusServiceUnavailable), WithRetryable())
+	ErrTimeout  = NamespaceSystem.New(1004, "request timeout", WithLang("zh-CN", "请求超时"), WithHTTPStatus(http.StatusGatewayTimeout), WithRetryable())
+	ErrConfig   = NamespaceSystem.New(1005, "invalid configuration", WithLang("zh-CN", "配置错误"), WithSeverity(SeverityCritical))
+	ErrResource = NamespaceSystem.New(1006, "resource exhausted", WithLang("zh-CN", "资源耗尽"), WithHTTPStatus(http.StatusTooManyRequests), WithRetryable())
+	ErrService  = NamespaceSystem.New(1007, "service unavailable", WithLang("zh-CN", "服务不可用"), WithHTTPStatus(http.StatusServiceUnavailable), WithRetryable())
+	ErrUnknown  = NamespaceSystem.New(ErrCodeUnknown, "unknown error", WithLang("zh-CN", "未知错误"), WithGRPCCode(codes.Unknown))
 
 	// 参数错误
-	ErrInvalidParam = New(1100, "invalid parameter")
-	ErrMissingParam = New(1101, "missing parameter")
-	ErrParamFormat  = New(1102, "parameter format error")
-	ErrParamRange   = New(1103, "parameter out of range")
-	ErrParamValue   = New(1104, "invalid parameter value")
+	ErrInvalidParam = NamespaceParam.New(1100, "invalid parameter", WithLang("zh-CN", "参数错误"), WithHTTPStatus(http.StatusBadRequest), WithSeverity(SeverityWarning))
+	ErrMissingParam = NamespaceParam.New(1101, "missing parameter", WithLang("zh-CN", "缺少参数"), WithHTTPStatus(http.StatusBadRequest), WithSeverity(SeverityWarning))
+	ErrParamFormat  = NamespaceParam.New(1102, "parameter format error", WithLang("zh-CN", "参数格式错误"), WithHTTPStatus(http.StatusBadRequest), WithSeverity(SeverityWarning))
+	ErrParamRange   = NamespaceParam.New(1103, "parameter out of range", WithLang("zh-CN", "参数超出范围"), WithHTTPStatus(http.StatusBadRequest), WithSeverity(SeverityWarning))
+	ErrParamValue   = NamespaceParam.New(1104, "invalid parameter value", WithLang("zh-CN", "参数值无效"), WithHTTPStatus(http.StatusBadRequest), WithSeverity(SeverityWarning))
 
 	// 业务错误
-	ErrNotFound         = New(1200, "resource not found")
-	ErrAlreadyExists    = New(1201, "resource already exists")
-	ErrPermissionDenied = New(1202, "permission denied")
-	ErrForbidden        = New(1203, "forbidden access")
-	ErrUnauthorized     = New(1204, "unauthorized")
-	ErrAccessDenied     = New(1205, "access denied")
-	ErrOperation        = New(1206, "operation failed")
-	ErrBusinessRule     = New(1207, "business rule violation")
+	ErrNotFound         = NamespaceBiz.New(1200, "resource not found", WithLang("zh-CN", "资源不存在"), WithHTTPStatus(http.StatusNotFound), WithSeverity(SeverityWarning))
+	ErrAlreadyExists    = NamespaceBiz.New(1201, "resource already exists", WithLang("zh-CN", "资源已存在"), WithHTTPStatus(http.StatusConflict), WithSeverity(SeverityWarning))
+	ErrPermissionDenied = NamespaceBiz.New(1202, "permission denied", WithLang("zh-CN", "没有权限"), WithHTTPStatus(http.StatusForbidden), WithSeverity(SeverityWarning))
+	ErrForbidden        = NamespaceBiz.New(1203, "forbidden access", WithLang("zh-CN", "禁止访问"), WithHTTPStatus(http.StatusForbidden), WithSeverity(SeverityWarning))
+	ErrUnauthorized     = NamespaceBiz.New(1204, "unauthorized", WithLang("zh-CN", "未认证"), WithHTTPStatus(http.StatusUnauthorized), WithSeverity(SeverityWarning))
+	ErrAccessDenied     = NamespaceBiz.New(1205, "access denied", WithLang("zh-CN", "拒绝访问"), WithHTTPStatus(http.StatusForbidden), WithSeverity(SeverityWarning))
+	ErrOperation        = NamespaceBiz.New(1206, "operation failed", WithLang("zh-CN", "操作失败"), WithGRPCCode(codes.FailedPrecondition))
+	ErrBusinessRule     = NamespaceBiz.New(1207, "business rule violation", WithLang("zh-CN", "违反业务规则"), WithHTTPStatus(http.StatusUnprocessableEntity), WithGRPCCode(codes.FailedPrecondition), WithSeverity(SeverityWarning))
 
 	// 数据错误
-	ErrDataValidation  = New(1300, "data validation error")
-	ErrDataFormat      = New(1301, "data format error")
-	ErrDataCorrupted   = New(1302, "data corrupted")
-	ErrDataConsistency = New(1303, "data consistency error")
+	ErrDataValidation  = NamespaceData.New(1300, "data validation error", WithLang("zh-CN", "数据校验错误"), WithHTTPStatus(http.StatusBadRequest), WithSeverity(SeverityWarning))
+	ErrDataFormat      = NamespaceData.New(1301, "data format error", WithLang("zh-CN", "数据格式错误"), WithHTTPStatus(http.StatusBadRequest), WithSeverity(SeverityWarning))
+	ErrDataCorrupted   = NamespaceData.New(1302, "data corrupted", WithLang("zh-CN", "数据损坏"), WithGRPCCode(codes.DataLoss), WithSeverity(SeverityCritical))
+	ErrDataConsistency = NamespaceData.New(1303, "data consistency error", WithLang("zh-CN", "数据不一致"))
 
 	// 数据库错误
-	ErrDatabase               = New(1400, "database error")
-	ErrDBConnection           = New(1401, "database connection error")
-	ErrDBQuery                = New(1402, "database query error")
-	ErrDBTransaction          = New(1403, "database transaction error")
-	ErrDBRollback             = New(1404, "database rollback error")
-	ErrDBInsert               = New(1405, "database insert error")
-	ErrDBUpdate               = New(1406, "database update error")
-	ErrDBDelete               = New(1407, "database delete error")
-	ErrDBForeignKey           = New(1408, "database foreign key error")
-	ErrDBUnique               = New(1409, "database unique constraint error")
-	ErrDBIndex                = New(1410, "database index error")
-	ErrDBLock                 = New(1411, "database lock error")
-	ErrDBTimeout              = New(1412, "database timeout error")
-	ErrDBConnectionLimit      = New(1413, "database connection limit error")
-	ErrDBTransactionIsolation = New(1414, "database transaction isolation error")
-	ErrDBTransactionDeadlock  = New(1415, "database transaction deadlock error")
-	ErrDBTransactionRollback  = New(1416, "database transaction rollback error")
-	ErrDBTransactionCommit    = New(1417, "database transaction commit error")
+	ErrDatabase               = NamespaceDB.New(1400, "database error", WithLang("zh-CN", "数据库错误"))
+	ErrDBConnection           = NamespaceDB.New(1401, "database connection error", WithLang("zh-CN", "数据库连接错误"), WithHTTPStatus(http.StatusServiceUnavailable), WithRetryable(), WithSeverity(SeverityCritical))
+	ErrDBQuery                = NamespaceDB.New(1402, "database query error", WithLang("zh-CN", "数据库查询错误"))
+	ErrDBTransaction          = NamespaceDB.New(1403, "database transaction error", WithLang("zh-CN", "数据库事务错误"))
+	ErrDBRollback             = NamespaceDB.New(1404, "database rollback error", WithLang("zh-CN", "数据库回滚错误"))
+	ErrDBInsert               = NamespaceDB.New(1405, "database insert error", WithLang("zh-CN", "数据库插入错误"))
+	ErrDBUpdate               = NamespaceDB.New(1406, "database update error", WithLang("zh-CN", "数据库更新错误"))
+	ErrDBDelete               = NamespaceDB.New(1407, "database delete error", WithLang("zh-CN", "数据库删除错误"))
+	ErrDBForeignKey           = NamespaceDB.New(1408, "database foreign key error", WithLang("zh-CN", "数据库外键约束错误"))
+	ErrDBUnique               = NamespaceDB.New(1409, "database unique constraint error", WithLang("zh-CN", "数据库唯一约束错误"), WithHTTPStatus(http.StatusConflict))
+	ErrDBIndex                = NamespaceDB.New(1410, "database index error", WithLang("zh-CN", "数据库索引错误"))
+	ErrDBLock                 = NamespaceDB.New(1411, "database lock error", WithLang("zh-CN", "数据库锁错误"), WithRetryable())
+	ErrDBTimeout              = NamespaceDB.New(1412, "database timeout error", WithLang("zh-CN", "数据库超时"), WithHTTPStatus(http.StatusGatewayTimeout), WithRetryable())
+	ErrDBConnectionLimit      = NamespaceDB.New(1413, "database connection limit error", WithLang("zh-CN", "数据库连接数超限"), WithHTTPStatus(http.StatusServiceUnavailable), WithRetryable())
+	ErrDBTransactionIsolation = NamespaceDB.New(1414, "database transaction isolation error", WithLang("zh-CN", "数据库事务隔离错误"))
+	ErrDBTransactionDeadlock  = NamespaceDB.New(1415, "database transaction deadlock error", WithLang("zh-CN", "数据库事务死锁"), WithGRPCCode(codes.Aborted), WithRetryable())
+	ErrDBTransactionRollback  = NamespaceDB.New(1416, "database transaction rollback error", WithLang("zh-CN", "数据库事务回滚错误"))
+	ErrDBTransactionCommit    = NamespaceDB.New(1417, "database transaction commit error", WithLang("zh-CN", "数据库事务提交错误"))
 
 	// redis错误
-	ErrRedisConnection = New(1500, "redis connection error")
+	ErrRedisConnection = NamespaceRedis.New(1500, "redis connection error", WithLang("zh-CN", "redis 连接错误"), WithHTTPStatus(http.StatusServiceUnavailable), WithRetryable())
 )
 
 // EcodeError 是一个带有错误码的错误类型
 type EcodeError struct {
-	code  int
-	msg   string
-	cause error
+	code    int
+	msg     string
+	cause   error
+	details []Detail // 附带的错误详情
+	stack   stack    // 创建或包装时的调用栈，开启 EnableStack 后才会记录
 }
 
 // Error 实现 error 接口
@@ -113,39 +113,29 @@ func (e *EcodeError) GetMessage() string {
 	return e.msg
 }
 
-// checkDuplicateCode 检查错误码是否已被使用
-func checkDuplicateCode(code int) error {
-	if !enableCheckDuplicate {
-		return nil
-	}
-
-	codesMutex.RLock()
-	_, exists := usedCodes[code]
-	codesMutex.RUnlock()
-
-	if exists {
-		return fmt.Errorf("error code %d is already in use", code)
-	}
-
-	// 注册新的错误码
-	codesMutex.Lock()
-	usedCodes[code] = true
-	codesMutex.Unlock()
-
-	return nil
-}
-
-// New 创建一个新的错误
-func New(code int, msg string) error {
+// register 在全局注册表中登记错误码，开启重复检查时错误码重复会 panic；
+// ns 为 nil 表示不通过命名空间创建，错误码不能落在已注册命名空间的号段内，
+// 注册了业务命名空间后也不能落在所有命名空间之外
+func register(code int, msg string, ns *Namespace, opts ...Option) {
 	// 确保错误码不小于 1000
 	if code < 1000 {
 		panic("error code must be at least 1000") // 暴力panic
 	}
 
-	// 检查错误码是否重复
-	if err := checkDuplicateCode(code); err != nil {
+	if ns != nil {
+		opts = append(opts, withNamespace(ns.Name))
+	} else if err := checkOutsideNamespace(code); err != nil {
+		panic(err.Error())
+	}
+	if _, err := defaultRegistry.Register(code, msg, opts...); err != nil && enableCheckDuplicate {
 		panic(fmt.Sprintf("duplicate error code: %v", err))
 	}
+}
+
+// New 创建一个新的错误，并在全局注册表中登记错误码和元信息
+// opts 用于设置 HTTP 状态码、gRPC 状态码、是否可重试、严重程度和多语言消息
+func New(code int, msg string, opts ...Option) error {
+	register(code, msg, nil, opts...)
 
 	return &EcodeError{
 		code: code,
@@ -153,26 +143,21 @@ func New(code int, msg string) error {
 		// 当使用 New 创建错误时，cause 默认为 nil
 		// 因为这是一个新创建的错误，没有原始错误可以包装
 		cause: nil,
+		stack: callers(1),
 	}
 }
 
 // Newf 使用格式化字符串创建一个新的错误
 func Newf(code int, format string, args ...interface{}) error {
-	// 确保错误码不小于 1000
-	if code < 1000 {
-		panic("error code must be at least 1000") // 暴力panic
-	}
-
-	// 检查错误码是否重复
-	if err := checkDuplicateCode(code); err != nil {
-		panic(fmt.Sprintf("duplicate error code: %v", err))
-	}
+	msg := fmt.Sprintf(format, args...)
+	register(code, msg, nil)
 
 	return &EcodeError{
 		code: code,
-		msg:  fmt.Sprintf(format, args...),
+		msg:  msg,
 		// 当使用 Newf 创建错误时，cause 默认为 nil
 		cause: nil,
+		stack: callers(1),
 	}
 }
 
@@ -190,6 +175,7 @@ func Wrap(err error, msg string) error {
 			code:  ec.code, // 使用原始错误的错误码
 			msg:   msg,     // 使用新的错误消息
 			cause: err,     // 保留原始错误作为 cause
+			stack: callers(1),
 		}
 	}
 
@@ -198,6 +184,7 @@ func Wrap(err error, msg string) error {
 		code:  ErrCodeUnknown, // 使用未知错误码
 		msg:   msg,            // 使用新的错误消息
 		cause: err,            // 保留原始错误作为 cause
+		stack: callers(1),
 	}
 }
 
@@ -214,6 +201,7 @@ func Wrapf(err error, format string, args ...interface{}) error {
 			code:  ec.code,                      // 使用原始错误的错误码
 			msg:   fmt.Sprintf(format, args...), // 使用格式化的新错误消息
 			cause: err,                          // 保留原始错误作为 cause
+			stack: callers(1),
 		}
 	}
 
@@ -222,6 +210,7 @@ func Wrapf(err error, format string, args ...interface{}) error {
 		code:  ErrCodeUnknown,               // 使用未知错误码
 		msg:   fmt.Sprintf(format, args...), // 使用格式化的新错误消息
 		cause: err,                          // 保留原始错误作为 cause
+		stack: callers(1),
 	}
 }
 
@@ -238,6 +227,7 @@ func WrapError(err error, newErr error) error {
 			code:  ec.code,
 			msg:   newErr.Error(),
 			cause: err,
+			stack: callers(1),
 		}
 	}
 
@@ -245,6 +235,7 @@ func WrapError(err error, newErr error) error {
 		code:  ErrCodeUnknown,
 		msg:   newErr.Error(),
 		cause: err,
+		stack: callers(1),
 	}
 }
 
@@ -304,36 +295,42 @@ func IsError(err error, target error) bool {
 
 // NewErrorWithCause 创建一个带有原因的错误
 func NewErrorWithCause(code int, msg string, cause error) error {
-	// 确保错误码不小于 1000
-	if code < 1000 {
-		panic("error code must be at least 1000")
-	}
+	register(code, msg, nil)
 
-	// 检查错误码是否重复
-	if err := checkDuplicateCode(code); err != nil {
-		panic(fmt.Sprintf("duplicate error code: %v", err))
+	return &EcodeError{
+		code:  code,
+		msg:   msg,
+		cause: cause,
+		stack: callers(1),
 	}
+}
 
+// FromCode 按错误码和消息还原错误，不在注册表中登记错误码，用于客户端还原下游服务返回的错误。
+// 错误码已注册时 HTTPStatus、IsRetryable 等使用注册的元信息，cause 可以为 nil
+func FromCode(code int, msg string, cause error) error {
 	return &EcodeError{
 		code:  code,
 		msg:   msg,
 		cause: cause,
+		stack: callers(1),
 	}
 }
 
-// GetErrorCodeMessage 根据错误码获取预定义的错误消息
+// GetErrorCodeMessage 根据错误码获取注册的错误消息
 func GetErrorCodeMessage(code int) string {
-	// 这里可以根据需要添加更多的错误码到错误消息的映射
-	switch code {
-	case ErrCodeUnknown:
-		return "unknown error"
-	default:
-		return fmt.Sprintf("error with code %d", code)
+	if meta, ok := Lookup(code); ok {
+		return meta.Message
 	}
+	return fmt.Sprintf("error with code %d", code)
 }
 
 // WithMessage 返回带有新消息的错误，保持错误码不变
 func WithMessage(err error, msg string) error {
+	return withMessage(err, msg, 1)
+}
+
+// withMessage skip 为记录调用栈时需要跳过的调用层数
+func withMessage(err error, msg string, skip int) error {
 	if err == nil {
 		return nil
 	}
@@ -344,6 +341,7 @@ func WithMessage(err error, msg string) error {
 			code:  ErrCodeUnknown,
 			msg:   msg,
 			cause: err,
+			stack: callers(skip + 1),
 		}
 	}
 
@@ -351,6 +349,7 @@ func WithMessage(err error, msg string) error {
 		code:  e.code,
 		msg:   msg,
 		cause: e,
+		stack: callers(skip + 1),
 	}
 }
 
@@ -360,5 +359,5 @@ func WithMessagef(err error, format string, args ...interface{}) error {
 		return nil
 	}
 
-	return WithMessage(err, fmt.Sprintf(format, args...))
+	return withMessage(err, fmt.Sprintf(format, args...), 1)
 }
@@ -283,9 +283,10 @@ func TestInterfaceMethods(t *testing.T) {
 // 测试边界情况
 func TestEdgeCases(t *testing.T) {
 	Convey("EdgeCases", t, func() {
-		// 测试错误码刚好等于 1000 的情况
+		// 测试错误码刚好等于 1000 的情况，1000 属于内置的 system 命名空间，只能通过命名空间创建
 		code := 1000
-		err := New(code, "error code exactly 1000")
+		So(func() { New(code, "error code exactly 1000") }, ShouldPanic)
+		err := NamespaceSystem.New(code, "error code exactly 1000")
 		So(Code(err), ShouldEqual, code)
 
 		// 测试空字符串消息
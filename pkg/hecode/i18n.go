@@ -0,0 +1,71 @@
+package hecode
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage 解析 Accept-Language 请求头，按权重从高到低返回语言标签
+// 例如 "zh-CN,zh;q=0.9,en;q=0.8" 返回 [zh-cn zh en]
+func ParseAcceptLanguage(header string) []string {
+	type langQ struct {
+		lang string
+		q    float64
+	}
+
+	var langs []langQ
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lang, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		lang = normalizeLang(lang)
+		if lang == "" || lang == "*" || q <= 0 {
+			continue
+		}
+		langs = append(langs, langQ{lang: lang, q: q})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+	result := make([]string, 0, len(langs))
+	for _, l := range langs {
+		result = append(result, l.lang)
+	}
+	return result
+}
+
+// LocalizedMessage 根据 Accept-Language 返回错误的本地化消息
+// 仅当错误消息未被 Wrap/WithMessage 修改时才替换为对应语言的消息，避免丢失业务补充的上下文
+func LocalizedMessage(err error, acceptLanguage string) string {
+	if err == nil {
+		return ""
+	}
+	var ec *EcodeError
+	if !errors.As(err, &ec) {
+		return err.Error()
+	}
+
+	meta, ok := Lookup(ec.Code())
+	if !ok || ec.GetMessage() != meta.Message {
+		return ec.GetMessage()
+	}
+	for _, lang := range ParseAcceptLanguage(acceptLanguage) {
+		if msg, ok := meta.lookupLang(lang); ok {
+			return msg
+		}
+	}
+	return meta.Message
+}
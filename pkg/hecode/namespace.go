@@ -0,0 +1,158 @@
+package hecode
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Namespace 错误码命名空间，每个模块独占一段错误码，[Min, Max] 为闭区间
+type Namespace struct {
+	Name string `json:"name"`
+	Min  int    `json:"min"`
+	Max  int    `json:"max"`
+}
+
+// 框架内置的命名空间，业务模块请使用 RegisterNamespace 注册 2000 以上的号段
+var (
+	NamespaceSystem = MustRegisterNamespace("system", 1000, 1099)
+	NamespaceParam  = MustRegisterNamespace("param", 1100, 1199)
+	NamespaceBiz    = MustRegisterNamespace("biz", 1200, 1299)
+	NamespaceData   = MustRegisterNamespace("data", 1300, 1399)
+	NamespaceDB     = MustRegisterNamespace("db", 1400, 1499)
+	NamespaceRedis  = MustRegisterNamespace("redis", 1500, 1599)
+)
+
+var (
+	namespaces   []*Namespace
+	namespacesMu sync.RWMutex
+)
+
+// RegisterNamespace 注册错误码命名空间，名称重复、号段与已有命名空间重叠，
+// 或者号段内已有不通过命名空间创建的错误码时返回错误
+func RegisterNamespace(name string, min, max int) (*Namespace, error) {
+	if name == "" {
+		return nil, fmt.Errorf("namespace name is empty")
+	}
+	if min < 1000 || min > max {
+		return nil, fmt.Errorf("invalid range [%d, %d] for namespace %q, codes must be at least 1000", min, max, name)
+	}
+
+	namespacesMu.Lock()
+	defer namespacesMu.Unlock()
+	for _, ns := range namespaces {
+		if ns.Name == name {
+			return nil, fmt.Errorf("namespace %q is already registered", name)
+		}
+		if min <= ns.Max && ns.Min <= max {
+			return nil, fmt.Errorf("range [%d, %d] of namespace %q overlaps namespace %q [%d, %d]", min, max, name, ns.Name, ns.Min, ns.Max)
+		}
+	}
+	for _, meta := range defaultRegistry.All() {
+		if meta.Namespace == "" && meta.Code >= min && meta.Code <= max {
+			return nil, fmt.Errorf("range [%d, %d] of namespace %q contains error code %d created without a namespace", min, max, name, meta.Code)
+		}
+	}
+	ns := &Namespace{Name: name, Min: min, Max: max}
+	namespaces = append(namespaces, ns)
+	return ns, nil
+}
+
+// MustRegisterNamespace 注册错误码命名空间，失败时 panic，适合在包级变量中使用
+func MustRegisterNamespace(name string, min, max int) *Namespace {
+	ns, err := RegisterNamespace(name, min, max)
+	if err != nil {
+		panic(err)
+	}
+	return ns
+}
+
+// Namespaces 返回所有命名空间，按号段升序排列
+func Namespaces() []*Namespace {
+	namespacesMu.RLock()
+	result := make([]*Namespace, len(namespaces))
+	copy(result, namespaces)
+	namespacesMu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Min < result[j].Min
+	})
+	return result
+}
+
+// NamespaceOf 返回错误码所属的命名空间
+func NamespaceOf(code int) (*Namespace, bool) {
+	namespacesMu.RLock()
+	defer namespacesMu.RUnlock()
+	for _, ns := range namespaces {
+		if ns.Contains(code) {
+			return ns, true
+		}
+	}
+	return nil, false
+}
+
+// Contains 判断错误码是否在命名空间的号段内
+func (ns *Namespace) Contains(code int) bool {
+	return code >= ns.Min && code <= ns.Max
+}
+
+// New 在命名空间内创建错误，错误码超出号段时 panic
+func (ns *Namespace) New(code int, msg string, opts ...Option) error {
+	ns.check(code)
+	register(code, msg, ns, opts...)
+	return &EcodeError{code: code, msg: msg, stack: callers(1)}
+}
+
+// Newf 在命名空间内使用格式化字符串创建错误，错误码超出号段时 panic
+func (ns *Namespace) Newf(code int, format string, args ...interface{}) error {
+	ns.check(code)
+	msg := fmt.Sprintf(format, args...)
+	register(code, msg, ns)
+	return &EcodeError{code: code, msg: msg, stack: callers(1)}
+}
+
+// String 返回 "name[min-max]" 格式的字符串
+func (ns *Namespace) String() string {
+	return fmt.Sprintf("%s[%d-%d]", ns.Name, ns.Min, ns.Max)
+}
+
+func (ns *Namespace) check(code int) {
+	if !ns.Contains(code) {
+		panic(fmt.Sprintf("error code %d is out of range of namespace %s", code, ns))
+	}
+}
+
+// checkOutsideNamespace 检查不通过命名空间创建的错误码：不能占用已注册命名空间的号段，
+// 注册了内置命名空间以外的业务命名空间后，错误码必须属于某个命名空间
+func checkOutsideNamespace(code int) error {
+	namespacesMu.RLock()
+	defer namespacesMu.RUnlock()
+	modular := false
+	for _, ns := range namespaces {
+		if ns.Contains(code) {
+			return fmt.Errorf("error code %d is in the range of namespace %s, create it with the namespace", code, ns)
+		}
+		modular = modular || !ns.builtin()
+	}
+	if modular {
+		return fmt.Errorf("error code %d is out of range of all registered namespaces", code)
+	}
+	return nil
+}
+
+// builtin 是否为框架内置的命名空间
+func (ns *Namespace) builtin() bool {
+	switch ns {
+	case NamespaceSystem, NamespaceParam, NamespaceBiz, NamespaceData, NamespaceDB, NamespaceRedis:
+		return true
+	}
+	return false
+}
+
+// withNamespace 记录错误码所属的命名空间
+func withNamespace(name string) Option {
+	return func(m *Meta) {
+		m.Namespace = name
+	}
+}
@@ -0,0 +1,56 @@
+package hecode
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNamespace(t *testing.T) {
+	Convey("Namespace", t, func() {
+		// 还没有业务命名空间时可以直接创建错误码，之后号段包含它的命名空间不能注册
+		So(Code(New(300001, "legacy")), ShouldEqual, 300001)
+		_, err := RegisterNamespace("legacy", 300000, 300999)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "contains error code 300001 created without a namespace")
+
+		ns, err := RegisterNamespace("order", 200000, 200999)
+		So(err, ShouldBeNil)
+		So(ns.String(), ShouldEqual, "order[200000-200999]")
+
+		// 名称重复、号段重叠、号段无效
+		_, err = RegisterNamespace("order", 201000, 201999)
+		So(err, ShouldNotBeNil)
+		_, err = RegisterNamespace("payment", 200500, 201499)
+		So(err, ShouldNotBeNil)
+		_, err = RegisterNamespace("invalid", 999, 1001)
+		So(err, ShouldNotBeNil)
+		So(func() { MustRegisterNamespace("system", 300000, 300999) }, ShouldPanic)
+
+		err = ns.New(200001, "order not found", WithHTTPStatus(404))
+		So(Code(err), ShouldEqual, 200001)
+		meta, ok := Lookup(200001)
+		So(ok, ShouldBeTrue)
+		So(meta.Namespace, ShouldEqual, "order")
+		So(meta.HTTPStatus, ShouldEqual, 404)
+		So(Code(ns.Newf(200002, "order %s", "closed")), ShouldEqual, 200002)
+
+		So(func() { ns.New(201000, "out of range") }, ShouldPanic)
+		// 不通过命名空间创建的错误码不能占用命名空间的号段，注册业务命名空间后也不能在所有号段之外
+		So(func() { New(200500, "in order") }, ShouldPanicWith, "error code 200500 is in the range of namespace order[200000-200999], create it with the namespace")
+		So(func() { New(1250, "in biz") }, ShouldPanic)
+		So(func() { Newf(400001, "stray %d", 1) }, ShouldPanicWith, "error code 400001 is out of range of all registered namespaces")
+
+		got, ok := NamespaceOf(200100)
+		So(ok, ShouldBeTrue)
+		So(got, ShouldEqual, ns)
+		_, ok = NamespaceOf(999999)
+		So(ok, ShouldBeFalse)
+
+		// 内置命名空间
+		So(MetaOf(ErrInvalidParam).Namespace, ShouldEqual, "param")
+		So(NamespaceRedis.Contains(Code(ErrRedisConnection)), ShouldBeTrue)
+		all := Namespaces()
+		So(all[0], ShouldEqual, NamespaceSystem)
+	})
+}
@@ -0,0 +1,299 @@
+package hecode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Severity 错误的严重程度
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityError    Severity = "error"
+	SeverityCritical Severity = "critical"
+)
+
+// DefaultLang 默认消息的语言
+const DefaultLang = "en"
+
+// Meta 错误码的元信息
+type Meta struct {
+	Code       int               `json:"code"`
+	Namespace  string            `json:"namespace,omitempty"` // 所属命名空间，使用 Namespace.New 创建时记录
+	Message    string            `json:"message"`             // 默认消息（英文）
+	HTTPStatus int               `json:"http_status"`         // 响应的 HTTP 状态码
+	GRPCCode   codes.Code        `json:"grpc_code"`           // 对应的 gRPC 状态码
+	Retryable  bool              `json:"retryable"`           // 客户端是否可以重试
+	Severity   Severity          `json:"severity"`            // 严重程度
+	Messages   map[string]string `json:"messages,omitempty"`  // 多语言消息，key 为小写语言标签，例如 zh-cn
+}
+
+// Localize 返回指定语言的消息，没有对应语言的消息时返回默认消息
+func (m Meta) Localize(lang string) string {
+	if msg, ok := m.lookupLang(lang); ok {
+		return msg
+	}
+	return m.Message
+}
+
+// lookupLang 查找指定语言的消息，优先完全匹配，其次匹配主语言
+func (m Meta) lookupLang(lang string) (string, bool) {
+	lang = normalizeLang(lang)
+	if lang == "" {
+		return "", false
+	}
+	if msg, ok := m.Messages[lang]; ok {
+		return msg, true
+	}
+	primary, _, _ := strings.Cut(lang, "-")
+	if msg, ok := m.Messages[primary]; ok {
+		return msg, true
+	}
+	// zh 可以匹配 zh-cn 等带地区的消息
+	for l, msg := range m.Messages {
+		if strings.HasPrefix(l, primary+"-") {
+			return msg, true
+		}
+	}
+	if primary == DefaultLang {
+		return m.Message, true
+	}
+	return "", false
+}
+
+// Option 错误码元信息选项
+type Option func(*Meta)
+
+// WithHTTPStatus 设置 HTTP 状态码，未设置 gRPC 状态码时按 HTTP 状态码推导
+func WithHTTPStatus(status int) Option {
+	return func(m *Meta) {
+		m.HTTPStatus = status
+	}
+}
+
+// WithGRPCCode 设置 gRPC 状态码
+func WithGRPCCode(code codes.Code) Option {
+	return func(m *Meta) {
+		m.GRPCCode = code
+	}
+}
+
+// WithRetryable 标记错误可以重试
+func WithRetryable() Option {
+	return func(m *Meta) {
+		m.Retryable = true
+	}
+}
+
+// WithSeverity 设置严重程度
+func WithSeverity(severity Severity) Option {
+	return func(m *Meta) {
+		m.Severity = severity
+	}
+}
+
+// WithLang 设置指定语言的消息，例如 WithLang("zh-CN", "参数错误")
+func WithLang(lang, msg string) Option {
+	return func(m *Meta) {
+		if m.Messages == nil {
+			m.Messages = make(map[string]string)
+		}
+		m.Messages[normalizeLang(lang)] = msg
+	}
+}
+
+// Registry 错误码注册表
+type Registry struct {
+	mu    sync.RWMutex
+	metas map[int]Meta
+}
+
+// NewRegistry 创建错误码注册表
+func NewRegistry() *Registry {
+	return &Registry{metas: make(map[int]Meta)}
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry 返回全局错误码注册表，New/Newf 创建的错误码都注册在这里
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register 注册错误码，错误码重复时返回错误
+func (r *Registry) Register(code int, msg string, opts ...Option) (Meta, error) {
+	meta := newMeta(code, msg, opts...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.metas[code]; exists {
+		return Meta{}, fmt.Errorf("error code %d is already in use", code)
+	}
+	r.metas[code] = meta
+	return meta, nil
+}
+
+// Lookup 查询错误码的元信息
+func (r *Registry) Lookup(code int) (Meta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.metas[code]
+	return meta, ok
+}
+
+// All 返回所有错误码，按错误码升序排列
+func (r *Registry) All() []Meta {
+	r.mu.RLock()
+	metas := make([]Meta, 0, len(r.metas))
+	for _, meta := range r.metas {
+		metas = append(metas, meta)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].Code < metas[j].Code
+	})
+	return metas
+}
+
+// ExportJSON 以 JSON 格式导出所有错误码，供客户端团队使用
+func (r *Registry) ExportJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.All())
+}
+
+// ExportMarkdown 以 Markdown 表格导出所有错误码，langs 指定额外输出的语言列
+func (r *Registry) ExportMarkdown(w io.Writer, langs ...string) error {
+	var b strings.Builder
+	b.WriteString("| Code | Message | HTTP Status | gRPC Code | Retryable | Severity |")
+	for _, lang := range langs {
+		b.WriteString(" " + lang + " |")
+	}
+	b.WriteString("\n|------|---------|-------------|-----------|-----------|----------|")
+	for range langs {
+		b.WriteString("------|")
+	}
+	b.WriteString("\n")
+
+	for _, meta := range r.All() {
+		fmt.Fprintf(&b, "| %d | %s | %d | %s | %t | %s |",
+			meta.Code, escapeMarkdown(meta.Message), meta.HTTPStatus, meta.GRPCCode, meta.Retryable, meta.Severity)
+		for _, lang := range langs {
+			b.WriteString(" " + escapeMarkdown(meta.Localize(lang)) + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Lookup 在全局注册表中查询错误码的元信息
+func Lookup(code int) (Meta, bool) {
+	return defaultRegistry.Lookup(code)
+}
+
+// MetaOf 获取错误对应的元信息，未注册的错误码返回按默认规则生成的元信息
+func MetaOf(err error) Meta {
+	code := Code(err)
+	if meta, ok := Lookup(code); ok {
+		return meta
+	}
+	if code == 0 {
+		return newMeta(0, http.StatusText(http.StatusInternalServerError))
+	}
+	return newMeta(code, GetErrorCodeMessage(code))
+}
+
+// HTTPStatus 获取错误对应的 HTTP 状态码
+func HTTPStatus(err error) int {
+	return MetaOf(err).HTTPStatus
+}
+
+// GRPCCode 获取错误对应的 gRPC 状态码
+func GRPCCode(err error) codes.Code {
+	return MetaOf(err).GRPCCode
+}
+
+// IsRetryable 判断错误是否可以重试
+func IsRetryable(err error) bool {
+	return MetaOf(err).Retryable
+}
+
+// ExportJSON 以 JSON 格式导出全局注册表
+func ExportJSON(w io.Writer) error {
+	return defaultRegistry.ExportJSON(w)
+}
+
+// ExportMarkdown 以 Markdown 表格导出全局注册表
+func ExportMarkdown(w io.Writer, langs ...string) error {
+	return defaultRegistry.ExportMarkdown(w, langs...)
+}
+
+func newMeta(code int, msg string, opts ...Option) Meta {
+	meta := Meta{
+		Code:       code,
+		Message:    msg,
+		HTTPStatus: http.StatusInternalServerError,
+		GRPCCode:   grpcCodeUnset,
+		Severity:   SeverityError,
+	}
+	for _, opt := range opts {
+		opt(&meta)
+	}
+	if meta.GRPCCode == grpcCodeUnset {
+		meta.GRPCCode = grpcCodeFromHTTP(meta.HTTPStatus)
+	}
+	return meta
+}
+
+// grpcCodeUnset 未设置 gRPC 状态码的标记，codes.OK 是合法值不能作为标记
+const grpcCodeUnset codes.Code = 1<<32 - 1
+
+// grpcCodeFromHTTP 按 HTTP 状态码推导 gRPC 状态码，与 grpc-gateway 的映射保持一致
+func grpcCodeFromHTTP(status int) codes.Code {
+	switch status {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+func normalizeLang(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,90 @@
+package hecode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRegistry(t *testing.T) {
+	Convey("Registry", t, func() {
+		r := NewRegistry()
+		meta, err := r.Register(2001, "not found", WithHTTPStatus(http.StatusNotFound), WithLang("zh-CN", "不存在"))
+		So(err, ShouldBeNil)
+		So(meta.HTTPStatus, ShouldEqual, http.StatusNotFound)
+		So(meta.GRPCCode, ShouldEqual, codes.NotFound) // 按 HTTP 状态码推导
+		So(meta.Severity, ShouldEqual, SeverityError)
+
+		_, err = r.Register(2001, "duplicate")
+		So(err, ShouldNotBeNil)
+
+		_, err = r.Register(2000, "custom", WithGRPCCode(codes.OK), WithRetryable())
+		So(err, ShouldBeNil)
+		got, ok := r.Lookup(2000)
+		So(ok, ShouldBeTrue)
+		So(got.GRPCCode, ShouldEqual, codes.OK)
+		So(got.HTTPStatus, ShouldEqual, http.StatusInternalServerError)
+		So(got.Retryable, ShouldBeTrue)
+
+		all := r.All()
+		So(len(all), ShouldEqual, 2)
+		So(all[0].Code, ShouldEqual, 2000)
+	})
+}
+
+func TestRegistryExport(t *testing.T) {
+	Convey("RegistryExport", t, func() {
+		r := NewRegistry()
+		_, _ = r.Register(2001, "a|b", WithLang("zh-CN", "中文"))
+
+		var buf bytes.Buffer
+		So(r.ExportJSON(&buf), ShouldBeNil)
+		var metas []Meta
+		So(json.Unmarshal(buf.Bytes(), &metas), ShouldBeNil)
+		So(len(metas), ShouldEqual, 1)
+		So(metas[0].Messages["zh-cn"], ShouldEqual, "中文")
+
+		buf.Reset()
+		So(r.ExportMarkdown(&buf, "zh-CN"), ShouldBeNil)
+		So(buf.String(), ShouldContainSubstring, "| 2001 | a\\|b | 500 | Internal | false | error | 中文 |")
+	})
+}
+
+func TestPredefinedMeta(t *testing.T) {
+	Convey("PredefinedMeta", t, func() {
+		So(HTTPStatus(ErrInvalidParam), ShouldEqual, http.StatusBadRequest)
+		So(HTTPStatus(ErrNotFound), ShouldEqual, http.StatusNotFound)
+		So(HTTPStatus(ErrUnauthorized), ShouldEqual, http.StatusUnauthorized)
+		So(HTTPStatus(ErrInternal), ShouldEqual, http.StatusInternalServerError)
+		So(HTTPStatus(Wrap(ErrNotFound, "user")), ShouldEqual, http.StatusNotFound)
+		So(HTTPStatus(errors.New("plain")), ShouldEqual, http.StatusInternalServerError)
+
+		So(GRPCCode(ErrTimeout), ShouldEqual, codes.DeadlineExceeded)
+		So(GRPCCode(ErrBusinessRule), ShouldEqual, codes.FailedPrecondition)
+		So(GRPCCode(ErrUnknown), ShouldEqual, codes.Unknown)
+
+		So(IsRetryable(ErrTimeout), ShouldBeTrue)
+		So(IsRetryable(ErrInvalidParam), ShouldBeFalse)
+		So(GetErrorCodeMessage(Code(ErrNotFound)), ShouldEqual, "resource not found")
+	})
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	Convey("LocalizedMessage", t, func() {
+		So(ParseAcceptLanguage("en;q=0.5, zh-CN, zh;q=0.9, *;q=0.1"), ShouldResemble, []string{"zh-cn", "zh", "en"})
+
+		So(LocalizedMessage(ErrInvalidParam, "zh-CN,zh;q=0.9"), ShouldEqual, "参数错误")
+		So(LocalizedMessage(ErrInvalidParam, "zh-TW"), ShouldEqual, "参数错误")
+		So(LocalizedMessage(ErrInvalidParam, "fr,en;q=0.8"), ShouldEqual, "invalid parameter")
+		So(LocalizedMessage(ErrInvalidParam, ""), ShouldEqual, "invalid parameter")
+
+		// 修改过消息的错误保留业务上下文
+		So(LocalizedMessage(WithMessage(ErrInvalidParam, "name is required"), "zh-CN"), ShouldEqual, "name is required")
+		So(LocalizedMessage(errors.New("plain"), "zh-CN"), ShouldEqual, "plain")
+	})
+}
@@ -0,0 +1,110 @@
+package hecode
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// stackDepth 最多记录的调用栈层数
+const stackDepth = 32
+
+// stackEnabled 是否在创建和包装错误时记录调用栈，默认值由构建标签 hecode_stack 决定
+var stackEnabled atomic.Bool
+
+func init() {
+	stackEnabled.Store(defaultStackEnabled)
+}
+
+// EnableStack 开启或关闭调用栈记录，开启后 New/Wrap/WithMessage 等函数会记录调用位置
+// 记录调用栈有一定开销，建议只在开发环境或排查问题时开启
+func EnableStack(enable bool) {
+	stackEnabled.Store(enable)
+}
+
+// stackHolders 通过 EnableStackUntil 开启且 ctx 尚未结束的数量
+var stackHolders atomic.Int64
+
+// EnableStackUntil 在 ctx 结束之前开启调用栈记录，ctx 结束后恢复为 EnableStack 的设置，多次调用互不影响。
+// App 根据 ecode.stack 配置在 App 的 context 取消之前开启；调用栈在创建错误时记录，无法区分 App，
+// 同一进程中任何一个 App 开启期间所有错误都会记录调用栈
+func EnableStackUntil(ctx context.Context) {
+	stackHolders.Add(1)
+	context.AfterFunc(ctx, func() {
+		stackHolders.Add(-1)
+	})
+}
+
+// StackEnabled 返回是否开启了调用栈记录
+func StackEnabled() bool {
+	return stackEnabled.Load() || stackHolders.Load() > 0
+}
+
+// Frame 调用栈中的一帧
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String 返回 "函数 文件:行号" 格式的字符串
+func (f Frame) String() string {
+	return fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line)
+}
+
+// stack 记录的程序计数器，延迟到使用时再解析成 Frame
+type stack []uintptr
+
+// callers 记录调用栈，skip 为需要跳过的 hecode 内部调用层数
+func callers(skip int) stack {
+	if !StackEnabled() {
+		return nil
+	}
+	pcs := make([]uintptr, stackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[:n]
+}
+
+// frames 将程序计数器解析为调用栈帧
+func (s stack) frames() []Frame {
+	if len(s) == 0 {
+		return nil
+	}
+	result := make([]Frame, 0, len(s))
+	frames := runtime.CallersFrames(s)
+	for {
+		frame, more := frames.Next()
+		result = append(result, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// String 每行一帧，格式与 panic 输出类似
+func (s stack) String() string {
+	var b strings.Builder
+	for _, f := range s.frames() {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// StackTrace 返回错误创建或包装时的调用栈，未开启调用栈记录时返回 nil
+func (e *EcodeError) StackTrace() []Frame {
+	return e.stack.frames()
+}
+
+// StackTrace 返回错误链上最内层记录的调用栈，即最接近错误源头的位置
+func StackTrace(err error) []Frame {
+	var frames []Frame
+	for _, ec := range chain(err) {
+		if len(ec.stack) > 0 {
+			frames = ec.stack.frames()
+		}
+	}
+	return frames
+}
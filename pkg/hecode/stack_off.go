@@ -0,0 +1,6 @@
+//go:build !hecode_stack
+
+package hecode
+
+// 默认不记录调用栈，可通过 EnableStack 或 -tags hecode_stack 开启
+const defaultStackEnabled = false
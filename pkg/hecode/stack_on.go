@@ -0,0 +1,6 @@
+//go:build hecode_stack
+
+package hecode
+
+// 使用 -tags hecode_stack 构建时默认开启调用栈记录
+const defaultStackEnabled = true
@@ -0,0 +1,36 @@
+package hecode
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// MarshalLogObject 实现 zapcore.ObjectMarshaler，输出错误码、消息、详情、调用栈以及完整的错误链
+func (e *EcodeError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("code", e.code)
+	enc.AddString("msg", e.msg)
+	if len(e.details) > 0 {
+		if err := enc.AddReflected("details", DetailsData(&EcodeError{details: e.details})); err != nil {
+			return err
+		}
+	}
+	if len(e.stack) > 0 {
+		enc.AddString("stack", e.stack.String())
+	}
+	if e.cause == nil {
+		return nil
+	}
+	if cause, ok := e.cause.(*EcodeError); ok {
+		return enc.AddObject("cause", cause)
+	}
+	enc.AddString("cause", e.cause.Error())
+	return nil
+}
+
+// ZapError 返回记录错误的 zap 字段，EcodeError 按结构化对象输出完整的错误链，其他错误同 zap.Error
+func ZapError(err error) zap.Field {
+	if ec, ok := err.(*EcodeError); ok {
+		return zap.Object("error", ec)
+	}
+	return zap.Error(err)
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/go-resty/resty/v2"
-	"github.com/vaynedu/hollow/internal/logger"
 	"go.uber.org/zap"
 )
 
@@ -83,19 +82,19 @@ func GetTraceInfo(resp *resty.Response) (*RequestTrace, error) {
 	}, nil
 }
 
-// PrintTraceInfo 打印请求跟踪信息(保持向后兼容)
-func PrintTraceInfo(resp *resty.Response) {
+// PrintTraceInfo 使用指定的日志实例打印请求跟踪信息
+func PrintTraceInfo(logger *zap.Logger, resp *resty.Response) {
 	trace, err := GetTraceInfo(resp)
 	if err != nil {
-		logger.GetLogger().Error("获取跟踪信息失败", zap.Error(err))
+		logger.Error("获取跟踪信息失败", zap.Error(err))
 		return
 	}
-	PrintStructuredTrace(trace)
+	PrintStructuredTrace(logger, trace)
 }
 
-// PrintStructuredTrace 打印结构化的请求跟踪信息
-func PrintStructuredTrace(trace *RequestTrace) {
-	logger.GetLogger().Info("请求跟踪信息",
+// PrintStructuredTrace 使用指定的日志实例打印结构化的请求跟踪信息
+func PrintStructuredTrace(logger *zap.Logger, trace *RequestTrace) {
+	logger.Info("请求跟踪信息",
 		zap.Duration("dns查询时间", trace.DNSLookup),
 		zap.Duration("连接建立时间", trace.ConnTime),
 		zap.Duration("tcp连接时间", trace.TCPConnTime),
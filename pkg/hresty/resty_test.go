@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/avast/retry-go/v4"
+	"go.uber.org/zap"
 )
 
 func TestGet(t *testing.T) {
@@ -42,7 +43,7 @@ func TestGet(t *testing.T) {
 			// 打印trace信息
 			// 比较好的一张图，表示时间的关系
 			// https://vearne.cc/archives/39953
-			PrintTraceInfo(resp)
+			PrintTraceInfo(zap.NewNop(), resp)
 			traceInfo := resp.Request.TraceInfo()
 			t.Logf("Reqeust trace info")
 			t.Logf("DNSLookup: %v", traceInfo.DNSLookup)
@@ -0,0 +1,135 @@
+package hstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SSE 以 Server-Sent Events 运行服务端流，每次 Send 写入一个 message 事件
+//
+// 流正常结束时写入 end 事件，客户端收到后应关闭 EventSource 避免自动重连；
+// handler 在发送第一条消息之前返回错误时通过 c.Error 交给 response 中间件返回普通的错误响应，
+// 之后返回的错误写入 error 事件。
+// 流的时长不受 http.Server 的 ReadTimeout 和 WriteTimeout 限制，连接的读写超时在开始前清除
+func SSE[T any](c *gin.Context, handler func(ServerStream[T]) error, opts ...Option) {
+	o := newOptions(opts)
+	clearDeadline(c.Writer)
+	s := &sseStream[T]{c: c}
+	stop := s.heartbeat(o.heartbeat)
+	err := handler(s)
+	stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil && !s.started {
+		c.Error(err)
+		return
+	}
+	if c.Request.Context().Err() != nil {
+		// 客户端已断开
+		return
+	}
+	if err != nil {
+		s.write("error", errorFrame(c, err))
+		return
+	}
+	s.write("end", dataFrame(c, nil))
+}
+
+// clearDeadline 清除连接的读写超时，WriteTimeout 到期后连接会被断开，ReadTimeout 到期后请求的 context 会被取消。
+// 不支持的 ResponseWriter（例如 httptest.ResponseRecorder）忽略
+func clearDeadline(w http.ResponseWriter) {
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+}
+
+type sseStream[T any] struct {
+	c       *gin.Context
+	mu      sync.Mutex
+	started bool
+	id      int
+}
+
+func (s *sseStream[T]) Context() context.Context {
+	return s.c.Request.Context()
+}
+
+func (s *sseStream[T]) Send(msg T) error {
+	if err := s.Context().Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write("message", dataFrame(s.c, msg))
+}
+
+// write 写入一个事件并立即 flush，第一次写入时发送响应头，调用方需持有锁
+func (s *sseStream[T]) write(event string, f frame) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	s.start()
+	s.id++
+	if _, err := fmt.Fprintf(s.c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", s.id, event, data); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+func (s *sseStream[T]) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	h := s.c.Writer.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// 禁止 nginx 缓冲
+	h.Set("X-Accel-Buffering", "no")
+	s.c.Status(http.StatusOK)
+	s.c.Writer.WriteHeaderNow()
+}
+
+// heartbeat 在流开始后定期发送注释行，返回停止心跳的函数
+func (s *sseStream[T]) heartbeat(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-s.Context().Done():
+				return
+			case <-ticker.C:
+				s.mu.Lock()
+				if s.started {
+					fmt.Fprint(s.c.Writer, ": ping\n\n")
+					s.c.Writer.Flush()
+				}
+				s.mu.Unlock()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,137 @@
+package hstream
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/hollow/pkg/hecode"
+)
+
+type event struct {
+	name string
+	data frame
+}
+
+// parseEvents 解析 SSE 响应体中的事件
+func parseEvents(t *testing.T, body string) []event {
+	t.Helper()
+	var events []event
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var e event
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				e.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e.data))
+			}
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func serveSSE(handler func(ServerStream[int]) error) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/watch", func(c *gin.Context) {
+		SSE(c, handler)
+	})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/watch", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSSE(t *testing.T) {
+	w := serveSSE(func(s ServerStream[int]) error {
+		for i := 1; i <= 2; i++ {
+			if err := s.Send(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "id: 1\nevent: message\n")
+
+	events := parseEvents(t, w.Body.String())
+	require.Len(t, events, 3)
+	assert.Equal(t, "message", events[0].name)
+	assert.Equal(t, float64(1), events[0].data.Data)
+	assert.Equal(t, "req-1", events[0].data.RequestID)
+	assert.Equal(t, float64(2), events[1].data.Data)
+	assert.Equal(t, "end", events[2].name)
+}
+
+func TestSSEError(t *testing.T) {
+	// 发送消息后出错，写入 error 事件
+	w := serveSSE(func(s ServerStream[int]) error {
+		s.Send(1)
+		return hecode.WithDetails(hecode.ErrInvalidParam, hecode.FieldViolation{Field: "id", Description: "required"})
+	})
+	events := parseEvents(t, w.Body.String())
+	require.Len(t, events, 2)
+	assert.Equal(t, "error", events[1].name)
+	assert.Equal(t, hecode.Code(hecode.ErrInvalidParam), events[1].data.Code)
+	assert.Contains(t, events[1].data.Data, "details")
+
+	// 发送消息前出错，交给 response 中间件
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	var errs []error
+	r.GET("/watch", func(c *gin.Context) {
+		SSE(c, func(s ServerStream[int]) error { return errors.New("boom") })
+		for _, e := range c.Errors {
+			errs = append(errs, e.Err)
+		}
+	})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/watch", nil))
+	assert.False(t, strings.Contains(w.Body.String(), "event:"))
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "boom")
+}
+
+func TestSSEServerTimeout(t *testing.T) {
+	// 流的时长超过 http.Server 的读写超时
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/watch", func(c *gin.Context) {
+		SSE(c, func(s ServerStream[int]) error {
+			for i := 1; i <= 5; i++ {
+				time.Sleep(100 * time.Millisecond)
+				if err := s.Send(i); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	srv := httptest.NewUnstartedServer(r)
+	srv.Config.ReadTimeout = 200 * time.Millisecond
+	srv.Config.WriteTimeout = 200 * time.Millisecond
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/watch")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	events := parseEvents(t, string(body))
+	require.Len(t, events, 6)
+	assert.Equal(t, float64(5), events[4].data.Data)
+	assert.Equal(t, "end", events[5].name)
+}
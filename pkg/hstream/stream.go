@@ -0,0 +1,136 @@
+// Package hstream 为 hollow-cli 生成的流式 RPC handler 提供 HTTP 传输
+//
+// 服务端流使用 SSE（text/event-stream），客户端流和双向流使用 WebSocket。
+// 每条消息与普通接口一样使用 {code, msg, request_id, data} 格式，消息放在 data 中，
+// 错误使用 hecode 的错误码和 data.details。流的 Context 来自请求，客户端断开后被取消，
+// 因此业务代码可以和普通接口一样使用中间件写入 context 的值并响应取消。
+package hstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ServerStream 服务端流，Send 在客户端断开后返回 context 的错误
+type ServerStream[T any] interface {
+	Context() context.Context
+	Send(msg T) error
+}
+
+// ClientStream 客户端流，客户端结束请求流后 Recv 返回 io.EOF
+type ClientStream[T any] interface {
+	Context() context.Context
+	Recv() (T, error)
+}
+
+// BidiStream 双向流
+type BidiStream[Req, Resp any] interface {
+	ClientStream[Req]
+	Send(msg Resp) error
+}
+
+// All 以迭代器的形式接收消息，请求流正常结束时迭代结束，出错时产出错误后结束
+//
+//	for msg, err := range hstream.All(stream) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func All[T any](s ClientStream[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			msg, err := s.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(msg, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Option 流选项
+type Option func(*options)
+
+type options struct {
+	heartbeat time.Duration
+	upgrader  *websocket.Upgrader
+}
+
+// WithHeartbeat 设置心跳间隔，SSE 发送注释行，WebSocket 发送 ping，用于保持经过代理的长连接，默认不发送
+func WithHeartbeat(d time.Duration) Option {
+	return func(o *options) {
+		o.heartbeat = d
+	}
+}
+
+// WithUpgrader 设置 WebSocket 的 Upgrader，例如允许跨域的 CheckOrigin
+func WithUpgrader(u *websocket.Upgrader) Option {
+	return func(o *options) {
+		o.upgrader = u
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{upgrader: &websocket.Upgrader{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// frame 流中的一条消息，格式与 response 中间件的标准响应相同
+type frame struct {
+	Code      int         `json:"code"`
+	Msg       string      `json:"msg"`
+	RequestID string      `json:"request_id"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+func dataFrame(c *gin.Context, data interface{}) frame {
+	return frame{Code: http.StatusOK, Msg: "success", RequestID: c.GetHeader("X-Request-ID"), Data: data}
+}
+
+// errorFrame 与 response 中间件处理业务错误的方式相同
+func errorFrame(c *gin.Context, err error) frame {
+	f := frame{Code: http.StatusInternalServerError, Msg: err.Error(), RequestID: c.GetHeader("X-Request-ID")}
+	var ec *hecode.EcodeError
+	if errors.As(err, &ec) {
+		f.Code, f.Msg = ec.Code(), hecode.LocalizedMessage(ec, c.GetHeader("Accept-Language"))
+	}
+	if details := hecode.DetailsData(err); len(details) > 0 {
+		f.Data = gin.H{"details": details}
+	}
+	return f
+}
+
+var unmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// decode 解码客户端发送的消息，Protobuf 消息与 hbind 一样使用 protojson，T 为指针时分配新的值
+func decode[T any](data []byte) (T, error) {
+	var msg T
+	if t := reflect.TypeFor[T](); t.Kind() == reflect.Pointer {
+		msg = reflect.New(t.Elem()).Interface().(T)
+	}
+	if pm, ok := any(msg).(proto.Message); ok {
+		return msg, unmarshalOptions.Unmarshal(data, pm)
+	}
+	if reflect.TypeFor[T]().Kind() == reflect.Pointer {
+		return msg, json.Unmarshal(data, msg)
+	}
+	return msg, json.Unmarshal(data, &msg)
+}
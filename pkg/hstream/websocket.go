@@ -0,0 +1,171 @@
+package hstream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"github.com/vaynedu/hollow/pkg/hvalidate"
+)
+
+// WebSocket 将请求升级为 WebSocket 并运行双向流，客户端流也使用这个函数
+//
+// 客户端每个文本消息是一条 JSON 编码的请求，发送空消息表示请求流结束（半关闭），之后仍可接收响应；
+// 服务端每个文本消息是一条标准格式的响应。handler 返回后以 1000 关闭连接，
+// 返回错误时先发送错误消息再以 1011 关闭。升级失败时 Upgrader 已经返回了 HTTP 错误
+func WebSocket[Req, Resp any](c *gin.Context, handler func(BidiStream[Req, Resp]) error, opts ...Option) {
+	o := newOptions(opts)
+	conn, err := o.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// 连接被劫持后服务器不再感知客户端断开，由读循环在连接出错时取消
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	s := &wsStream[Req, Resp]{c: c, ctx: ctx, conn: conn, recv: make(chan Req)}
+	go s.readLoop(cancel)
+	stop := s.heartbeat(o.heartbeat)
+	defer stop()
+
+	err = handler(s)
+	if ctx.Err() != nil {
+		return
+	}
+	code, reason := websocket.CloseNormalClosure, ""
+	if err != nil {
+		s.writeJSON(errorFrame(c, err))
+		code, reason = websocket.CloseInternalServerErr, "internal error"
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+}
+
+type wsStream[Req, Resp any] struct {
+	c       *gin.Context
+	ctx     context.Context
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	recv    chan Req
+	recvErr error // recv 关闭前设置
+}
+
+func (s *wsStream[Req, Resp]) Context() context.Context {
+	return s.ctx
+}
+
+func (s *wsStream[Req, Resp]) Recv() (Req, error) {
+	select {
+	case msg, ok := <-s.recv:
+		if !ok {
+			return msg, s.recvErr
+		}
+		return msg, nil
+	case <-s.ctx.Done():
+		// 读循环先关闭 recv 再取消，优先返回请求流结束的原因
+		select {
+		case msg, ok := <-s.recv:
+			if !ok {
+				return msg, s.recvErr
+			}
+			return msg, nil
+		default:
+			var zero Req
+			return zero, s.ctx.Err()
+		}
+	}
+}
+
+func (s *wsStream[Req, Resp]) Send(msg Resp) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	return s.writeJSON(dataFrame(s.c, msg))
+}
+
+func (s *wsStream[Req, Resp]) writeJSON(f frame) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteJSON(f)
+}
+
+// readLoop 把客户端的消息转发到 recv，请求流结束后继续读取以处理 ping 和 close 等控制消息
+func (s *wsStream[Req, Resp]) readLoop(cancel context.CancelFunc) {
+	defer cancel()
+	closed := false
+	finish := func(err error) {
+		if !closed {
+			closed = true
+			s.recvErr = err
+			close(s.recv)
+		}
+	}
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				err = io.EOF
+			}
+			finish(err)
+			return
+		}
+		if closed {
+			continue
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			finish(io.EOF)
+			continue
+		}
+		msg, err := decode[Req](data)
+		if err != nil {
+			finish(hecode.WithDetails(hecode.ErrInvalidParam, hecode.FieldViolation{Field: "message", Description: err.Error()}))
+			continue
+		}
+		// 与 hbind.Bind 一样调用生成的 Validate 方法
+		if err := hvalidate.Validate(msg); err != nil {
+			finish(err)
+			continue
+		}
+		select {
+		case s.recv <- msg:
+		case <-s.ctx.Done():
+			finish(s.ctx.Err())
+			return
+		}
+	}
+}
+
+func (s *wsStream[Req, Resp]) heartbeat(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.writeMu.Lock()
+				err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval))
+				s.writeMu.Unlock()
+				if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,85 @@
+package hstream
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func dial(t *testing.T, handler gin.HandlerFunc) *websocket.Conn {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/ws", handler)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestWebSocketBidi(t *testing.T) {
+	// 请求是 Protobuf 消息，使用 protojson 解码
+	conn := dial(t, func(c *gin.Context) {
+		WebSocket(c, func(s BidiStream[*wrapperspb.StringValue, string]) error {
+			for msg, err := range All(s) {
+				if err != nil {
+					return err
+				}
+				if err := s.Send(strings.ToUpper(msg.GetValue())); err != nil {
+					return err
+				}
+			}
+			return s.Send("done")
+		})
+	})
+
+	var f frame
+	for _, msg := range []string{"a", "b"} {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`"`+msg+`"`)))
+		require.NoError(t, conn.ReadJSON(&f))
+		assert.Equal(t, strings.ToUpper(msg), f.Data)
+	}
+	// 空消息表示请求流结束，之后仍能收到响应
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, nil))
+	require.NoError(t, conn.ReadJSON(&f))
+	assert.Equal(t, "done", f.Data)
+
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), err)
+}
+
+func TestWebSocketInvalidMessage(t *testing.T) {
+	type upload struct {
+		Size int `json:"size"`
+	}
+	conn := dial(t, func(c *gin.Context) {
+		WebSocket(c, func(s BidiStream[upload, int]) error {
+			total := 0
+			for msg, err := range All[upload](s) {
+				if err != nil {
+					return err
+				}
+				total += msg.Size
+			}
+			return s.Send(total)
+		})
+	})
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"size": 1}`)))
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"size": "x"}`)))
+	var f frame
+	require.NoError(t, conn.ReadJSON(&f))
+	assert.Equal(t, hecode.Code(hecode.ErrInvalidParam), f.Code)
+
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseInternalServerErr), err)
+}
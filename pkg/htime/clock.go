@@ -0,0 +1,27 @@
+package htime
+
+import "time"
+
+// Clock 时钟接口，业务代码通过 Clock 获取当前时间，测试时可以替换为可控的时钟
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// SystemClock 使用系统时间的时钟
+type SystemClock struct{}
+
+// NewSystemClock 创建系统时钟
+func NewSystemClock() SystemClock {
+	return SystemClock{}
+}
+
+// Now 返回当前时间
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since 返回从 t 到现在经过的时间
+func (SystemClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
@@ -0,0 +1,175 @@
+// Package hvalidate 请求校验的运行时支持
+//
+// hollow-cli proto 根据 proto 字段上的 protoc-gen-validate、buf.validate 或 hollow.rules 选项
+// 为消息生成 Validate 方法，生成的代码通过 Validator 收集所有字段的违规，
+// 最终返回带 hecode.FieldViolation 详情的 hecode.ErrInvalidParam，字段路径使用 proto 字段名，
+// 嵌套消息为 user.email，repeated 字段为 items[0].sku。
+package hvalidate
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Validatable 生成的 Validate 方法实现的接口
+type Validatable interface {
+	Validate() error
+}
+
+// Validate 校验 msg，msg 没有生成 Validate 方法（proto 中没有校验规则）时返回 nil
+func Validate(msg any) error {
+	if v, ok := msg.(Validatable); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// Validator 收集字段违规
+type Validator struct {
+	violations []hecode.Detail
+}
+
+// Add 记录 field 的违规
+func (v *Validator) Add(field, description string) {
+	v.violations = append(v.violations, hecode.FieldViolation{Field: field, Description: description})
+}
+
+// Nested 校验嵌套消息，违规的字段路径加上 field 前缀，msg 为 nil 时不校验
+func (v *Validator) Nested(field string, msg any) {
+	err := Validate(msg)
+	if err == nil {
+		return
+	}
+	details := hecode.Details(err)
+	if len(details) == 0 {
+		v.Add(field, err.Error())
+		return
+	}
+	for _, d := range details {
+		if fv, ok := d.(hecode.FieldViolation); ok {
+			v.Add(field+"."+fv.Field, fv.Description)
+		}
+	}
+}
+
+// Err 没有违规时返回 nil，否则返回带所有违规详情的 hecode.ErrInvalidParam
+func (v *Validator) Err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return hecode.WithDetails(hecode.ErrInvalidParam, v.violations...)
+}
+
+// Index repeated 字段中元素的路径，例如 items[0]
+func Index(field string, i int) string {
+	return fmt.Sprintf("%s[%d]", field, i)
+}
+
+// Has 字段是否已设置，用于 optional 和 oneof 字段只在设置时校验
+func Has(m proto.Message, name protoreflect.Name) bool {
+	msg := m.ProtoReflect()
+	fd := msg.Descriptor().Fields().ByName(name)
+	return fd != nil && msg.Has(fd)
+}
+
+// Len 字符串的字符数，与 protoc-gen-validate 一样按 Unicode 字符计算
+func Len(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// In 值是否在 values 中
+func In[T comparable](v T, values ...T) bool {
+	for _, value := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Unique repeated 字段的元素是否各不相同
+func Unique[T comparable](values []T) bool {
+	seen := make(map[T]struct{}, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			return false
+		}
+		seen[v] = struct{}{}
+	}
+	return true
+}
+
+var patterns sync.Map
+
+// Match s 是否匹配正则表达式，编译后的正则会被缓存，生成代码时已检查过表达式的合法性
+func Match(pattern, s string) bool {
+	re, ok := patterns.Load(pattern)
+	if !ok {
+		re, _ = patterns.LoadOrStore(pattern, regexp.MustCompile(pattern))
+	}
+	return re.(*regexp.Regexp).MatchString(s)
+}
+
+// IsEmail 是否为 RFC 5322 的邮箱地址，不允许带显示名称
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && addr.Name == ""
+}
+
+// IsHostname 是否为 RFC 1034 的主机名
+func IsHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsIP 是否为 IPv4 或 IPv6 地址
+func IsIP(s string) bool {
+	return net.ParseIP(s) != nil
+}
+
+// IsIPv4 是否为 IPv4 地址
+func IsIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+}
+
+// IsIPv6 是否为 IPv6 地址
+func IsIPv6(s string) bool {
+	return net.ParseIP(s) != nil && strings.Contains(s, ":")
+}
+
+// IsURI 是否为带 scheme 的绝对 URI
+func IsURI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != ""
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsUUID 是否为 8-4-4-4-12 格式的 UUID
+func IsUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
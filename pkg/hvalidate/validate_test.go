@@ -0,0 +1,80 @@
+package hvalidate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaynedu/hollow/pkg/hecode"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type item struct{ sku string }
+
+func (i *item) Validate() error {
+	var v Validator
+	if i.sku == "" {
+		v.Add("sku", "value is required")
+	}
+	return v.Err()
+}
+
+type plainError struct{}
+
+func (plainError) Validate() error { return errors.New("boom") }
+
+func TestValidator(t *testing.T) {
+	var v Validator
+	assert.NoError(t, v.Err())
+
+	v.Add("name", "value length must be at least 1 characters")
+	v.Nested(Index("items", 1), &item{})
+	v.Nested("ok", &item{sku: "a"})
+	v.Nested("plain", plainError{})
+	v.Nested("none", struct{}{})
+
+	err := v.Err()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, hecode.ErrInvalidParam)
+	var fields []string
+	for _, d := range hecode.Details(err) {
+		fields = append(fields, d.(hecode.FieldViolation).Field)
+	}
+	assert.Equal(t, []string{"name", "items[1].sku", "plain"}, fields)
+
+	assert.NoError(t, Validate(struct{}{}))
+	assert.Error(t, Validate(&item{}))
+}
+
+func TestHas(t *testing.T) {
+	assert.False(t, Has(&wrapperspb.StringValue{}, "value"))
+	assert.True(t, Has(&wrapperspb.StringValue{Value: "x"}, "value"))
+	assert.False(t, Has(&wrapperspb.StringValue{Value: "x"}, "missing"))
+}
+
+func TestFormats(t *testing.T) {
+	assert.Equal(t, 2, Len("你好"))
+	assert.True(t, In("b", "a", "b"))
+	assert.False(t, In(3, 1, 2))
+	assert.True(t, Unique([]int{1, 2}))
+	assert.False(t, Unique([]string{"a", "a"}))
+	assert.True(t, Match(`^\d+$`, "123"))
+	assert.False(t, Match(`^\d+$`, "12a"))
+
+	assert.True(t, IsEmail("a@example.com"))
+	assert.False(t, IsEmail("Ann <a@example.com>"))
+	assert.False(t, IsEmail("a.example.com"))
+	assert.True(t, IsHostname("api.example.com"))
+	assert.False(t, IsHostname("-a.example.com"))
+	assert.False(t, IsHostname("a..b"))
+	assert.True(t, IsIPv4("10.0.0.1"))
+	assert.False(t, IsIPv4("::1"))
+	assert.True(t, IsIPv6("::1"))
+	assert.True(t, IsIP("::ffff:10.0.0.1"))
+	assert.False(t, IsIPv4("::ffff:10.0.0.1"))
+	assert.True(t, IsURI("https://example.com/a?b=1"))
+	assert.False(t, IsURI("/relative"))
+	assert.True(t, IsUUID("123e4567-e89b-12d3-a456-426614174000"))
+	assert.False(t, IsUUID("123e4567e89b12d3a456426614174000"))
+}